@@ -0,0 +1,344 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+// maxTreeDepth limits the recursion into name trees.
+const maxTreeDepth = 32
+
+// AnnotationType is the /Subtype of an annotation.
+type AnnotationType string
+
+// Annotation types with dedicated fields in Annotation. Other subtypes
+// (Square, Ink, FileAttachment, Widget, ...) are reported by name with the
+// common fields only.
+const (
+	LinkAnnotation      AnnotationType = "Link"
+	TextAnnotation      AnnotationType = "Text" // sticky note comment
+	FreeTextAnnotation  AnnotationType = "FreeText"
+	HighlightAnnotation AnnotationType = "Highlight"
+	UnderlineAnnotation AnnotationType = "Underline"
+	StrikeOutAnnotation AnnotationType = "StrikeOut"
+	SquigglyAnnotation  AnnotationType = "Squiggly"
+	StampAnnotation     AnnotationType = "Stamp"
+	PopupAnnotation     AnnotationType = "Popup"
+)
+
+// An Annotation is an entry of a page's /Annots array.
+type Annotation struct {
+	Type     AnnotationType `json:"type"`
+	Rect     Rect           `json:"rect"`
+	Contents string         `json:"contents,omitempty"` // comment text, or the text of a FreeText annotation
+	Author   string         `json:"author,omitempty"`   // /T
+	Subject  string         `json:"subject,omitempty"`  // /Subj
+	ModDate  string         `json:"modDate,omitempty"`  // /M, as written in the file (D:YYYYMMDDHHmmSSOHH'mm)
+	Name     string         `json:"name,omitempty"`     // icon of a Text annotation or stamp name
+
+	// Link annotations
+	Action string       `json:"action,omitempty"` // action type (/S), such as URI, GoTo or GoToR
+	URI    string       `json:"uri,omitempty"`
+	Dest   *Destination `json:"dest,omitempty"`
+	File   string       `json:"file,omitempty"` // target file of GoToR and Launch actions
+
+	// Text markup annotations (Highlight, Underline, StrikeOut, Squiggly)
+	Quads      []Quad `json:"quads,omitempty"`
+	MarkedText string `json:"markedText,omitempty"` // page text under the quads
+
+	// Popup annotations
+	Open bool `json:"open,omitempty"`
+}
+
+// A Quad is a quadrilateral from an annotation's /QuadPoints, in the order
+// the points are stored (usually upper left, upper right, lower left,
+// lower right).
+type Quad [4]Point
+
+// A Destination is a location in a document targeted by a link.
+type Destination struct {
+	Page int    `json:"page,omitempty"` // page number starting at 1, or 0 if unknown
+	Name string `json:"name,omitempty"` // named destination, if the link used one
+}
+
+// isMarkup reports whether the annotation marks up text with /QuadPoints.
+func (t AnnotationType) isMarkup() bool {
+	switch t {
+	case HighlightAnnotation, UnderlineAnnotation, StrikeOutAnnotation, SquigglyAnnotation:
+		return true
+	}
+	return false
+}
+
+// Annotations returns the page's annotations in /Annots order. Link
+// targets are resolved to page numbers, and text markup annotations carry
+// the page text under their quad points.
+func (p Page) Annotations() (annots []Annotation, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			annots = nil
+			logger.Error(fmt.Sprint(r))
+			err = recoveredError(r)
+		}
+	}()
+	p = p.raising()
+
+	list := p.V.Key("Annots")
+	markup := false
+	for i := 0; i < list.Len(); i++ {
+		v := list.Index(i)
+		if v.Kind() != Dict {
+			continue
+		}
+		a := Annotation{
+			Type:     AnnotationType(v.Key("Subtype").Name()),
+			Rect:     rectValue(v.Key("Rect")),
+			Contents: v.Key("Contents").Text(),
+			Author:   v.Key("T").Text(),
+			Subject:  v.Key("Subj").Text(),
+			ModDate:  v.Key("M").Text(),
+			Name:     v.Key("Name").Name(),
+		}
+		switch {
+		case a.Type == LinkAnnotation:
+			p.readLink(&a, v)
+		case a.Type == PopupAnnotation:
+			a.Open = v.Key("Open").Bool()
+		case a.Type.isMarkup():
+			a.Quads = quadPoints(v.Key("QuadPoints"), a.Rect)
+			markup = true
+		}
+		annots = append(annots, a)
+	}
+	if markup && p.V.Key("Contents").Kind() != Null {
+		markedText(p.layoutGlyphs(), annots)
+	}
+	logger.Debug(fmt.Sprintf("Annotations: %d for Page %d %d R", len(annots), p.V.ptr.id, p.V.ptr.gen), true)
+	return annots, nil
+}
+
+// readLink fills in the action and destination of a Link annotation, given
+// either as /Dest or as the /A action dictionary.
+func (p Page) readLink(a *Annotation, v Value) {
+	if d := v.Key("Dest"); d.Kind() != Null {
+		a.Action = "GoTo"
+		a.Dest = p.V.r.destination(d)
+		return
+	}
+	act := v.Key("A")
+	a.Action = act.Key("S").Name()
+	switch a.Action {
+	case "URI":
+		a.URI = act.Key("URI").Text()
+	case "GoTo":
+		a.Dest = p.V.r.destination(act.Key("D"))
+	case "GoToR", "Launch":
+		a.File = fileSpecName(act.Key("F"))
+		if d := act.Key("D"); d.Kind() != Null {
+			// pages of another document are numbered from 0
+			a.Dest = &Destination{Name: d.Text()}
+			if d.Kind() == Array && d.Index(0).Kind() == Integer {
+				a.Dest = &Destination{Page: int(d.Index(0).Int64()) + 1}
+			}
+		}
+	}
+}
+
+// fileSpecName returns the file name of a file specification, which is
+// either a string or a dictionary.
+func fileSpecName(f Value) string {
+	if f.Kind() != Dict {
+		return f.Text()
+	}
+	for _, key := range []string{"UF", "F", "Unix", "DOS", "Mac"} {
+		if s := f.Key(key).Text(); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// destination resolves an explicit destination array, a named destination
+// or a dictionary holding one in /D.
+func (r *Reader) destination(d Value) *Destination {
+	var dest Destination
+	switch d.Kind() {
+	case Name:
+		dest.Name = d.Name()
+		d = r.namedDestination(dest.Name)
+	case String:
+		dest.Name = d.Text()
+		d = r.namedDestination(dest.Name)
+	}
+	if d.Kind() == Dict {
+		d = d.Key("D")
+	}
+	if page := d.Index(0); page.Kind() == Dict {
+		dest.Page = r.pageNumber(page.ptr)
+	}
+	return &dest
+}
+
+// namedDestination looks name up in the catalog's /Dests dictionary
+// (PDF 1.1) and in the /Dests name tree.
+func (r *Reader) namedDestination(name string) Value {
+	root := r.Trailer().Key("Root")
+	if d := root.Key("Dests").Key(name); d.Kind() != Null {
+		return d
+	}
+	return nameTreeLookup(root.Key("Names").Key("Dests"), name, 0)
+}
+
+// nameTreeLookup finds key in the name tree rooted at node, using the
+// /Limits of intermediate nodes to skip subtrees.
+func nameTreeLookup(node Value, key string, depth int) Value {
+	if node.Kind() != Dict || depth > maxTreeDepth {
+		return Value{}
+	}
+	names := node.Key("Names")
+	for i := 0; i+1 < names.Len(); i += 2 {
+		if names.Index(i).RawString() == key {
+			return names.Index(i + 1)
+		}
+	}
+	kids := node.Key("Kids")
+	for i := 0; i < kids.Len(); i++ {
+		kid := kids.Index(i)
+		if lim := kid.Key("Limits"); lim.Len() == 2 &&
+			(key < lim.Index(0).RawString() || key > lim.Index(1).RawString()) {
+			continue
+		}
+		if v := nameTreeLookup(kid, key, depth+1); v.Kind() != Null {
+			return v
+		}
+	}
+	return Value{}
+}
+
+// nameTreeEach calls fn with each key and value of the name tree rooted
+// at node, in tree order.
+func nameTreeEach(node Value, fn func(key string, v Value), depth int) {
+	if node.Kind() != Dict || depth > maxTreeDepth {
+		return
+	}
+	names := node.Key("Names")
+	for i := 0; i+1 < names.Len(); i += 2 {
+		fn(names.Index(i).Text(), names.Index(i+1))
+	}
+	kids := node.Key("Kids")
+	for i := 0; i < kids.Len(); i++ {
+		nameTreeEach(kids.Index(i), fn, depth+1)
+	}
+}
+
+// pageNumber returns the number of the page object ptr, starting at 1, or
+// 0 if it is not in the page tree.
+func (r *Reader) pageNumber(ptr objptr) int {
+	num := 0
+	r.walkPages(func(n int, page Value) bool {
+		if page.ptr == ptr {
+			num = n
+			return false
+		}
+		return true
+	})
+	return num
+}
+
+// walkPages calls fn with each page of the page tree and its number,
+// starting at 1, until fn returns false.
+func (r *Reader) walkPages(fn func(n int, page Value) bool) {
+	n := 0
+	seen := make(map[objptr]bool)
+	var walk func(node Value) bool
+	walk = func(node Value) bool {
+		if seen[node.ptr] {
+			return true
+		}
+		seen[node.ptr] = true
+		if node.Key("Type").Name() == "Page" {
+			n++
+			return fn(n, node)
+		}
+		kids := node.Key("Kids")
+		for i := 0; i < kids.Len(); i++ {
+			if !walk(kids.Index(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	walk(r.Trailer().Key("Root").Key("Pages"))
+}
+
+// rectValue converts a PDF rectangle array to a Rect, normalizing the
+// corners.
+func rectValue(v Value) Rect {
+	if v.Len() != 4 {
+		return Rect{}
+	}
+	x1, y1, x2, y2 := v.Index(0).Float64(), v.Index(1).Float64(), v.Index(2).Float64(), v.Index(3).Float64()
+	return Rect{Point{math.Min(x1, x2), math.Min(y1, y2)}, Point{math.Max(x1, x2), math.Max(y1, y2)}}
+}
+
+// quadPoints reads /QuadPoints, eight numbers per quadrilateral. An
+// annotation without them marks its whole rectangle.
+func quadPoints(v Value, rect Rect) []Quad {
+	var quads []Quad
+	for i := 0; i+8 <= v.Len(); i += 8 {
+		var q Quad
+		for j := range q {
+			q[j] = Point{v.Index(i + 2*j).Float64(), v.Index(i + 2*j + 1).Float64()}
+		}
+		quads = append(quads, q)
+	}
+	if len(quads) == 0 && rect != (Rect{}) {
+		quads = []Quad{{
+			{rect.Min.X, rect.Max.Y}, {rect.Max.X, rect.Max.Y},
+			{rect.Min.X, rect.Min.Y}, {rect.Max.X, rect.Min.Y},
+		}}
+	}
+	return quads
+}
+
+// bounds returns the quad's bounding box.
+func (q Quad) bounds() Rect {
+	r := Rect{q[0], q[0]}
+	for _, pt := range q[1:] {
+		r = r.union(Rect{pt, pt})
+	}
+	return r
+}
+
+// markedText sets the MarkedText of each text markup annotation to the
+// glyphs whose centers fall inside one of its quads, read line by line.
+func markedText(glyphs []layoutGlyph, annots []Annotation) {
+	for i := range annots {
+		a := &annots[i]
+		if !a.Type.isMarkup() {
+			continue
+		}
+		var marked []layoutGlyph
+		for _, g := range glyphs {
+			cx, cy := g.center()
+			for _, q := range a.Quads {
+				b := q.bounds()
+				if cx >= b.Min.X && cx <= b.Max.X && cy >= b.Min.Y && cy <= b.Max.Y {
+					marked = append(marked, g)
+					break
+				}
+			}
+		}
+		var words []string
+		for _, l := range buildLines(marked) {
+			words = append(words, l.text())
+		}
+		a.MarkedText = strings.Join(words, " ")
+	}
+}
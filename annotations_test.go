@@ -0,0 +1,83 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func annotationsTestReader(t *testing.T) *Reader {
+	t.Helper()
+	data := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /Names << /Dests 6 0 R >> >>",
+		"<< /Type /Pages /Kids [3 0 R 8 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> " +
+			"/Annots [7 0 R 9 0 R 10 0 R 11 0 R 12 0 R 13 0 R] >>",
+		streamObj("", []byte("BT /F1 10 Tf 1 0 0 1 72 700 Tm (Hello) Tj 1 0 0 1 110 700 Tm (World) Tj ET\n"+
+			"BT /F1 10 Tf 1 0 0 1 72 688 Tm (second line) Tj ET")),
+		layoutFont,
+		"<< /Kids [14 0 R] >>",
+		"<< /Type /Annot /Subtype /Link /Rect [72 600 200 612] /A << /S /URI /URI (https://example.com/terms) >> >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		"<< /Type /Annot /Subtype /Link /Rect [72 580 200 592] /Dest (chapter2) >>",
+		"<< /Type /Annot /Subtype /Text /Rect [300 700 320 720] /Name /Comment /T (Jane Reviewer) " +
+			"/M (D:20260102150405+01'00') /Contents (Please check clause 4) /Popup 13 0 R >>",
+		"<< /Type /Annot /Subtype /Highlight /Rect [108 698 140 712] /T (Jane Reviewer) /Contents (Why?) " +
+			"/QuadPoints [108 710 140 710 108 698 140 698] >>",
+		"<< /Type /Annot /Subtype /Underline /Rect [70 686 200 712] " +
+			"/QuadPoints [82 710 140 710 82 698 140 698 70 698 100 698 70 686 100 686] >>",
+		"<< /Type /Annot /Subtype /Popup /Rect [320 600 480 700] /Parent 10 0 R /Open true >>",
+		"<< /Limits [(chapter1) (chapter3)] /Names [(chapter1) [3 0 R /Fit] (chapter2) [8 0 R /XYZ 0 792 0]] >>",
+	}, "")
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	return r
+}
+
+func TestAnnotations(t *testing.T) {
+	r := annotationsTestReader(t)
+	annots, err := r.Page(1).Annotations()
+	require.NoError(t, err)
+	require.Len(t, annots, 6)
+
+	assert.Equal(t, LinkAnnotation, annots[0].Type)
+	assert.Equal(t, "URI", annots[0].Action)
+	assert.Equal(t, "https://example.com/terms", annots[0].URI)
+	assert.Equal(t, Rect{Point{72, 600}, Point{200, 612}}, annots[0].Rect)
+
+	assert.Equal(t, "GoTo", annots[1].Action)
+	assert.Equal(t, &Destination{Page: 2, Name: "chapter2"}, annots[1].Dest)
+
+	assert.Equal(t, TextAnnotation, annots[2].Type)
+	assert.Equal(t, "Jane Reviewer", annots[2].Author)
+	assert.Equal(t, "D:20260102150405+01'00'", annots[2].ModDate)
+	assert.Equal(t, "Please check clause 4", annots[2].Contents)
+	assert.Equal(t, "Comment", annots[2].Name)
+
+	assert.Equal(t, HighlightAnnotation, annots[3].Type)
+	assert.Equal(t, []Quad{{{108, 710}, {140, 710}, {108, 698}, {140, 698}}}, annots[3].Quads)
+	assert.Equal(t, "World", annots[3].MarkedText)
+
+	// two quads spanning a line break
+	assert.Equal(t, "llo World second", annots[4].MarkedText)
+
+	assert.Equal(t, PopupAnnotation, annots[5].Type)
+	assert.True(t, annots[5].Open)
+}
+
+func TestAnnotations_None(t *testing.T) {
+	r := annotationsTestReader(t)
+	annots, err := r.Page(2).Annotations()
+	require.NoError(t, err)
+	assert.Empty(t, annots)
+}
+
+func TestQuadPoints_FallBackToRect(t *testing.T) {
+	quads := quadPoints(Value{}, Rect{Point{1, 2}, Point{3, 4}})
+	assert.Equal(t, []Quad{{{1, 4}, {3, 4}, {1, 2}, {3, 2}}}, quads)
+}
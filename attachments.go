@@ -0,0 +1,122 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+// An Attachment is a file embedded in the document, either in the
+// /Names/EmbeddedFiles name tree (including the files of a portfolio) or
+// in a FileAttachment annotation.
+type Attachment struct {
+	Name         string `json:"name"`                   // file name (/UF or /F), or the name tree key
+	Description  string `json:"description,omitempty"`  // /Desc
+	Subtype      string `json:"subtype,omitempty"`      // MIME type, such as application/pdf
+	Size         int64  `json:"size"`                   // uncompressed size from /Params, or -1 if unknown
+	CreationDate string `json:"creationDate,omitempty"` // as written in the file
+	ModDate      string `json:"modDate,omitempty"`
+	CheckSum     string `json:"checkSum,omitempty"` // MD5 of the content, in hex
+	Page         int    `json:"page,omitempty"`     // page of a FileAttachment annotation, starting at 1
+
+	file Value // embedded file stream
+}
+
+// Open returns the decoded content of the attachment.
+func (a Attachment) Open() (rc io.ReadCloser, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			rc = nil
+			logger.Error(fmt.Sprint(r))
+			err = recoveredError(r)
+		}
+	}()
+	a.file = a.file.raising()
+
+	if a.file.Kind() != Stream {
+		return nil, fmt.Errorf("attachment %q has no embedded file stream", a.Name)
+	}
+	return a.file.Reader(), nil
+}
+
+// IsPDF reports whether the attachment is a PDF document, judging by its
+// MIME type or file name.
+func (a Attachment) IsPDF() bool {
+	return a.Subtype == "application/pdf" || strings.HasSuffix(strings.ToLower(a.Name), ".pdf")
+}
+
+// Attachments returns the document's embedded files: those of the
+// EmbeddedFiles name tree first, then those of FileAttachment annotations
+// not already listed, page by page.
+func (r *Reader) Attachments() (files []Attachment, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			files = nil
+			logger.Error(fmt.Sprint(rec))
+			err = recoveredError(rec)
+		}
+	}()
+	r = r.raising()
+
+	seen := make(map[objptr]bool)
+	add := func(key string, spec Value, page int) {
+		a, ok := newAttachment(key, spec)
+		if !ok || (a.file.ptr != objptr{} && seen[a.file.ptr]) {
+			return
+		}
+		seen[a.file.ptr] = true
+		a.Page = page
+		files = append(files, a)
+	}
+	nameTreeEach(r.Trailer().Key("Root").Key("Names").Key("EmbeddedFiles"), func(key string, spec Value) {
+		add(key, spec, 0)
+	}, 0)
+	r.walkPages(func(n int, page Value) bool {
+		annots := page.Key("Annots")
+		for i := 0; i < annots.Len(); i++ {
+			if a := annots.Index(i); a.Key("Subtype").Name() == "FileAttachment" {
+				add("", a.Key("FS"), n)
+			}
+		}
+		return true
+	})
+	logger.Debug(fmt.Sprintf("Attachments: %d embedded files", len(files)), true)
+	return files, nil
+}
+
+// newAttachment describes the embedded file of the file specification
+// spec; it reports false if spec does not embed a file.
+func newAttachment(key string, spec Value) (Attachment, bool) {
+	ef := spec.Key("EF")
+	file := ef.Key("UF")
+	if file.Kind() != Stream {
+		file = ef.Key("F")
+	}
+	if file.Kind() != Stream {
+		return Attachment{}, false
+	}
+	params := file.Key("Params")
+	a := Attachment{
+		Name:         fileSpecName(spec),
+		Description:  spec.Key("Desc").Text(),
+		Subtype:      file.Key("Subtype").Name(),
+		Size:         -1,
+		CreationDate: params.Key("CreationDate").Text(),
+		ModDate:      params.Key("ModDate").Text(),
+		CheckSum:     hex.EncodeToString([]byte(params.Key("CheckSum").RawString())),
+		file:         file,
+	}
+	if a.Name == "" {
+		a.Name = key
+	}
+	if size := params.Key("Size"); size.Kind() == Integer {
+		a.Size = size.Int64()
+	}
+	return a, true
+}
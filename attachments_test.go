@@ -0,0 +1,90 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// portfolioPDF builds a document showing cover on its page, with pdf
+// embedded through the EmbeddedFiles name tree and a note attached to the
+// page with a FileAttachment annotation.
+func portfolioPDF(cover string, pdf []byte) []byte {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write([]byte("remember the milk"))
+	zw.Close()
+	return buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /Names << /EmbeddedFiles << /Names [(report) 6 0 R] >> >> /Collection << >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> " +
+			"/Annots [<< /Type /Annot /Subtype /FileAttachment /Rect [0 0 10 10] /FS 8 0 R >> " +
+			"<< /Type /Annot /Subtype /FileAttachment /Rect [0 0 10 10] /FS 6 0 R >>] >>",
+		streamObj("", []byte("BT /F1 10 Tf 72 700 Td ("+cover+") Tj ET")),
+		layoutFont,
+		"<< /Type /Filespec /F (report.pdf) /UF <FEFF007200E9007000E9002E007000640066> /Desc (Quarterly report) /EF << /F 7 0 R >> >>",
+		streamObj("/Type /EmbeddedFile /Subtype /application#2Fpdf /Params << /Size "+
+			strconv.Itoa(len(pdf))+" /CreationDate (D:20260101120000Z) /ModDate (D:20260102120000Z) /CheckSum <00ff10> >>", pdf),
+		"<< /Type /Filespec /F (note.txt) /EF << /F 9 0 R >> >>",
+		streamObj("/Type /EmbeddedFile /Filter /FlateDecode", z.Bytes()),
+	}, "")
+}
+
+func TestAttachments(t *testing.T) {
+	inner := layoutTestPDF(layoutFont, "BT /F1 10 Tf 72 700 Td (Inner text) Tj ET")
+	data := portfolioPDF("Cover", inner)
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	files, err := r.Attachments()
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	a := files[0]
+	assert.Equal(t, "répé.pdf", a.Name)
+	assert.Equal(t, "Quarterly report", a.Description)
+	assert.Equal(t, "application/pdf", a.Subtype)
+	assert.Equal(t, int64(len(inner)), a.Size)
+	assert.Equal(t, "D:20260101120000Z", a.CreationDate)
+	assert.Equal(t, "D:20260102120000Z", a.ModDate)
+	assert.Equal(t, "00ff10", a.CheckSum)
+	assert.Equal(t, 0, a.Page)
+	assert.True(t, a.IsPDF())
+	rc, err := a.Open()
+	require.NoError(t, err)
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	require.NoError(t, err)
+	assert.Equal(t, inner, got)
+
+	// the annotation attaching the same file again is not repeated
+	b := files[1]
+	assert.Equal(t, "note.txt", b.Name)
+	assert.Equal(t, int64(-1), b.Size)
+	assert.Equal(t, 1, b.Page)
+	assert.False(t, b.IsPDF())
+	rc, err = b.Open()
+	require.NoError(t, err)
+	got, err = io.ReadAll(rc)
+	rc.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "remember the milk", string(got))
+}
+
+func TestAttachments_None(t *testing.T) {
+	r := annotationsTestReader(t)
+	files, err := r.Attachments()
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	_, err = Attachment{Name: "x"}.Open()
+	assert.Error(t, err)
+}
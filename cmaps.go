@@ -0,0 +1,533 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+// Type0 fonts select glyphs by CID through the CMap named or embedded in
+// their /Encoding. Without a /ToUnicode CMap, the text is recovered from the
+// CMap itself:
+//   - the Unicode CMaps (UniJIS-UCS2-H, UniGB-UTF16-V, ...) use Unicode as
+//     character codes;
+//   - the legacy CMaps (90ms-RKSJ-H, GBK-EUC-H, ETen-B5-H, KSCms-UHC-H, ...)
+//     use a national character set, decoded with its charset decoder;
+//   - Identity-H/V and embedded CMaps map codes to CIDs, which are looked up
+//     in the character collection of the font's /CIDSystemInfo.
+//
+// The predefined CMap files are not bundled. The Adobe-Japan1 collection
+// follows JIS X 0208 order, so its Roman, kana and JIS X 0208 CIDs are
+// mapped through that character set; for Adobe-GB1, Adobe-CNS1 and
+// Adobe-Korea1 only the Roman CIDs are known.
+
+// legacyCMaps maps the names of the predefined CMaps for national character
+// sets, without their -H or -V suffix, to the charset of their codes.
+var legacyCMaps = map[string]encoding.Encoding{
+	// Adobe-Japan1
+	"78-RKSJ":    japanese.ShiftJIS,
+	"78ms-RKSJ":  japanese.ShiftJIS,
+	"83pv-RKSJ":  japanese.ShiftJIS,
+	"90ms-RKSJ":  japanese.ShiftJIS,
+	"90msp-RKSJ": japanese.ShiftJIS,
+	"90pv-RKSJ":  japanese.ShiftJIS,
+	"Add-RKSJ":   japanese.ShiftJIS,
+	"Ext-RKSJ":   japanese.ShiftJIS,
+	"78-EUC":     japanese.EUCJP,
+	"EUC":        japanese.EUCJP,
+
+	// Adobe-GB1
+	"GB-EUC":   simplifiedchinese.GBK,
+	"GBpc-EUC": simplifiedchinese.GBK,
+	"GBK-EUC":  simplifiedchinese.GBK,
+	"GBKp-EUC": simplifiedchinese.GBK,
+	"GBK2K":    simplifiedchinese.GB18030,
+
+	// Adobe-CNS1
+	"B5":        traditionalchinese.Big5,
+	"B5pc":      traditionalchinese.Big5,
+	"ETen-B5":   traditionalchinese.Big5,
+	"ETenms-B5": traditionalchinese.Big5,
+	"HKscs-B5":  traditionalchinese.Big5,
+	"HKdla-B5":  traditionalchinese.Big5,
+	"HKdlb-B5":  traditionalchinese.Big5,
+	"HKgccs-B5": traditionalchinese.Big5,
+	"HKm314-B5": traditionalchinese.Big5,
+	"HKm471-B5": traditionalchinese.Big5,
+
+	// Adobe-Korea1
+	"KSC-EUC":      korean.EUCKR,
+	"KSCpc-EUC":    korean.EUCKR,
+	"KSCms-UHC":    korean.EUCKR,
+	"KSCms-UHC-HW": korean.EUCKR,
+}
+
+// iso2022CMaps maps the predefined CMaps whose codes are the 7-bit (GL)
+// form of a two-byte character set to the charset of its 8-bit form.
+var iso2022CMaps = map[string]encoding.Encoding{
+	"":    japanese.EUCJP, // H and V: JIS X 0208
+	"78":  japanese.EUCJP,
+	"Add": japanese.EUCJP,
+	"Ext": japanese.EUCJP,
+	"GB":  simplifiedchinese.GBK,
+	"KSC": korean.EUCKR,
+}
+
+// predefinedCMap returns an encoder for the predefined CMap name whose
+// codes can be decoded without the font's character collection, or nil.
+func predefinedCMap(name string) TextEncoding {
+	base := strings.TrimSuffix(strings.TrimSuffix(name, "-H"), "-V")
+	if name == "H" || name == "V" {
+		base = ""
+	} else if base == name {
+		return nil
+	}
+	if strings.HasPrefix(base, "Uni") {
+		switch {
+		case strings.Contains(base, "-UCS2"), strings.Contains(base, "-UTF16"):
+			return &unicodeCMapEncoder{width: 2}
+		case strings.Contains(base, "-UTF8"):
+			return &unicodeCMapEncoder{width: 1}
+		case strings.Contains(base, "-UTF32"):
+			return &unicodeCMapEncoder{width: 4}
+		}
+		return nil
+	}
+	if cs, ok := legacyCMaps[base]; ok {
+		return &charsetEncoder{cs: cs}
+	}
+	if cs, ok := iso2022CMaps[base]; ok {
+		return &charsetEncoder{cs: cs, gl: true}
+	}
+	return nil
+}
+
+// unicodeCMapEncoder decodes the codes of a Unicode CMap, which are UTF-8,
+// UTF-16BE or UTF-32BE depending on the code width.
+type unicodeCMapEncoder struct {
+	width int
+}
+
+func (e *unicodeCMapEncoder) Decode(raw string) string {
+	logger.Debug("decoding unicodeCMapEncoder")
+	switch e.width {
+	case 1:
+		return string(DecodeUTF8OrPreserve(raw))
+	case 2:
+		return utf16Decode(raw[:len(raw)&^1])
+	}
+	var sb strings.Builder
+	for i := 0; i+4 <= len(raw); i += 4 {
+		r := rune(raw[i])<<24 | rune(raw[i+1])<<16 | rune(raw[i+2])<<8 | rune(raw[i+3])
+		if !utf8.ValidRune(r) {
+			r = utf8.RuneError
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// codeLen returns the length of the code at the start of raw.
+func (e *unicodeCMapEncoder) codeLen(raw string) int {
+	switch {
+	case e.width == 1:
+		_, n := utf8.DecodeRuneInString(raw)
+		return n
+	case e.width == 2 && raw[0] >= 0xd8 && raw[0] <= 0xdb:
+		return 4 // surrogate pair
+	}
+	return e.width
+}
+
+// charsetEncoder decodes the codes of a legacy CMap with the decoder of its
+// character set. If gl is set, the codes are 7-bit and are moved to the
+// upper half before decoding.
+type charsetEncoder struct {
+	cs encoding.Encoding
+	gl bool
+}
+
+func (e *charsetEncoder) Decode(raw string) string {
+	logger.Debug("decoding charsetEncoder")
+	if e.gl {
+		b := []byte(raw)
+		for i := range b {
+			b[i] |= 0x80
+		}
+		raw = string(b)
+	}
+	text, err := e.cs.NewDecoder().String(raw)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("charsetEncoder: %v", err), true)
+		return string(DecodeUTF8OrPreserve(raw))
+	}
+	return text
+}
+
+// codeLen returns the length of the code at the start of raw, judging by
+// its lead byte.
+func (e *charsetEncoder) codeLen(raw string) int {
+	b := raw[0]
+	switch {
+	case e.gl:
+		return 2
+	case e.cs == japanese.ShiftJIS:
+		if b < 0x81 || (b >= 0xa0 && b <= 0xdf) || b >= 0xfd {
+			return 1
+		}
+	case e.cs == japanese.EUCJP:
+		if b == 0x8f {
+			return 3 // JIS X 0212
+		}
+		if b < 0x80 {
+			return 1
+		}
+	case e.cs == simplifiedchinese.GB18030 && len(raw) > 1 && raw[1] >= 0x30 && raw[1] <= 0x39:
+		return 4
+	case b < 0x81:
+		return 1
+	}
+	return 2
+}
+
+// cidRange maps the codes lo through hi, of equal length, to consecutive
+// CIDs starting at cid.
+type cidRange struct {
+	lo, hi string
+	cid    int
+}
+
+// cidCMap is a CMap embedded in a font's /Encoding, mapping codes to CIDs
+// with cidrange and cidchar operators. Codes it does not map fall through
+// to the Identity CMap if it names one with usecmap. A predefined CMap
+// other than Identity is represented by the length of its codes only.
+type cidCMap struct {
+	space    [4][]byteRange
+	ranges   []cidRange
+	identity bool
+	codeLen  func(raw string) int
+}
+
+// lookup returns the CID of code, or -1 if it is not mapped.
+func (m *cidCMap) lookup(code string) int {
+	for _, r := range m.ranges {
+		if len(r.lo) == len(code) && r.lo <= code && code <= r.hi {
+			return r.cid + codeInt(code) - codeInt(r.lo)
+		}
+	}
+	if m.identity && len(code) == 2 {
+		return codeInt(code)
+	}
+	return -1
+}
+
+// codeInt returns the big-endian value of a character code.
+func codeInt(code string) int {
+	n := 0
+	for i := 0; i < len(code); i++ {
+		n = n<<8 | int(code[i])
+	}
+	return n
+}
+
+// readCIDCmap reads an embedded CMap stream. It returns nil if the stream
+// is malformed; the error of a stream that cannot be parsed is passed to
+// the fail method of its Reader.
+func readCIDCmap(strm Value) *cidCMap {
+	logger.Debug("reading CID CMap")
+
+	n := -1
+	var m cidCMap
+	ok := true
+	err := Interpret(strm, func(stk *Stack, op string) {
+		if !ok {
+			return
+		}
+		switch op {
+		case "findresource":
+			stk.Pop() // category
+			stk.Pop() // key
+			stk.Push(newDict())
+		case "begincmap":
+			stk.Push(newDict())
+		case "endcmap":
+			stk.Pop()
+		case "usecmap":
+			name := stk.Pop().Name()
+			m.identity = name == "Identity-H" || name == "Identity-V"
+			if !m.identity {
+				logger.Debug(fmt.Sprintf("readCIDCmap: ignoring usecmap %s", name), true)
+			}
+		case "begincodespacerange", "begincidrange", "begincidchar", "beginnotdefrange", "beginnotdefchar":
+			n = int(stk.Pop().Int64())
+		case "endcodespacerange":
+			if n < 0 {
+				logger.Debug("missing begincodespacerange")
+				ok = false
+				return
+			}
+			for i := 0; i < n; i++ {
+				hi, lo := stk.Pop().RawString(), stk.Pop().RawString()
+				if len(lo) == 0 || len(lo) > 4 || len(lo) != len(hi) {
+					logger.Debug("bad codespace range")
+					ok = false
+					return
+				}
+				m.space[len(lo)-1] = append(m.space[len(lo)-1], byteRange{lo, hi})
+			}
+			n = -1
+		case "endcidrange":
+			if n < 0 {
+				logger.Debug("missing begincidrange")
+				ok = false
+				return
+			}
+			for i := 0; i < n; i++ {
+				cid, hi, lo := stk.Pop().Int64(), stk.Pop().RawString(), stk.Pop().RawString()
+				m.ranges = append(m.ranges, cidRange{lo, hi, int(cid)})
+			}
+			n = -1
+		case "endcidchar":
+			if n < 0 {
+				logger.Debug("missing begincidchar")
+				ok = false
+				return
+			}
+			for i := 0; i < n; i++ {
+				cid, code := stk.Pop().Int64(), stk.Pop().RawString()
+				m.ranges = append(m.ranges, cidRange{code, code, int(cid)})
+			}
+			n = -1
+		case "endnotdefrange", "endnotdefchar":
+			for i := 0; i < n*3 && stk.Len() > 0; i++ {
+				stk.Pop()
+			}
+			n = -1
+		case "defineresource":
+			stk.Pop().Name() // category
+			value := stk.Pop()
+			stk.Pop().Name() // key
+			stk.Push(value)
+		}
+	})
+	if err != nil {
+		strm.r.fail(err)
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	return &m
+}
+
+// nextCode returns the next code of raw and its length, using the
+// codespace ranges of the CMap, or two bytes for Identity-H/V.
+func (m *cidCMap) nextCode(raw string) (string, int) {
+	if m.codeLen != nil {
+		n := min(max(m.codeLen(raw), 1), len(raw))
+		return raw[:n], n
+	}
+	if m.identity && len(m.ranges) == 0 {
+		n := min(2, len(raw))
+		return raw[:n], n
+	}
+	for n := 1; n <= 4 && n <= len(raw); n++ {
+		for _, space := range m.space[n-1] {
+			if space.low <= raw[:n] && raw[:n] <= space.high {
+				return raw[:n], n
+			}
+		}
+	}
+	if m.identity && len(raw) >= 2 {
+		return raw[:2], 2
+	}
+	return raw[:1], 1
+}
+
+// cidEncoder decodes the codes of a Type0 font to CIDs through its CMap
+// and the CIDs to text through its character collection or its glyphs.
+// CIDs without known text decode to U+FFFD.
+type cidEncoder struct {
+	cmap *cidCMap
+	text func(cid int) string
+}
+
+func (e *cidEncoder) Decode(raw string) string {
+	logger.Debug("decoding cidEncoder")
+	var sb strings.Builder
+	for len(raw) > 0 {
+		code, n := e.cmap.nextCode(raw)
+		raw = raw[n:]
+		s := ""
+		if cid := e.cmap.lookup(code); cid >= 0 {
+			s = e.text(cid)
+		}
+		if s == "" {
+			s = string(utf8.RuneError)
+		}
+		sb.WriteString(s)
+	}
+	return sb.String()
+}
+
+// cidOrderings maps a character collection, Registry-Ordering from
+// /CIDSystemInfo, to its CID to Unicode mapping.
+var cidOrderings = map[string]func(cid int) rune{
+	"Adobe-Japan1": japan1ToUnicode,
+	"Adobe-GB1":    romanToUnicode,
+	"Adobe-CNS1":   romanToUnicode,
+	"Adobe-Korea1": romanToUnicode,
+}
+
+// cidOrdering returns the CID to Unicode mapping of the character
+// collection described by a /CIDSystemInfo dictionary, or nil.
+func cidOrdering(info Value) func(cid int) rune {
+	return cidOrderings[info.Key("Registry").Text()+"-"+info.Key("Ordering").Text()]
+}
+
+// romanToUnicode maps CIDs 1 through 95, the proportional Roman glyphs
+// shared by the Adobe CJK collections, to U+0020 through U+007E.
+func romanToUnicode(cid int) rune {
+	if cid >= 1 && cid <= 95 {
+		return rune(cid - 1 + 0x20)
+	}
+	return 0
+}
+
+// jisRange assigns consecutive Adobe-Japan1 CIDs from cid to the JIS X 0208
+// codes lo through hi of one row.
+type jisRange struct {
+	lo, hi uint16
+	cid    int
+}
+
+// japan1JIS lists the JIS X 0208-1983 rows of Adobe-Japan1, CIDs 633 to
+// 7477, as the H CMap maps them. Rows 1 to 7 hold symbols, Roman letters,
+// kana, Greek and Cyrillic with gaps; the kanji of rows 16 to 84 follow
+// without gaps.
+var japan1JIS = []jisRange{
+	{0x2121, 0x217e, 633},
+	{0x2221, 0x222e, 727}, {0x223a, 0x2241, 741}, {0x224a, 0x2250, 749},
+	{0x225c, 0x226a, 756}, {0x2272, 0x2279, 771}, {0x227e, 0x227e, 779},
+	{0x2330, 0x2339, 780}, {0x2341, 0x235a, 790}, {0x2361, 0x237a, 816},
+	{0x2421, 0x2473, 842},
+	{0x2521, 0x2576, 925},
+	{0x2621, 0x2638, 1011}, {0x2641, 0x2658, 1035},
+	{0x2721, 0x2741, 1059}, {0x2751, 0x2771, 1092},
+}
+
+var (
+	japan1Once  sync.Once
+	japan1Table []rune // indexed by CID
+)
+
+// japan1ToUnicode maps an Adobe-Japan1 CID to Unicode: the proportional
+// and half-width Roman glyphs, the half-width katakana and the characters
+// of JIS X 0208.
+func japan1ToUnicode(cid int) rune {
+	switch {
+	case cid >= 1 && cid <= 95:
+		return rune(cid - 1 + 0x20)
+	case cid >= 231 && cid <= 325:
+		return rune(cid - 231 + 0x20)
+	case cid >= 327 && cid <= 389:
+		return rune(cid - 327 + 0xff61)
+	}
+	japan1Once.Do(buildJapan1Table)
+	if cid >= 0 && cid < len(japan1Table) {
+		return japan1Table[cid]
+	}
+	return 0
+}
+
+// buildJapan1Table decodes the JIS X 0208 codes of Adobe-Japan1 as EUC-JP.
+func buildJapan1Table() {
+	ranges := japan1JIS
+	cid := 1125
+	for row := uint16(0x30); row <= 0x74; row++ {
+		hi := row<<8 | 0x7e
+		switch row {
+		case 0x4f:
+			hi = 0x4f53 // end of level 1 kanji
+		case 0x74:
+			hi = 0x7424
+		}
+		ranges = append(ranges, jisRange{row<<8 | 0x21, hi, cid})
+		cid += int(hi&0xff) - 0x21 + 1
+	}
+
+	table := make([]rune, cid)
+	dec := japanese.EUCJP.NewDecoder()
+	for _, r := range ranges {
+		for code := r.lo; code <= r.hi; code++ {
+			s, err := dec.String(string([]byte{byte(code>>8) | 0x80, byte(code) | 0x80}))
+			if err != nil {
+				continue
+			}
+			if c, _ := utf8.DecodeRuneInString(s); c != utf8.RuneError {
+				table[r.cid+int(code-r.lo)] = c
+			}
+		}
+	}
+	japan1Table = table
+}
+
+// cidEncoding returns the encoder of a Type0 font. A /ToUnicode CMap is
+// preferred; otherwise the text is recovered from the /Encoding CMap, the
+// character collection and, for TrueType-based fonts, the embedded font
+// program.
+func (f *Font) cidEncoding() TextEncoding {
+	if f.V.Key("ToUnicode").Kind() == Stream {
+		return f.charmapEncoding()
+	}
+	enc := f.V.Key("Encoding")
+	toUnicode := cidOrdering(f.V.Key("DescendantFonts").Index(0).Key("CIDSystemInfo"))
+	if toUnicode == nil && enc.Kind() == Stream {
+		// an embedded CMap names the character collection it maps to
+		toUnicode = cidOrdering(enc.Key("CIDSystemInfo"))
+	}
+	text := f.cidGlyphText()
+	if toUnicode != nil {
+		// the tables of some collections cover only their Latin CIDs; the
+		// font program may know the others
+		glyphText := text
+		text = func(cid int) string {
+			if r := toUnicode(cid); r != 0 {
+				return string(r)
+			}
+			if glyphText != nil {
+				return glyphText(cid)
+			}
+			return ""
+		}
+	}
+	switch enc.Kind() {
+	case Name:
+		name := enc.Name()
+		if e := predefinedCMap(name); e != nil {
+			logger.Debug(fmt.Sprintf("cidEncoding: predefined CMap %s", name), true)
+			return e
+		}
+		if (name == "Identity-H" || name == "Identity-V") && text != nil {
+			return &cidEncoder{&cidCMap{identity: true}, text}
+		}
+	case Stream:
+		if m := readCIDCmap(enc); m != nil && text != nil {
+			return &cidEncoder{m, text}
+		}
+	}
+	logger.Debug(fmt.Sprintf("cidEncoding: no CID to Unicode mapping for Font %d %d R", f.V.ptr.id, f.V.ptr.gen), true)
+	return f.charmapEncoding()
+}
@@ -0,0 +1,141 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// type0Font returns a Type0 font with the given /Encoding whose descendant
+// uses the character collection Adobe-ordering.
+func type0Font(encoding, ordering string) string {
+	return "<< /Type /Font /Subtype /Type0 /BaseFont /KozMinPr6N-Regular /Encoding " + encoding +
+		" /DescendantFonts [<< /Type /Font /Subtype /CIDFontType0 /BaseFont /KozMinPr6N-Regular" +
+		" /CIDSystemInfo << /Registry (Adobe) /Ordering (" + ordering + ") /Supplement 6 >> /DW 1000 >>] >>"
+}
+
+func TestPredefinedCMaps(t *testing.T) {
+	tests := []struct {
+		cmap string
+		raw  string
+		want string
+	}{
+		{"UniJIS-UCS2-H", "\x65\xe5\x67\x2c\x00\x41", "日本A"},
+		{"UniGB-UTF16-V", "\x4e\x2d\xd8\x40\xdc\x0b", "中𠀋"},
+		{"UniKS-UTF8-H", "한국", "한국"},
+		{"UniCNS-UTF32-H", "\x00\x00\x4e\x2d", "中"},
+		{"90ms-RKSJ-H", "\x93\xfa\x96\x7bA\xb1", "日本Aｱ"},
+		{"EUC-V", "\xc6\xfc\xcb\xdc", "日本"},
+		{"H", "\x46\x7c\x4b\x5c", "日本"},
+		{"GBK-EUC-H", "\xd6\xd0\xce\xc4", "中文"},
+		{"ETen-B5-H", "\xa4\xa4\xa4\xe5", "中文"},
+		{"KSCms-UHC-H", "\xc7\xd1\xb1\xb9", "한국"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cmap, func(t *testing.T) {
+			enc := predefinedCMap(tt.cmap)
+			require.NotNil(t, enc)
+			assert.Equal(t, tt.want, enc.Decode(tt.raw))
+		})
+	}
+
+	assert.Nil(t, predefinedCMap("Identity-H"), "Identity CMaps need the character collection")
+	assert.Nil(t, predefinedCMap("WinAnsiEncoding"))
+}
+
+func TestJapan1ToUnicode(t *testing.T) {
+	tests := map[int]rune{
+		1:    ' ',
+		34:   'A',
+		266:  'C', // half-width Roman
+		327:  '｡', // half-width katakana
+		633:  '　',
+		779:  '◯',
+		780:  '０',
+		842:  'ぁ',
+		925:  'ァ',
+		1011: 'Α',
+		1124: 'я',
+		1125: '亜',
+		4089: '腕', // last level 1 kanji
+		4090: '弌',
+		7477: '瑤', // last kanji of JIS X 0208-1983
+	}
+	for cid, want := range tests {
+		assert.Equal(t, string(want), string(japan1ToUnicode(cid)), "CID %d", cid)
+	}
+	assert.Equal(t, rune(0), japan1ToUnicode(20000))
+	assert.Equal(t, rune(0), romanToUnicode(96))
+}
+
+func TestType0FontText(t *testing.T) {
+	text := func(t *testing.T, font, content string) string {
+		t.Helper()
+		data := buildPDF([]string{
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+			streamObj("", []byte(content)),
+			font,
+			streamObj("/Type /CMap /CMapName /Test-H /CIDSystemInfo << /Registry (Adobe) /Ordering (Japan1) /Supplement 0 >>", []byte(
+				"/CIDInit /ProcSet findresource begin 12 dict begin begincmap\n"+
+					"/CMapName /Test-H def\n"+
+					"1 begincodespacerange <00> <80> <8140> <FFFC> endcodespacerange\n"+
+					"1 begincidrange <20> <7e> 1 endcidrange\n"+
+					"2 begincidchar <82a0> 843 <889f> 1125 endcidchar\n"+
+					"endcmap CMapName currentdict /CMap defineresource pop end end")),
+		}, "")
+		r := newTestReader(t, data)
+		s, err := r.Page(1).GetPlainText(nil)
+		require.NoError(t, err)
+		return strings.TrimSpace(s)
+	}
+
+	// Identity-H without /ToUnicode: CIDs of Adobe-Japan1
+	got := text(t, type0Font("/Identity-H", "Japan1"), "BT /F1 12 Tf 72 700 Td <0465034A0022> Tj ET")
+	assert.Equal(t, "亜ぁA", got)
+
+	// Identity-V decodes the same way
+	got = text(t, type0Font("/Identity-V", "Japan1"), "BT /F1 12 Tf 72 700 Td <0465> Tj ET")
+	assert.Equal(t, "亜", got)
+
+	// unknown CIDs of a known collection are replaced, not passed through
+	got = text(t, type0Font("/Identity-H", "GB1"), "BT /F1 12 Tf 72 700 Td <00224E2D> Tj ET")
+	assert.Equal(t, "A�", got)
+
+	// predefined Unicode CMap
+	got = text(t, type0Font("/UniJIS-UCS2-H", "Japan1"), "BT /F1 12 Tf 72 700 Td <65E5672C> Tj ET")
+	assert.Equal(t, "日本", got)
+
+	// embedded CMap stream mixing one- and two-byte codes
+	got = text(t, type0Font("6 0 R", "Japan1"), "BT /F1 12 Tf 72 700 Td <4182A0889F> Tj ET")
+	assert.Equal(t, "Aあ亜", got)
+
+	// the embedded CMap's own /CIDSystemInfo is used when the descendant
+	// font's names no known collection
+	got = text(t, type0Font("6 0 R", "Unknown"), "BT /F1 12 Tf 72 700 Td <4182A0889F> Tj ET")
+	assert.Equal(t, "Aあ亜", got)
+}
+
+func TestType0FontPrefersToUnicode(t *testing.T) {
+	toUnicode := []byte("/CIDInit /ProcSet findresource begin 12 dict begin begincmap\n" +
+		"1 begincodespacerange <0000> <FFFF> endcodespacerange\n" +
+		"1 beginbfchar <0465> <0058> endbfchar\n" +
+		"endcmap CMapName currentdict /CMap defineresource pop end end")
+	data := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		streamObj("", []byte("BT /F1 12 Tf 72 700 Td <0465> Tj ET")),
+		"<< /Type /Font /Subtype /Type0 /Encoding /Identity-H /ToUnicode 6 0 R /DescendantFonts [<< /CIDSystemInfo << /Registry (Adobe) /Ordering (Japan1) >> >>] >>",
+		streamObj("", toUnicode),
+	}, "")
+	s, err := newTestReader(t, data).Page(1).GetPlainText(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "X", strings.TrimSpace(s))
+}
@@ -0,0 +1,350 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+// Column segmentation heuristics, as fractions of the font size unless
+// noted otherwise.
+const (
+	// columnGap is the smallest horizontal whitespace that separates two
+	// columns, both within a line and as a gutter between regions.
+	columnGap = 1.0
+	// gutterCoverage is the fraction of the busiest x position's segments
+	// that may cross a gutter; the segments crossing it span columns
+	// (titles, figure captions, footers).
+	gutterCoverage = 0.25
+	// blockLeading is the largest baseline distance between two lines of
+	// the same block.
+	blockLeading = 1.6
+)
+
+// A TextBlock is a group of lines read together, such as a paragraph
+// within one column.
+type TextBlock struct {
+	Rect  Rect     // bounding box, in points
+	Lines []string // lines of text, top to bottom
+}
+
+// Text returns the block's lines joined by newlines.
+func (b TextBlock) Text() string {
+	return strings.Join(b.Lines, "\n")
+}
+
+// textSegment is part of a line that does not cross a column gap.
+type textSegment struct {
+	layoutLine
+	rect Rect
+}
+
+// GetTextBlocks segments the page into columns and returns its text blocks
+// in reading order: columns left to right, each top to bottom, with
+// content spanning several columns (titles, figures, footers) placed
+// between the column runs above and below it. It is a whitespace-based
+// XY-cut over the positioned glyphs of Content and handles one-, two- and
+// three-column layouts.
+func (p Page) GetTextBlocks() (blocks []TextBlock, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			blocks = nil
+			logger.Error(fmt.Sprint(r))
+			err = recoveredError(r)
+		}
+	}()
+	p = p.raising()
+
+	if p.V.IsNull() || p.V.Key("Contents").Kind() == Null {
+		return nil, nil
+	}
+	segs := orderSegments(splitSegments(buildLines(p.layoutGlyphs())))
+	blocks = groupBlocks(segs)
+	logger.Debug(fmt.Sprintf("GetTextBlocks: %d segments in %d blocks for Page %d %d R",
+		len(segs), len(blocks), p.V.ptr.id, p.V.ptr.gen), true)
+	return blocks, nil
+}
+
+// GetColumnText returns the page's text blocks in column reading order,
+// separated by blank lines.
+func (p Page) GetColumnText() (string, error) {
+	blocks, err := p.GetTextBlocks()
+	if err != nil || len(blocks) == 0 {
+		return "", err
+	}
+	var sb strings.Builder
+	for i, b := range blocks {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString(b.Text())
+	}
+	sb.WriteByte('\n')
+	return sb.String(), nil
+}
+
+// splitSegments cuts each line wherever the gap between two glyphs is wide
+// enough to be a column gutter.
+func splitSegments(lines []*layoutLine) []*textSegment {
+	var segs []*textSegment
+	for _, l := range lines {
+		var cur *textSegment
+		end := math.Inf(-1)
+		for _, g := range l.glyphs {
+			if cur != nil && g.X-end > columnGap*g.size() {
+				cur = nil
+			}
+			if cur == nil {
+				if g.S == " " {
+					continue
+				}
+				cur = &textSegment{layoutLine: layoutLine{y: l.y, size: l.size}}
+				cur.rect = Rect{Point{g.X, l.y - 0.2*l.size}, Point{g.X, l.y + 0.8*l.size}}
+				segs = append(segs, cur)
+			}
+			cur.glyphs = append(cur.glyphs, g)
+			end = g.advanceFrom(end)
+			cur.rect.Max.X = math.Max(cur.rect.Max.X, end)
+		}
+	}
+	return segs
+}
+
+// orderSegments returns segs in reading order. A region is split at
+// vertical gutters (left to right) unless a wider horizontal gap sets off
+// a title or footer; failing that, at segments spanning the columns, and
+// otherwise at its widest horizontal gap (top to bottom). Each part is
+// ordered recursively; segments on one line are read left to right.
+func orderSegments(segs []*textSegment) []*textSegment {
+	if len(segs) <= 1 {
+		return segs
+	}
+	cols, xGap := xCut(segs)
+	above, below, yGap := yCut(segs)
+	if len(cols) > 1 {
+		// A wider horizontal gap with a single column on one side of it
+		// separates a title or footer from the columns.
+		if yGap > xGap && (columnsSpanned(above, cols) == 1 || columnsSpanned(below, cols) == 1) {
+			return append(orderSegments(above), orderSegments(below)...)
+		}
+		var out []*textSegment
+		for _, c := range cols {
+			out = append(out, orderSegments(c)...)
+		}
+		return out
+	}
+	if bands := spanningBands(segs); len(bands) > 1 {
+		var out []*textSegment
+		for _, b := range bands {
+			out = append(out, orderSegments(b)...)
+		}
+		return out
+	}
+	if yGap > 0 {
+		return append(orderSegments(above), orderSegments(below)...)
+	}
+	sorted := append([]*textSegment(nil), segs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].rect.Min.X < sorted[j].rect.Min.X })
+	return sorted
+}
+
+// spanningBands cuts segs into horizontal bands, top to bottom, at each
+// segment that spans the region's columns.
+func spanningBands(segs []*textSegment) [][]*textSegment {
+	spanning := spanningSegments(segs)
+	if len(spanning) == 0 {
+		return nil
+	}
+	sorted := append([]*textSegment(nil), segs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].rect.Max.Y > sorted[j].rect.Max.Y })
+
+	var bands [][]*textSegment
+	var band []*textSegment
+	for _, s := range sorted {
+		if spanning[s] {
+			if len(band) > 0 {
+				bands = append(bands, band)
+				band = nil
+			}
+			bands = append(bands, []*textSegment{s})
+			continue
+		}
+		band = append(band, s)
+	}
+	if len(band) > 0 {
+		bands = append(bands, band)
+	}
+	return bands
+}
+
+// spanningSegments finds gutters that are obstructed only by a few
+// segments, using how many segments cover each x position of the region,
+// and returns the segments that cross one of them.
+func spanningSegments(segs []*textSegment) map[*textSegment]bool {
+	region := segmentBounds(segs)
+	step := math.Max(1, (region.Max.X-region.Min.X)/4000)
+	cover := make([]int, int((region.Max.X-region.Min.X)/step)+1)
+	bin := func(x float64) int {
+		return min(len(cover)-1, max(0, int((x-region.Min.X)/step)))
+	}
+	maxCover := 0
+	for _, s := range segs {
+		for i := bin(s.rect.Min.X); i < bin(s.rect.Max.X); i++ {
+			cover[i]++
+			maxCover = max(maxCover, cover[i])
+		}
+	}
+	limit := max(1, int(gutterCoverage*float64(maxCover)))
+	if limit >= maxCover {
+		return nil
+	}
+
+	gutter := columnGap * medianSize(segs)
+
+	spanning := make(map[*textSegment]bool)
+	for i := 1; i < len(cover); {
+		if cover[i] > limit {
+			i++
+			continue
+		}
+		j := i
+		for j < len(cover) && cover[j] <= limit {
+			j++
+		}
+		lo, hi := region.Min.X+float64(i)*step, region.Min.X+float64(j)*step
+		if j < len(cover)-1 && hi-lo >= gutter {
+			for _, s := range segs {
+				if s.rect.Min.X <= lo && s.rect.Max.X >= hi {
+					spanning[s] = true
+				}
+			}
+		}
+		i = j
+	}
+	return spanning
+}
+
+// xCut splits segs at every vertical strip of whitespace at least columnGap
+// wide that no segment crosses, returning the groups left to right and the
+// widest strip, or nil if there is no such strip.
+func xCut(segs []*textSegment) ([][]*textSegment, float64) {
+	sorted := append([]*textSegment(nil), segs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].rect.Min.X < sorted[j].rect.Min.X })
+	gutter := columnGap * medianSize(segs)
+
+	var groups [][]*textSegment
+	var widest float64
+	start, end := 0, sorted[0].rect.Max.X
+	for i := 1; i < len(sorted); i++ {
+		if gap := sorted[i].rect.Min.X - end; gap >= gutter {
+			groups = append(groups, sorted[start:i])
+			start = i
+			widest = math.Max(widest, gap)
+		}
+		end = math.Max(end, sorted[i].rect.Max.X)
+	}
+	if start == 0 {
+		return nil, 0
+	}
+	return append(groups, sorted[start:]), widest
+}
+
+// yCut splits segs at the widest horizontal strip of whitespace, returning
+// the segments above and below it and the strip's height.
+func yCut(segs []*textSegment) (above, below []*textSegment, gap float64) {
+	sorted := append([]*textSegment(nil), segs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].rect.Max.Y > sorted[j].rect.Max.Y })
+
+	split := 0
+	bottom := sorted[0].rect.Min.Y
+	for i := 1; i < len(sorted); i++ {
+		if g := bottom - sorted[i].rect.Max.Y; g > gap {
+			gap, split = g, i
+		}
+		bottom = math.Min(bottom, sorted[i].rect.Min.Y)
+	}
+	if split == 0 {
+		return segs, nil, 0
+	}
+	return sorted[:split], sorted[split:], gap
+}
+
+// columnsSpanned returns how many of the groups in cols hold segments of
+// segs.
+func columnsSpanned(segs []*textSegment, cols [][]*textSegment) int {
+	in := make(map[int]bool)
+	for _, s := range segs {
+		for i, c := range cols {
+			for _, cs := range c {
+				if cs == s {
+					in[i] = true
+				}
+			}
+		}
+	}
+	return len(in)
+}
+
+// medianSize returns the median font size of segs.
+func medianSize(segs []*textSegment) float64 {
+	sizes := make([]float64, len(segs))
+	for i, s := range segs {
+		sizes[i] = s.size
+	}
+	sort.Float64s(sizes)
+	return sizes[len(sizes)/2]
+}
+
+func segmentBounds(segs []*textSegment) Rect {
+	r := segs[0].rect
+	for _, s := range segs[1:] {
+		r = r.union(s.rect)
+	}
+	return r
+}
+
+func (r Rect) union(o Rect) Rect {
+	return Rect{
+		Point{math.Min(r.Min.X, o.Min.X), math.Min(r.Min.Y, o.Min.Y)},
+		Point{math.Max(r.Max.X, o.Max.X), math.Max(r.Max.Y, o.Max.Y)},
+	}
+}
+
+// groupBlocks merges consecutive segments in reading order into blocks.
+// A segment continues the current line if it sits on the same baseline to
+// its right, and the current block if it is the next line below and
+// overlaps it horizontally.
+func groupBlocks(segs []*textSegment) []TextBlock {
+	var blocks []TextBlock
+	var last *textSegment
+	for _, s := range segs {
+		text := s.text()
+		if last != nil {
+			b := &blocks[len(blocks)-1]
+			dy := last.y - s.y
+			size := math.Max(last.size, s.size)
+			switch {
+			case math.Abs(dy) <= baselineTolerance*size && s.rect.Min.X >= last.rect.Max.X:
+				b.Lines[len(b.Lines)-1] += " " + text
+				b.Rect = b.Rect.union(s.rect)
+				last = s
+				continue
+			case dy > 0 && dy <= blockLeading*size &&
+				s.rect.Min.X < b.Rect.Max.X && s.rect.Max.X > b.Rect.Min.X:
+				b.Lines = append(b.Lines, text)
+				b.Rect = b.Rect.union(s.rect)
+				last = s
+				continue
+			}
+		}
+		blocks = append(blocks, TextBlock{Rect: s.rect, Lines: []string{text}})
+		last = s
+	}
+	return blocks
+}
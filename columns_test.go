@@ -0,0 +1,154 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// showAt returns content showing s in /F1 at 10 points with its baseline
+// origin at (x, y).
+func showAt(x, y float64, s string) string {
+	return fmt.Sprintf("BT /F1 10 Tf 1 0 0 1 %g %g Tm (%s) Tj ET", x, y, s)
+}
+
+func textBlocks(t *testing.T, content []string) []string {
+	t.Helper()
+	data := layoutTestPDF(layoutFont, strings.Join(content, "\n"))
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	blocks, err := r.Page(1).GetTextBlocks()
+	require.NoError(t, err)
+	var out []string
+	for _, b := range blocks {
+		out = append(out, b.Text())
+	}
+	return out
+}
+
+func TestGetTextBlocks_TwoColumns(t *testing.T) {
+	// The columns are emitted row by row and their paragraph breaks line
+	// up. The footer crosses the 18pt gutter; the title only reaches into
+	// it.
+	content := []string{
+		showAt(222, 740, "Centered Title"),
+		showAt(72, 700, "left one of the first column"), showAt(230, 700, "right one, second column"),
+		showAt(72, 688, "left two of the first column"), showAt(230, 688, "right two, second column"),
+		showAt(72, 660, "left three"), showAt(230, 660, "right three"),
+		showAt(72, 648, "left four"), showAt(230, 648, "right four"),
+		showAt(150, 600, "A footer spanning both columns"),
+	}
+	assert.Equal(t, []string{
+		"Centered Title",
+		"left one of the first column\nleft two of the first column",
+		"left three\nleft four",
+		"right one, second column\nright two, second column",
+		"right three\nright four",
+		"A footer spanning both columns",
+	}, textBlocks(t, content))
+}
+
+func TestGetTextBlocks_LongerColumn(t *testing.T) {
+	// The left column runs past the end of the right one.
+	var content []string
+	for j := 0; j < 6; j++ {
+		content = append(content, showAt(72, 700-12*float64(j), fmt.Sprintf("left line %d of the column", j+1)))
+	}
+	for j := 0; j < 3; j++ {
+		content = append(content, showAt(230, 700-12*float64(j), fmt.Sprintf("right line %d of it", j+1)))
+	}
+	blocks := textBlocks(t, content)
+	require.Len(t, blocks, 2)
+	assert.True(t, strings.HasPrefix(blocks[0], "left line 1"))
+	assert.True(t, strings.HasSuffix(blocks[0], "left line 6 of the column"))
+	assert.Equal(t, "right line 1 of it\nright line 2 of it\nright line 3 of it", blocks[1])
+}
+
+func TestOrderSegments_SpanningBands(t *testing.T) {
+	seg := func(name string, x0, x1, y float64) *textSegment {
+		return &textSegment{
+			layoutLine: layoutLine{y: y, size: 10, glyphs: []layoutGlyph{{Text: Text{S: name, X: x0, Y: y, W: x1 - x0, FontSize: 10}, adv: x1 - x0}}},
+			rect:       Rect{Point{x0, y - 2}, Point{x1, y + 8}},
+		}
+	}
+	// A caption crossing the gutter sits between two coincident
+	// paragraph breaks.
+	segs := []*textSegment{
+		seg("L1", 72, 220, 700), seg("R1", 240, 400, 700),
+		seg("cap", 100, 380, 660),
+		seg("L2", 72, 220, 620), seg("R2", 240, 400, 620),
+	}
+	var got []string
+	for _, s := range orderSegments(segs) {
+		got = append(got, s.text())
+	}
+	assert.Equal(t, []string{"L1", "R1", "cap", "L2", "R2"}, got)
+}
+
+func TestGetTextBlocks_ThreeColumns(t *testing.T) {
+	var content []string
+	for i, x := range []float64{50, 230, 410} {
+		for j := 0; j < 3; j++ {
+			content = append(content, showAt(x, 700-12*float64(j), fmt.Sprintf("col%d line%d", i+1, j+1)))
+		}
+	}
+	blocks := textBlocks(t, content)
+	require.Len(t, blocks, 3)
+	for i, b := range blocks {
+		assert.Equal(t, fmt.Sprintf("col%[1]d line1\ncol%[1]d line2\ncol%[1]d line3", i+1), b)
+	}
+}
+
+func TestGetTextBlocks_SingleColumn(t *testing.T) {
+	content := []string{
+		showAt(72, 700, "The first line of a paragraph is long"),
+		showAt(72, 688, "and it wraps onto a second line that"),
+		showAt(72, 676, "ends here."),
+		showAt(72, 640, "Second paragraph."),
+	}
+	assert.Equal(t, []string{
+		"The first line of a paragraph is long\nand it wraps onto a second line that\nends here.",
+		"Second paragraph.",
+	}, textBlocks(t, content))
+}
+
+func TestGetTextBlocks_Rect(t *testing.T) {
+	data := layoutTestPDF(layoutFont, showAt(100, 500, "abcd"))
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	blocks, err := r.Page(1).GetTextBlocks()
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	assert.InDelta(t, 100, blocks[0].Rect.Min.X, 0.001)
+	assert.InDelta(t, 120, blocks[0].Rect.Max.X, 0.001)
+	assert.InDelta(t, 498, blocks[0].Rect.Min.Y, 0.001)
+	assert.InDelta(t, 508, blocks[0].Rect.Max.Y, 0.001)
+}
+
+func TestProcessor_Extract_ColumnMode(t *testing.T) {
+	content := []string{
+		showAt(72, 700, "left one"), showAt(320, 700, "right one"),
+		showAt(72, 688, "left two"), showAt(320, 688, "right two"),
+	}
+	path, cleanup := writeTempFile(t, string(layoutTestPDF(layoutFont, strings.Join(content, "\n"))))
+	defer cleanup()
+
+	cfg := NewDefaultConfig()
+	cfg.TextMode = ColumnText
+	text, _, err := NewProcessor(cfg).Extract(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, "left one\nleft two\n\nright one\nright two\n", text)
+
+	cfg.TextMode = LayoutText
+	text, _, err = NewProcessor(cfg).Extract(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, "left one right one\nleft two right two\n", text)
+}
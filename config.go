@@ -4,6 +4,7 @@
 package xtract
 
 import (
+	"context"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -17,6 +18,35 @@ const (
 	BestEffort ParsingMode = "best-effort"
 )
 
+// TextMode selects how page text is assembled.
+type TextMode string
+
+const (
+	// PlainText concatenates text in content stream order (Page.GetPlainText).
+	PlainText TextMode = "plain"
+	// LayoutText rebuilds words, lines and paragraphs in reading order
+	// from glyph positions (Page.GetLayoutText).
+	LayoutText TextMode = "layout"
+	// ColumnText detects columns and emits text blocks column by column
+	// (Page.GetColumnText).
+	ColumnText TextMode = "columns"
+)
+
+// OCRProvider recognizes the text of pages that have little or no text of
+// their own, such as scanned pages. It is given the page's images with
+// their placement.
+type OCRProvider interface {
+	RecognizePage(ctx context.Context, page OCRPage) (string, error)
+}
+
+// OCRPage is a page handed to an OCRProvider.
+type OCRPage struct {
+	Number   int     // page number, starting at 1
+	MediaBox Rect    // page boundaries, in points
+	Text     string  // text extracted from the page content, if any
+	Images   []Image // images painted on the page, in content order
+}
+
 type Config struct {
 	MaxConcurrentPDFs int           `validate:"min=1,max=10"`
 	MaxWorkersPerPDF  int           `validate:"min=1,max=10"`
@@ -24,8 +54,33 @@ type Config struct {
 	ParsingMode       ParsingMode   `validate:"oneof=strict best-effort"`
 	MaxRetries        int           `validate:"min=0,max=3"`
 	MaxTotalChars     int           `validate:"min=0"`
+	TextMode          TextMode      `validate:"omitempty,oneof=plain layout columns"`
 	DebugOn           bool
 	Logger            logger.LogFunc
+	// AppendXFA appends the XFA form data, one "key: value" line per
+	// field, to the text returned by Extract.
+	AppendXFA bool
+	// ExtractAttachments appends the text of PDF documents attached to the
+	// document, such as the files of a portfolio, to the text returned by
+	// Extract. Attachments of attachments are followed too.
+	ExtractAttachments bool
+	// OCR, if set, is called for each page with images whose text has
+	// fewer than OCRMinChars non-space characters (or none, if OCRMinChars
+	// is 0). The recognized text is appended to the page's text.
+	OCR         OCRProvider
+	OCRMinChars int `validate:"min=0"`
+	// Password opens encrypted documents whose user password is not empty.
+	// It may be either the user or the owner password.
+	Password string
+	// Limits bounds the resources each document may use. A document that
+	// exceeds them fails with a *LimitError, in both parsing modes.
+	Limits Limits
+	// SpoolThreshold is the size up to which ExtractReader and the other
+	// methods taking an io.Reader read documents from a reader that cannot
+	// seek into memory. Larger documents are written to a temporary file
+	// in SpoolDir, or in os.TempDir if SpoolDir is empty.
+	SpoolThreshold int64 `validate:"min=0"`
+	SpoolDir       string
 	// Metrics           MetricsInterface
 }
 
@@ -37,7 +92,10 @@ func NewDefaultConfig() *Config {
 		ParsingMode:       BestEffort,
 		MaxRetries:        3,
 		MaxTotalChars:     0,
+		TextMode:          PlainText,
 		DebugOn:           false,
+		Limits:            DefaultLimits,
+		SpoolThreshold:    32 << 20,
 	}
 }
 
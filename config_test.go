@@ -87,6 +87,17 @@ func TestConfig_Validate(t *testing.T) {
 			cfg:       NewDefaultConfig(),
 			shouldErr: false,
 		},
+		{
+			name: "invalid negative limit",
+			cfg: &Config{
+				MaxConcurrentPDFs: 10,
+				MaxWorkersPerPDF:  2,
+				WorkerTimeout:     5 * time.Second,
+				ParsingMode:       BestEffort,
+				Limits:            Limits{MaxStreamBytes: -1},
+			},
+			shouldErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,491 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Standard Security Handler (ISO 32000-2 §7.6.4): file key derivation,
+// password authentication and decryption of strings and streams.
+
+package xtract
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rc4"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+var (
+	// ErrPasswordRequired is returned when an encrypted document cannot be
+	// opened with the empty user password and no password was supplied.
+	ErrPasswordRequired = fmt.Errorf("%w: password required", ErrEncrypted)
+
+	// ErrBadPassword is returned when the supplied password is neither the
+	// user nor the owner password of an encrypted document.
+	ErrBadPassword = fmt.Errorf("%w: incorrect password", ErrEncrypted)
+
+	// errInvalidPassword is returned by initEncrypt when the supplied password
+	// authenticates neither as the user nor as the owner password.
+	errInvalidPassword = fmt.Errorf("%w: invalid password", ErrEncrypted)
+)
+
+// openEncrypted authenticates an encrypted document, trying the empty user
+// password before password, and reports ErrPasswordRequired or
+// ErrBadPassword when neither opens it.
+func (r *Reader) openEncrypted(password string) error {
+	err := r.initEncrypt("")
+	if err != errInvalidPassword {
+		return err
+	}
+	if password == "" {
+		logger.Error("encrypted PDF: empty user password rejected and no password supplied")
+		return ErrPasswordRequired
+	}
+	err = r.initEncrypt(password)
+	if err == errInvalidPassword {
+		logger.Error("encrypted PDF: supplied password rejected")
+		return ErrBadPassword
+	}
+	return err
+}
+
+// passwordPad is the 32-byte padding string of ISO 32000-1 §7.6.3.3, Algorithm 2.
+var passwordPad = []byte{
+	0x28, 0xBF, 0x4E, 0x5E, 0x4E, 0x75, 0x8A, 0x41, 0x64, 0x00, 0x4E, 0x56, 0xFF, 0xFA, 0x01, 0x08,
+	0x2E, 0x2E, 0x00, 0xB6, 0xD0, 0x68, 0x3E, 0x80, 0x2F, 0x0C, 0xA9, 0xFE, 0x64, 0x53, 0x69, 0x7A,
+}
+
+// stdSecurity holds the parsed /Encrypt dictionary of a Standard Security Handler.
+type stdSecurity struct {
+	v, r            int
+	length          int // file key length in bytes
+	o, u            []byte
+	oe, ue          []byte
+	p               uint32
+	id0             []byte
+	encryptMetadata bool
+	strMethod       string            // crypt filter method for strings: None, V2, AESV2 or AESV3
+	stmMethod       string            // crypt filter method for streams
+	filters         map[string]string // method of each crypt filter in /CF, for /Crypt stream filters
+}
+
+// initEncrypt reads the trailer's /Encrypt dictionary, authenticates password
+// against it and installs the resulting file key on r.
+func (r *Reader) initEncrypt(password string) error {
+	encPtr, _ := r.trailer[name("Encrypt")].(objptr)
+	encrypt := r.resolve(objptr{}, r.trailer[name("Encrypt")])
+	if encrypt.Kind() != Dict {
+		logger.Error(fmt.Sprintf("encrypted PDF: /Encrypt is not a dictionary: %v", encrypt))
+		return fmt.Errorf("%w: malformed /Encrypt dictionary", ErrEncrypted)
+	}
+	if f := encrypt.Key("Filter").Name(); f != "Standard" {
+		logger.Error(fmt.Sprintf("encrypted PDF: unsupported security handler %q", f))
+		return fmt.Errorf("%w: unsupported security handler %q", ErrEncrypted, f)
+	}
+
+	sec, err := parseStdSecurity(encrypt, r.Trailer().Key("ID").Index(0).RawString())
+	if err != nil {
+		return err
+	}
+	key, err := sec.authenticate([]byte(password))
+	if err != nil {
+		return err
+	}
+
+	r.key = key
+	r.encryptptr = encPtr
+	r.strMethod = sec.strMethod
+	r.stmMethod = sec.stmMethod
+	r.cryptFilters = sec.filters
+	r.encryptMetadata = sec.encryptMetadata
+	logger.Debug(fmt.Sprintf("encrypt: V=%d R=%d keylen=%d strings=%s streams=%s",
+		sec.v, sec.r, len(key), sec.strMethod, sec.stmMethod), true)
+	return nil
+}
+
+// parseStdSecurity validates the /Encrypt dictionary and selects the crypt
+// filter methods used for strings and streams.
+func parseStdSecurity(encrypt Value, id0 string) (*stdSecurity, error) {
+	sec := &stdSecurity{
+		v:               int(encrypt.Key("V").Int64()),
+		r:               int(encrypt.Key("R").Int64()),
+		length:          40,
+		o:               []byte(encrypt.Key("O").RawString()),
+		u:               []byte(encrypt.Key("U").RawString()),
+		oe:              []byte(encrypt.Key("OE").RawString()),
+		ue:              []byte(encrypt.Key("UE").RawString()),
+		p:               uint32(encrypt.Key("P").Int64()),
+		id0:             []byte(id0),
+		encryptMetadata: true,
+	}
+	if em := encrypt.Key("EncryptMetadata"); em.Kind() == Bool {
+		sec.encryptMetadata = em.Bool()
+	}
+	if l := encrypt.Key("Length"); l.Kind() == Integer {
+		sec.length = int(l.Int64())
+	}
+
+	switch sec.v {
+	case 1:
+		sec.length = 40
+		sec.strMethod, sec.stmMethod = "V2", "V2"
+	case 2:
+		sec.strMethod, sec.stmMethod = "V2", "V2"
+	case 4, 5:
+		cf := encrypt.Key("CF")
+		sec.strMethod = cryptFilterMethod(cf, encrypt.Key("StrF").Name())
+		sec.stmMethod = cryptFilterMethod(cf, encrypt.Key("StmF").Name())
+		sec.filters = make(map[string]string)
+		for _, name := range cf.Keys() {
+			sec.filters[name] = cryptFilterMethod(cf, name)
+		}
+		if sec.v == 4 {
+			sec.length = 128
+			if l := cf.Key(encrypt.Key("StmF").Name()).Key("Length"); l.Kind() == Integer {
+				sec.length = int(l.Int64())
+				if sec.length <= 16 { // some writers give the length in bytes
+					sec.length *= 8
+				}
+			}
+		} else {
+			sec.length = 256
+		}
+	default:
+		logger.Error(fmt.Sprintf("encrypted PDF: unsupported V=%d", sec.v))
+		return nil, fmt.Errorf("%w: unsupported V=%d", ErrEncrypted, sec.v)
+	}
+	if sec.length%8 != 0 || sec.length < 40 || sec.length > 256 {
+		logger.Error(fmt.Sprintf("encrypted PDF: invalid key length %d", sec.length))
+		return nil, fmt.Errorf("%w: invalid key length %d", ErrEncrypted, sec.length)
+	}
+	sec.length /= 8
+
+	switch sec.r {
+	case 2, 3, 4:
+		if len(sec.o) < 32 || len(sec.u) < 32 {
+			logger.Error("encrypted PDF: /O or /U shorter than 32 bytes")
+			return nil, fmt.Errorf("%w: malformed /O or /U entry", ErrEncrypted)
+		}
+	case 5, 6:
+		if len(sec.o) < 48 || len(sec.u) < 48 || len(sec.oe) < 32 || len(sec.ue) < 32 {
+			logger.Error("encrypted PDF: /O, /U, /OE or /UE too short")
+			return nil, fmt.Errorf("%w: malformed /O, /U, /OE or /UE entry", ErrEncrypted)
+		}
+	default:
+		logger.Error(fmt.Sprintf("encrypted PDF: unsupported R=%d", sec.r))
+		return nil, fmt.Errorf("%w: unsupported R=%d", ErrEncrypted, sec.r)
+	}
+	return sec, nil
+}
+
+// cryptFilterMethod returns the /CFM of the named crypt filter in cf.
+// The reserved name Identity (or a missing name) means no encryption.
+func cryptFilterMethod(cf Value, filter string) string {
+	if filter == "" || filter == "Identity" {
+		return "None"
+	}
+	switch m := cf.Key(filter).Key("CFM").Name(); m {
+	case "V2", "AESV2", "AESV3", "None":
+		return m
+	case "":
+		return "None"
+	default:
+		logger.Debug(fmt.Sprintf("encrypt: unknown crypt filter method %q, treating as V2", m))
+		return "V2"
+	}
+}
+
+// authenticate tries password first as the user and then as the owner
+// password and returns the file encryption key.
+func (sec *stdSecurity) authenticate(password []byte) ([]byte, error) {
+	if sec.r >= 5 {
+		return sec.authenticateAES256(password)
+	}
+	if key := sec.userKey(password); key != nil {
+		return key, nil
+	}
+	// Owner password: recover the padded user password from /O (Algorithm 7).
+	ownerKey := sec.ownerKey(password)
+	upw := append([]byte(nil), sec.o[:32]...)
+	if sec.r == 2 {
+		rc4XOR(ownerKey, upw)
+	} else {
+		tmp := make([]byte, len(ownerKey))
+		for i := 19; i >= 0; i-- {
+			for j := range ownerKey {
+				tmp[j] = ownerKey[j] ^ byte(i)
+			}
+			rc4XOR(tmp, upw)
+		}
+	}
+	if key := sec.userKey(upw); key != nil {
+		return key, nil
+	}
+	return nil, errInvalidPassword
+}
+
+// userKey returns the file key for password if it validates against /U
+// (Algorithms 6 and 4/5), or nil otherwise.
+func (sec *stdSecurity) userKey(password []byte) []byte {
+	key := sec.fileKey(password)
+	u := sec.computeU(key)
+	if !bytes.Equal(u, sec.u[:len(u)]) {
+		return nil
+	}
+	return key
+}
+
+// fileKey computes the file encryption key for a revision 2–4 user password (Algorithm 2).
+func (sec *stdSecurity) fileKey(password []byte) []byte {
+	h := md5.New()
+	h.Write(padPassword(password))
+	h.Write(sec.o[:32])
+	h.Write([]byte{byte(sec.p), byte(sec.p >> 8), byte(sec.p >> 16), byte(sec.p >> 24)})
+	h.Write(sec.id0)
+	if sec.r >= 4 && !sec.encryptMetadata {
+		h.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	}
+	key := h.Sum(nil)
+	n := sec.length
+	if sec.r == 2 {
+		n = 5
+	}
+	if sec.r >= 3 {
+		for i := 0; i < 50; i++ {
+			sum := md5.Sum(key[:n])
+			key = sum[:]
+		}
+	}
+	return key[:n]
+}
+
+// computeU returns the significant bytes of the /U entry for key: all 32
+// bytes for revision 2 (Algorithm 4), the first 16 otherwise (Algorithm 5).
+func (sec *stdSecurity) computeU(key []byte) []byte {
+	if sec.r == 2 {
+		u := append([]byte(nil), passwordPad...)
+		rc4XOR(key, u)
+		return u
+	}
+	h := md5.New()
+	h.Write(passwordPad)
+	h.Write(sec.id0)
+	u := h.Sum(nil)
+	tmp := make([]byte, len(key))
+	for i := 0; i < 20; i++ {
+		for j := range key {
+			tmp[j] = key[j] ^ byte(i)
+		}
+		rc4XOR(tmp, u)
+	}
+	return u
+}
+
+// ownerKey computes the RC4 key used to encrypt /O (Algorithm 3, steps a–d).
+func (sec *stdSecurity) ownerKey(password []byte) []byte {
+	sum := md5.Sum(padPassword(password))
+	key := sum[:]
+	n := sec.length
+	if sec.r == 2 {
+		n = 5
+	}
+	if sec.r >= 3 {
+		for i := 0; i < 50; i++ {
+			sum = md5.Sum(key)
+			key = sum[:]
+		}
+	}
+	return key[:n]
+}
+
+// authenticateAES256 implements Algorithms 2.A, 11 and 12 for revisions 5 and 6.
+func (sec *stdSecurity) authenticateAES256(password []byte) ([]byte, error) {
+	if len(password) > 127 {
+		password = password[:127]
+	}
+	u := sec.u[:48]
+	if bytes.Equal(sec.hashR6(password, u[32:40], nil), u[:32]) {
+		return aesDecryptNoPad(sec.hashR6(password, u[40:48], nil), sec.ue[:32])
+	}
+	o := sec.o[:48]
+	if bytes.Equal(sec.hashR6(password, o[32:40], u), o[:32]) {
+		return aesDecryptNoPad(sec.hashR6(password, o[40:48], u), sec.oe[:32])
+	}
+	return nil, errInvalidPassword
+}
+
+// hashR6 computes the revision 5 (plain SHA-256) or revision 6 (Algorithm 2.B)
+// password hash over password, salt and the optional 48-byte user key udata.
+func (sec *stdSecurity) hashR6(password, salt, udata []byte) []byte {
+	h := sha256.New()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(udata)
+	k := h.Sum(nil)
+	if sec.r == 5 {
+		return k
+	}
+
+	var k1 []byte
+	for i := 0; ; i++ {
+		k1 = k1[:0]
+		for j := 0; j < 64; j++ {
+			k1 = append(k1, password...)
+			k1 = append(k1, k...)
+			k1 = append(k1, udata...)
+		}
+		block, _ := aes.NewCipher(k[:16])
+		e := make([]byte, len(k1))
+		cipher.NewCBCEncrypter(block, k[16:32]).CryptBlocks(e, k1)
+
+		sum := 0
+		for _, c := range e[:16] {
+			sum += int(c)
+		}
+		var next hash.Hash
+		switch sum % 3 {
+		case 0:
+			next = sha256.New()
+		case 1:
+			next = sha512.New384()
+		case 2:
+			next = sha512.New()
+		}
+		next.Write(e)
+		k = next.Sum(nil)
+
+		if i >= 63 && int(e[len(e)-1]) <= i-31 {
+			break
+		}
+	}
+	return k[:32]
+}
+
+// padPassword pads or truncates password to 32 bytes using passwordPad.
+func padPassword(password []byte) []byte {
+	out := make([]byte, 32)
+	n := copy(out, password)
+	copy(out[n:], passwordPad)
+	return out
+}
+
+// rc4XOR encrypts or decrypts buf in place with RC4 under key.
+func rc4XOR(key, buf []byte) {
+	c, err := rc4.NewCipher(key)
+	if err != nil {
+		return
+	}
+	c.XORKeyStream(buf, buf)
+}
+
+// aesDecryptNoPad decrypts data with AES-256-CBC, a zero IV and no padding,
+// as used for /UE and /OE.
+func aesDecryptNoPad(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(out, data)
+	return out, nil
+}
+
+// objectKey derives the per-object key of Algorithm 1 from the file key
+// for the crypt filter method. AESV3 uses the 32-byte file key directly.
+func objectKey(key []byte, method string, ptr objptr) []byte {
+	if method == "AESV3" {
+		return key
+	}
+	h := md5.New()
+	h.Write(key)
+	h.Write([]byte{byte(ptr.id), byte(ptr.id >> 8), byte(ptr.id >> 16), byte(ptr.gen), byte(ptr.gen >> 8)})
+	if method == "AESV2" {
+		h.Write([]byte("sAlT"))
+	}
+	k := h.Sum(nil)
+	n := len(key) + 5
+	if n > 16 {
+		n = 16
+	}
+	return k[:n]
+}
+
+// isAESMethod reports whether the crypt filter method is AES-based.
+func isAESMethod(method string) bool {
+	return method == "AESV2" || method == "AESV3"
+}
+
+// decryptString decrypts a string belonging to the indirect object ptr
+// with the crypt filter method. Strings that cannot be decrypted are
+// returned unchanged.
+func decryptString(key []byte, method string, ptr objptr, s string) string {
+	if method == "None" {
+		return s
+	}
+	k := objectKey(key, method, ptr)
+	if !isAESMethod(method) {
+		b := []byte(s)
+		rc4XOR(k, b)
+		return string(b)
+	}
+	b, err := aesDecryptCBC(k, []byte(s))
+	if err != nil {
+		logger.Debug(fmt.Sprintf("decrypt: string in object %d %d: %v", ptr.id, ptr.gen, err))
+		return s
+	}
+	return string(b)
+}
+
+// decryptStream returns a reader of the data of the stream in the
+// indirect object ptr decrypted with the crypt filter method.
+func decryptStream(key []byte, method string, ptr objptr, rd io.Reader) io.Reader {
+	if method == "None" {
+		return rd
+	}
+	k := objectKey(key, method, ptr)
+	if !isAESMethod(method) {
+		c, err := rc4.NewCipher(k)
+		if err != nil {
+			return &errorReadCloser{err}
+		}
+		return &cipher.StreamReader{S: c, R: rd}
+	}
+	data, err := io.ReadAll(rd)
+	if err != nil {
+		return &errorReadCloser{err}
+	}
+	out, err := aesDecryptCBC(k, data)
+	if err != nil {
+		logger.Error(fmt.Sprintf("decrypt: stream in object %d %d: %v", ptr.id, ptr.gen, err))
+		return &errorReadCloser{err}
+	}
+	return bytes.NewReader(out)
+}
+
+// aesDecryptCBC decrypts data whose first 16 bytes are the IV and removes
+// the PKCS#5 padding. Malformed padding is tolerated and left in place.
+func aesDecryptCBC(key, data []byte) ([]byte, error) {
+	if len(data) < aes.BlockSize {
+		return nil, fmt.Errorf("encrypted data shorter than AES IV")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	iv, data := data[:aes.BlockSize], data[aes.BlockSize:]
+	data = data[:len(data)-len(data)%aes.BlockSize]
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, data)
+	if n := len(out); n > 0 {
+		if pad := int(out[n-1]); pad >= 1 && pad <= aes.BlockSize && pad <= n {
+			out = out[:n-pad]
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,374 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildPDF assembles a PDF from object bodies (object i+1 is objs[i]),
+// writing a classic xref table and a trailer with the given extra entries.
+func buildPDF(objs []string, trailerExtra string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.7\n")
+	offsets := make([]int, len(objs))
+	for i, obj := range objs {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xrefStart := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n%s 65535 f \n", len(objs)+1, pad10(0))
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%s 00000 n \n", pad10(off))
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R %s >>\nstartxref\n%d\n%%%%EOF\n", len(objs)+1, trailerExtra, xrefStart)
+	return b.Bytes()
+}
+
+// streamObj formats a stream object body with the given extra dictionary entries.
+func streamObj(extra string, data []byte) string {
+	return "<< /Length " + strconv.Itoa(len(data)) + " " + extra + " >>\nstream\n" + string(data) + "\nendstream"
+}
+
+const encTestContent = "BT /F1 12 Tf 72 700 Td (Secret text) Tj ET"
+
+// testEncrypter builds encrypted documents using the same algorithms the
+// reader implements, so tests can cover every revision without fixtures.
+type testEncrypter struct {
+	sec       *stdSecurity
+	key       []byte
+	strMethod string // /StrF method if it differs from /StmF; None leaves strings in the clear
+}
+
+func newTestEncrypter(t *testing.T, v, r, bits int, method, userPW, ownerPW string) *testEncrypter {
+	t.Helper()
+	sec := &stdSecurity{v: v, r: r, length: bits / 8, p: 0xfffff0c4, id0: []byte("0123456789abcdef"), encryptMetadata: true}
+	e := &testEncrypter{sec: sec}
+	if r >= 5 {
+		e.key = bytes.Repeat([]byte{0x5a}, 32)
+		vs, ks := []byte("uvsaltuv"), []byte("ukeysalt")
+		sec.u = append(append(sec.hashR6([]byte(userPW), vs, nil), vs...), ks...)
+		sec.ue = aesEncryptNoPad(t, sec.hashR6([]byte(userPW), ks, nil), e.key)
+		ovs, oks := []byte("ovsaltov"), []byte("okeysalt")
+		sec.o = append(append(sec.hashR6([]byte(ownerPW), ovs, sec.u), ovs...), oks...)
+		sec.oe = aesEncryptNoPad(t, sec.hashR6([]byte(ownerPW), oks, sec.u), e.key)
+		return e
+	}
+	// Algorithm 3: /O from the owner and user passwords.
+	ok := sec.ownerKey([]byte(ownerPW))
+	o := padPassword([]byte(userPW))
+	rc4XOR(ok, o)
+	if r >= 3 {
+		tmp := make([]byte, len(ok))
+		for i := 1; i < 20; i++ {
+			for j := range ok {
+				tmp[j] = ok[j] ^ byte(i)
+			}
+			rc4XOR(tmp, o)
+		}
+	}
+	sec.o = o
+	e.key = sec.fileKey([]byte(userPW))
+	sec.u = append(sec.computeU(e.key), make([]byte, 32)...)[:32]
+	return e
+}
+
+func aesEncryptNoPad(t *testing.T, key, data []byte) []byte {
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	out := make([]byte, len(data))
+	cipher.NewCBCEncrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(out, data)
+	return out
+}
+
+func (e *testEncrypter) encrypt(method string, ptr objptr, data []byte) []byte {
+	if method == "None" {
+		return data
+	}
+	k := objectKey(e.key, method, ptr)
+	if !isAESMethod(method) {
+		out := append([]byte(nil), data...)
+		rc4XOR(k, out)
+		return out
+	}
+	pad := aes.BlockSize - len(data)%aes.BlockSize
+	plain := append(append([]byte(nil), data...), bytes.Repeat([]byte{byte(pad)}, pad)...)
+	iv := []byte("0123456789ABCDEF")
+	block, _ := aes.NewCipher(k)
+	out := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(out, plain)
+	return append(append([]byte(nil), iv...), out...)
+}
+
+func (e *testEncrypter) encryptDict(method string) string {
+	h := func(b []byte) string { return "<" + hex.EncodeToString(b) + ">" }
+	s := e.sec
+	d := fmt.Sprintf("<< /Filter /Standard /V %d /R %d /Length %d /P %d /O %s /U %s",
+		s.v, s.r, s.length*8, int32(s.p), h(s.o), h(s.u))
+	if s.r >= 5 {
+		d += " /OE " + h(s.oe) + " /UE " + h(s.ue)
+	}
+	if s.v >= 4 {
+		cf, stmF, strF := "", "/StdCF", "/StdCF"
+		switch e.strMethod {
+		case "", method:
+		case "None":
+			strF = "/Identity"
+		default:
+			cf = fmt.Sprintf(" /StrCF << /CFM /%s /Length %d >>", e.strMethod, s.length)
+			strF = "/StrCF"
+		}
+		if method == "None" {
+			stmF = "/Identity"
+		} else {
+			cf += fmt.Sprintf(" /StdCF << /CFM /%s /AuthEvent /DocOpen /Length %d >>", method, s.length)
+		}
+		d += fmt.Sprintf(" /CF <<%s >> /StmF %s /StrF %s", cf, stmF, strF)
+	}
+	return d + " >>"
+}
+
+// pdf returns a one-page document whose content stream and /Info title are encrypted.
+func (e *testEncrypter) pdf(method string) []byte {
+	strMethod := method
+	if e.strMethod != "" {
+		strMethod = e.strMethod
+	}
+	content := e.encrypt(method, objptr{4, 0}, []byte(encTestContent))
+	title := e.encrypt(strMethod, objptr{6, 0}, []byte("Confidential"))
+	objs := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		streamObj("", content),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Title <" + hex.EncodeToString(title) + "> >>",
+		e.encryptDict(method),
+	}
+	id := "<" + hex.EncodeToString(e.sec.id0) + ">"
+	return buildPDF(objs, "/Info 6 0 R /Encrypt 7 0 R /ID ["+id+id+"]")
+}
+
+func TestDecrypt_EmptyUserPassword(t *testing.T) {
+	cases := []struct {
+		name      string
+		v, r, len int
+		method    string
+	}{
+		{"RC4-40", 1, 2, 40, "V2"},
+		{"RC4-128", 2, 3, 128, "V2"},
+		{"AESV2", 4, 4, 128, "AESV2"},
+		{"AESV3-R6", 5, 6, 256, "AESV3"},
+		{"AESV3-R5", 5, 5, 256, "AESV3"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newTestEncrypter(t, tc.v, tc.r, tc.len, tc.method, "", "owner-secret")
+			pdf := e.pdf(tc.method)
+
+			r, err := NewReader(bytes.NewReader(pdf), int64(len(pdf)))
+			require.NoError(t, err)
+			assert.Equal(t, e.key, r.key)
+
+			text, err := r.Page(1).GetPlainText(nil)
+			require.NoError(t, err)
+			assert.Contains(t, text, "Secret text")
+			assert.Equal(t, "Confidential", r.Trailer().Key("Info").Key("Title").Text())
+
+			// The /Encrypt dictionary itself must not be decrypted.
+			assert.Equal(t, string(e.sec.o), r.Trailer().Key("Encrypt").Key("O").RawString())
+		})
+	}
+}
+
+func TestDecrypt_NonEmptyUserPassword(t *testing.T) {
+	for _, rev := range []int{3, 6} {
+		v, bits, method := 2, 128, "V2"
+		if rev == 6 {
+			v, bits, method = 5, 256, "AESV3"
+		}
+		e := newTestEncrypter(t, v, rev, bits, method, "user-secret", "owner-secret")
+		pdf := e.pdf(method)
+
+		_, err := NewReader(bytes.NewReader(pdf), int64(len(pdf)))
+		assert.ErrorIs(t, err, ErrPasswordRequired, "R%d", rev)
+
+		for _, pw := range []string{"user-secret", "owner-secret"} {
+			key, err := e.sec.authenticate([]byte(pw))
+			require.NoError(t, err, "R%d password %q", rev, pw)
+			assert.Equal(t, e.key, key, "R%d password %q", rev, pw)
+		}
+		_, err = e.sec.authenticate([]byte("wrong"))
+		assert.ErrorIs(t, err, errInvalidPassword)
+	}
+}
+
+func TestNewReaderWithOptions_Password(t *testing.T) {
+	cases := []struct {
+		v, r, len int
+		method    string
+	}{
+		{1, 2, 40, "V2"},
+		{4, 4, 128, "AESV2"},
+		{5, 6, 256, "AESV3"},
+	}
+	for _, tc := range cases {
+		e := newTestEncrypter(t, tc.v, tc.r, tc.len, tc.method, "user-secret", "owner-secret")
+		pdf := e.pdf(tc.method)
+
+		for _, pw := range []string{"user-secret", "owner-secret"} {
+			r, err := NewReaderWithOptions(bytes.NewReader(pdf), int64(len(pdf)), ReaderOptions{Password: pw})
+			require.NoError(t, err, "R%d password %q", tc.r, pw)
+			text, err := r.Page(1).GetPlainText(nil)
+			require.NoError(t, err)
+			assert.Contains(t, text, "Secret text", "R%d password %q", tc.r, pw)
+		}
+
+		_, err := NewReaderWithOptions(bytes.NewReader(pdf), int64(len(pdf)), ReaderOptions{Password: "wrong"})
+		assert.ErrorIs(t, err, ErrBadPassword, "R%d", tc.r)
+	}
+}
+
+func TestOpenWithPassword(t *testing.T) {
+	e := newTestEncrypter(t, 2, 3, 128, "V2", "user-secret", "owner-secret")
+	path, cleanup := writeTempFile(t, string(e.pdf("V2")))
+	defer cleanup()
+
+	_, _, err := Open(path)
+	assert.ErrorIs(t, err, ErrPasswordRequired)
+
+	_, _, err = OpenWithPassword(path, "nope")
+	assert.ErrorIs(t, err, ErrBadPassword)
+
+	f, r, err := OpenWithPassword(path, "user-secret")
+	require.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, "Confidential", r.Trailer().Key("Info").Key("Title").Text())
+
+	// An unencrypted document ignores the password.
+	f2, _, err := OpenWithPassword(td("pdf_test.pdf"), "anything")
+	require.NoError(t, err)
+	f2.Close()
+}
+
+func TestDecrypt_IdentityStringFilter(t *testing.T) {
+	e := newTestEncrypter(t, 4, 4, 128, "AESV2", "", "owner")
+	e.strMethod = "None"
+	pdf := e.pdf("AESV2")
+
+	r, err := NewReader(bytes.NewReader(pdf), int64(len(pdf)))
+	require.NoError(t, err)
+	assert.Equal(t, "None", r.strMethod)
+	assert.Equal(t, "Confidential", r.Trailer().Key("Info").Key("Title").Text())
+	text, err := r.Page(1).GetPlainText(nil)
+	require.NoError(t, err)
+	assert.Contains(t, text, "Secret text")
+}
+
+func TestDecrypt_MixedFilters(t *testing.T) {
+	cases := []struct {
+		name       string
+		v, r, len  int
+		stmF, strF string
+	}{
+		{"AESV2 streams, RC4 strings", 4, 4, 128, "AESV2", "V2"},
+		{"RC4 streams, AESV2 strings", 4, 4, 128, "V2", "AESV2"},
+		{"AESV3 streams, Identity strings", 5, 6, 256, "AESV3", "None"},
+		{"Identity streams, AESV3 strings", 5, 6, 256, "None", "AESV3"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newTestEncrypter(t, tc.v, tc.r, tc.len, tc.stmF, "", "owner")
+			e.strMethod = tc.strF
+			pdf := e.pdf(tc.stmF)
+
+			r, err := NewReader(bytes.NewReader(pdf), int64(len(pdf)))
+			require.NoError(t, err)
+			assert.Equal(t, "Confidential", r.Trailer().Key("Info").Key("Title").Text())
+			text, err := r.Page(1).GetPlainText(nil)
+			require.NoError(t, err)
+			assert.Contains(t, text, "Secret text")
+		})
+	}
+}
+
+func TestDecrypt_NamedCryptFilter(t *testing.T) {
+	// The stream's /Crypt filter selects the RC4 filter used for strings
+	// instead of the AES filter named by /StmF.
+	e := newTestEncrypter(t, 4, 4, 128, "AESV2", "", "owner")
+	e.strMethod = "V2"
+	data := e.encrypt("V2", objptr{4, 0}, []byte("BT (named) Tj ET"))
+	objs := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		streamObj("/Filter /Crypt /DecodeParms << /Name /StrCF >>", data),
+		"null",
+		"null",
+		e.encryptDict("AESV2"),
+	}
+	id := "<" + hex.EncodeToString(e.sec.id0) + ">"
+	pdf := buildPDF(objs, "/Encrypt 7 0 R /ID ["+id+id+"]")
+
+	r, err := NewReader(bytes.NewReader(pdf), int64(len(pdf)))
+	require.NoError(t, err)
+	out, err := io.ReadAll(r.Page(1).V.Key("Contents").Reader())
+	require.NoError(t, err)
+	assert.Equal(t, "BT (named) Tj ET", string(out))
+}
+
+func TestDecrypt_IdentityCryptFilter(t *testing.T) {
+	e := newTestEncrypter(t, 4, 4, 128, "AESV2", "", "owner")
+	objs := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		streamObj("/Filter [/Crypt] /DecodeParms [<< /Name /Identity >>]", []byte("BT (clear) Tj ET")),
+		"null",
+		"null",
+		e.encryptDict("AESV2"),
+	}
+	id := "<" + hex.EncodeToString(e.sec.id0) + ">"
+	pdf := buildPDF(objs, "/Encrypt 7 0 R /ID ["+id+id+"]")
+
+	r, err := NewReader(bytes.NewReader(pdf), int64(len(pdf)))
+	require.NoError(t, err)
+	out, err := io.ReadAll(r.Page(1).V.Key("Contents").Reader())
+	require.NoError(t, err)
+	assert.Equal(t, "BT (clear) Tj ET", string(out))
+}
+
+func TestObjectKey(t *testing.T) {
+	key := []byte{1, 2, 3, 4, 5}
+	assert.Len(t, objectKey(key, "V2", objptr{1, 0}), 10)
+	assert.Len(t, objectKey(bytes.Repeat([]byte{1}, 16), "AESV2", objptr{1, 0}), 16)
+	assert.NotEqual(t, objectKey(key, "V2", objptr{1, 0}), objectKey(key, "V2", objptr{2, 0}))
+
+	k16 := bytes.Repeat([]byte{1}, 16)
+	assert.NotEqual(t, objectKey(k16, "V2", objptr{1, 0}), objectKey(k16, "AESV2", objptr{1, 0}))
+
+	k32 := bytes.Repeat([]byte{7}, 32)
+	assert.Equal(t, k32, objectKey(k32, "AESV3", objptr{9, 0}), "AES-256 uses the file key directly")
+}
+
+func TestAESDecryptCBC(t *testing.T) {
+	e := &testEncrypter{key: bytes.Repeat([]byte{3}, 16)}
+	enc := e.encrypt("AESV2", objptr{1, 0}, []byte("hello, world"))
+	out, err := aesDecryptCBC(objectKey(e.key, "AESV2", objptr{1, 0}), enc)
+	require.NoError(t, err)
+	assert.Equal(t, "hello, world", string(out))
+
+	_, err = aesDecryptCBC(e.key, []byte("short"))
+	assert.Error(t, err)
+}
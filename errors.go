@@ -0,0 +1,129 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+// Errors reported for malformed or unsupported documents. They are
+// wrapped with details of the problem; test for them with errors.Is.
+var (
+	// ErrNotPDF is returned when the data does not start with a PDF header.
+	ErrNotPDF = errors.New("not a PDF file")
+
+	// ErrMissingEOF is returned when the file does not end with the %%EOF
+	// marker, as a truncated download does.
+	ErrMissingEOF = errors.New("malformed PDF file: missing %%EOF")
+
+	// ErrBadXref is returned when the startxref pointer, the cross-reference
+	// table or stream, or the trailer cannot be read.
+	ErrBadXref = errors.New("malformed PDF file: bad cross-reference data")
+
+	// ErrUnsupportedFilter is returned when a stream is encoded with a
+	// filter, predictor or filter parameters this package cannot decode.
+	ErrUnsupportedFilter = errors.New("unsupported filter")
+
+	// ErrEncrypted is wrapped by the errors for encrypted documents that
+	// cannot be opened, such as ErrPasswordRequired and ErrBadPassword.
+	ErrEncrypted = errors.New("encrypted PDF")
+
+	// ErrLimitExceeded is wrapped by the LimitError returned when a
+	// document exceeds one of its Limits.
+	ErrLimitExceeded = errors.New("resource limit exceeded")
+)
+
+// An ObjectError reports a malformed object: ID and Gen identify the
+// object being read, if any, and Offset is the position in the file where
+// the problem was found, or -1 if unknown.
+type ObjectError struct {
+	ID     uint32
+	Gen    uint16
+	Offset int64
+	Err    error
+}
+
+func (e *ObjectError) Error() string {
+	var at string
+	if e.Offset >= 0 {
+		at = fmt.Sprintf(" at offset %d", e.Offset)
+	}
+	if e.ID == 0 && e.Gen == 0 {
+		return fmt.Sprintf("malformed PDF%s: %v", at, e.Err)
+	}
+	return fmt.Sprintf("malformed PDF: object %d %d%s: %v", e.ID, e.Gen, at, e.Err)
+}
+
+func (e *ObjectError) Unwrap() error {
+	return e.Err
+}
+
+// objectError returns an ObjectError for ptr, found at offset.
+func objectError(ptr objptr, offset int64, format string, args ...interface{}) *ObjectError {
+	return &ObjectError{ID: ptr.id, Gen: ptr.gen, Offset: offset, Err: fmt.Errorf(format, args...)}
+}
+
+// recoveredError converts a value recovered from a panic into an error.
+// Errors, such as the ObjectErrors raised while parsing, are returned as
+// they are so that callers can inspect them with errors.Is and errors.As.
+func recoveredError(r interface{}) error {
+	if err, ok := r.(error); ok {
+		return err
+	}
+	return errors.New(fmt.Sprint(r))
+}
+
+// isAbort reports whether err stopped the reading of data that is not
+// itself malformed: a document exceeding its Limits, or a done context.
+func isAbort(err error) bool {
+	return errors.Is(err, ErrLimitExceeded) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// raising returns a copy of r whose Values panic with an *ObjectError or a
+// *LimitError when an object they refer to cannot be read, instead of
+// reading it as null. The exported methods that return an error read the
+// document through it and recover the panic as their error.
+func (r *Reader) raising() *Reader {
+	if r == nil || r.raise {
+		return r
+	}
+	c := *r
+	c.raise = true
+	return &c
+}
+
+// raising returns v read through r.raising().
+func (v Value) raising() Value {
+	v.r = v.r.raising()
+	return v
+}
+
+// raising returns p read through r.raising().
+func (p Page) raising() Page {
+	p.V = p.V.raising()
+	return p
+}
+
+// catch, deferred, recovers from a malformed object or an exceeded limit
+// and logs it, unless r raises errors.
+func (r *Reader) catch() {
+	if r != nil && r.raise {
+		return
+	}
+	if e := recover(); e != nil {
+		logger.Error(fmt.Sprint(e))
+	}
+}
+
+// fail panics with err if r raises errors, and logs it otherwise.
+func (r *Reader) fail(err error) {
+	if r != nil && r.raise {
+		panic(err)
+	}
+	logger.Error(err.Error())
+}
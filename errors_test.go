@@ -0,0 +1,173 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errorTestPDF returns a one-page document whose page shows content.
+func errorTestPDF(content string) []byte {
+	return buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		streamObj("", []byte(content)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}, "")
+}
+
+func TestErrors_Sentinels(t *testing.T) {
+	open := func(data []byte) error {
+		_, err := NewReader(bytes.NewReader(data), int64(len(data)))
+		return err
+	}
+	data := errorTestPDF("BT /F1 12 Tf (hello) Tj ET")
+	require.NoError(t, open(data))
+
+	assert.ErrorIs(t, open([]byte("hello, world")), ErrNotPDF)
+	assert.ErrorIs(t, open(nil), ErrNotPDF)
+	assert.ErrorIs(t, open(data[:bytes.Index(data, []byte("xref"))]), ErrMissingEOF)
+	assert.ErrorIs(t, open(bytes.Replace(data, []byte("startxref\n"), []byte("startxref\n1"), 1)), ErrBadXref)
+	assert.ErrorIs(t, open([]byte("%PDF-1.7\nstartxref\n%%EOF")), ErrBadXref)
+
+	assert.ErrorIs(t, ErrPasswordRequired, ErrEncrypted)
+	assert.ErrorIs(t, ErrBadPassword, ErrEncrypted)
+}
+
+func TestErrors_UnsupportedFilter(t *testing.T) {
+	data := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R >>",
+		streamObj("/Filter /JBIG2Decode", []byte("BT (hello) Tj ET")),
+	}, "")
+	r := newTestReader(t, data)
+
+	_, err := io.ReadAll(r.Page(1).V.Key("Contents").Reader())
+	assert.ErrorIs(t, err, ErrUnsupportedFilter)
+
+	_, err = r.Page(1).GetPlainText(nil)
+	assert.ErrorIs(t, err, ErrUnsupportedFilter)
+}
+
+func TestErrors_ObjectError(t *testing.T) {
+	// the xref entry of the content stream points at another object
+	data := errorTestPDF("BT /F1 12 Tf (hello) Tj ET")
+	data = bytes.Replace(data, []byte("4 0 obj"), []byte("9 0 obj"), 1)
+	r := newTestReader(t, data)
+
+	_, err := r.Page(1).GetPlainText(nil)
+	var oe *ObjectError
+	require.True(t, errors.As(err, &oe), "got %v", err)
+	assert.Equal(t, uint32(4), oe.ID)
+	assert.Equal(t, int64(bytes.Index(data, []byte("9 0 obj"))), oe.Offset)
+
+	// a content stream that cannot be tokenized
+	r = newTestReader(t, errorTestPDF("BT /F1 12 Tf <zz> Tj ET"))
+	_, err = r.Page(1).GetPlainText(nil)
+	require.True(t, errors.As(err, &oe), "got %v", err)
+	assert.GreaterOrEqual(t, oe.Offset, int64(0))
+}
+
+func TestErrors_MalformedOperators(t *testing.T) {
+	// operators with the wrong number of operands are skipped
+	r := newTestReader(t, errorTestPDF("Q BT /F1 Tf 1 2 Td Tj () TJ /F1 12 Tf 72 700 Td (hello) Tj ET"))
+	text, err := r.Page(1).GetPlainText(nil)
+	require.NoError(t, err)
+	assert.Contains(t, text, "hello")
+
+	var shown strings.Builder
+	for _, txt := range r.Page(1).Content().Text {
+		shown.WriteString(txt.S)
+	}
+	assert.Contains(t, shown.String(), "hello")
+}
+
+func TestErrors_Accessors(t *testing.T) {
+	// the outline entry is not where the xref table says, and the
+	// ToUnicode CMap ends a bfchar block it never began
+	data := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /Outlines 6 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		streamObj("", []byte("BT /F1 12 Tf 72 700 Td (hello) Tj ET")),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /ToUnicode 8 0 R >>",
+		"<< /Type /Outlines /First 7 0 R /Count 1 >>",
+		"<< /Title (one) /Parent 6 0 R >>",
+		streamObj("", []byte("/CIDInit /ProcSet findresource begin begincmap <01> <0041> endbfchar endcmap")),
+	}, "")
+	data = bytes.Replace(data, []byte("7 0 obj"), []byte("9 0 obj"), 1)
+	r := newTestReader(t, data)
+
+	// the exported accessors read what cannot be loaded as null
+	require.NotPanics(t, func() {
+		assert.True(t, r.Trailer().Key("Root").Key("Outlines").Key("First").IsNull())
+		assert.Empty(t, r.Outline().Child)
+		assert.NotNil(t, r.Page(1).Font("F1").Encoder())
+		r.Page(1).Font("F1").Width('A')
+	})
+
+	// the methods that return an error report it
+	_, err := r.Page(1).GetPlainText(nil)
+	var oe *ObjectError
+	require.True(t, errors.As(err, &oe), "got %v", err)
+	assert.Equal(t, uint32(8), oe.ID)
+	assert.Contains(t, err.Error(), "missing beginbfchar")
+}
+
+func TestErrors_Interpret(t *testing.T) {
+	nop := func(stk *Stack, op string) {}
+
+	r := newTestReader(t, errorTestPDF("BT /F1 12 Tf <zz> Tj ET"))
+	err := Interpret(r.Page(1).V.Key("Contents"), nop)
+	var oe *ObjectError
+	assert.True(t, errors.As(err, &oe), "got %v", err)
+	err = Interpret(r.Page(1).V.Key("Contents"), func(stk *Stack, op string) { panic("stop") })
+	assert.EqualError(t, err, "stop")
+
+	data := errorTestPDF("BT /F1 12 Tf 72 700 Td (hello) Tj ET")
+	r, err = openLimited(t, data, Limits{MaxStreamBytes: 8})
+	require.NoError(t, err)
+	assertLimit(t, Interpret(r.Page(1).V.Key("Contents"), nop), "MaxStreamBytes")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, InterpretContext(ctx, r.Page(1).V.Key("Contents"), nop), context.Canceled)
+}
+
+func TestErrors_NoPanic(t *testing.T) {
+	data := errorTestPDF("BT /F1 12 Tf 72 700 Td (hello) Tj ET")
+	hostile := func(data []byte) {
+		for _, opts := range []ReaderOptions{{}, {Recover: true}} {
+			r, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), opts)
+			if err != nil {
+				continue
+			}
+			for i := 1; i <= r.NumPage(); i++ {
+				p := r.Page(i)
+				p.GetPlainText(nil)
+				p.GetLayoutText()
+				p.GetColumnText()
+				p.Content()
+			}
+			r.Metadata()
+		}
+	}
+	for i := 0; i < len(data); i++ {
+		// truncated and corrupted at every offset
+		assert.NotPanics(t, func() { hostile(data[:i]) }, "truncated at %d", i)
+		corrupt := bytes.Clone(data)
+		corrupt[i] = '('
+		assert.NotPanics(t, func() { hostile(corrupt) }, "corrupted at %d", i)
+	}
+}
@@ -0,0 +1,452 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Decoders for the standard PDF stream filters that are not provided by the
+// Go standard library: ASCIIHexDecode, LZWDecode, RunLengthDecode and the
+// PNG and TIFF predictors used with FlateDecode and LZWDecode. The ASCII85
+// input cleaning is in ascii85.go. applyFilter in read.go selects between
+// them, and contextReader stops them when the context of the read is done.
+
+package xtract
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+// asciiHexReader implements the ASCIIHexDecode filter.
+// Whitespace is ignored, '>' marks the end of data, and a final odd
+// digit is treated as if followed by 0.
+type asciiHexReader struct {
+	r   *bufio.Reader
+	eod bool
+}
+
+func newASCIIHexReader(r io.Reader) *asciiHexReader {
+	return &asciiHexReader{r: bufio.NewReader(r)}
+}
+
+func (a *asciiHexReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) && !a.eod {
+		hi, ok, err := a.nextDigit()
+		if err != nil {
+			return n, err
+		}
+		if !ok {
+			break
+		}
+		lo, ok, err := a.nextDigit()
+		if err != nil {
+			return n, err
+		}
+		if !ok {
+			lo = 0
+		}
+		p[n] = byte(hi<<4 | lo)
+		n++
+	}
+	if n == 0 && a.eod {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// nextDigit returns the next hex digit, skipping whitespace.
+// It reports ok == false at the end of data.
+func (a *asciiHexReader) nextDigit() (int, bool, error) {
+	for {
+		c, err := a.r.ReadByte()
+		if err == io.EOF {
+			a.eod = true
+			return 0, false, nil
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		if c == '>' {
+			a.eod = true
+			return 0, false, nil
+		}
+		if isSpace(c) {
+			continue
+		}
+		x := unhex(c)
+		if x < 0 {
+			return 0, false, fmt.Errorf("ASCIIHexDecode: invalid character %q", c)
+		}
+		return x, true, nil
+	}
+}
+
+// runLengthReader implements the RunLengthDecode filter.
+type runLengthReader struct {
+	r    *bufio.Reader
+	pend []byte
+	buf  [128]byte
+	eod  bool
+}
+
+func newRunLengthReader(r io.Reader) *runLengthReader {
+	return &runLengthReader{r: bufio.NewReader(r)}
+}
+
+func (rl *runLengthReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(rl.pend) > 0 {
+			m := copy(p[n:], rl.pend)
+			n += m
+			rl.pend = rl.pend[m:]
+			continue
+		}
+		if rl.eod {
+			break
+		}
+		length, err := rl.r.ReadByte()
+		if err == io.EOF || length == 128 {
+			rl.eod = true
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+		if length < 128 {
+			// copy the next length+1 bytes literally
+			k, err := io.ReadFull(rl.r, rl.buf[:int(length)+1])
+			rl.pend = rl.buf[:k]
+			if err != nil {
+				rl.eod = true
+			}
+			continue
+		}
+		// repeat the next byte 257-length times
+		c, err := rl.r.ReadByte()
+		if err != nil {
+			rl.eod = true
+			continue
+		}
+		run := rl.buf[:257-int(length)]
+		for i := range run {
+			run[i] = c
+		}
+		rl.pend = run
+	}
+	if n == 0 && rl.eod {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+const (
+	lzwClear    = 256
+	lzwEOD      = 257
+	lzwFirst    = 258
+	lzwMaxWidth = 12
+)
+
+// lzwReader implements the LZWDecode filter: variable-width codes of 9 to
+// 12 bits, most significant bit first. With earlyChange set (the PDF
+// default, /EarlyChange 1), the code width grows one code early.
+type lzwReader struct {
+	r     io.ByteReader
+	early int
+	bits  uint32
+	nbits uint
+	width uint
+	table [][]byte // entries for codes lzwFirst and above
+	prev  []byte
+	pend  []byte
+	err   error
+}
+
+func newLZWReader(r io.Reader, earlyChange bool) *lzwReader {
+	lz := &lzwReader{r: bufio.NewReader(r), width: 9}
+	if earlyChange {
+		lz.early = 1
+	}
+	return lz
+}
+
+func (lz *lzwReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(lz.pend) > 0 {
+			m := copy(p[n:], lz.pend)
+			n += m
+			lz.pend = lz.pend[m:]
+			continue
+		}
+		if lz.err != nil {
+			break
+		}
+		lz.step()
+	}
+	if n == 0 && lz.err != nil {
+		return 0, lz.err
+	}
+	return n, nil
+}
+
+// step decodes one code into lz.pend, or sets lz.err.
+func (lz *lzwReader) step() {
+	code, ok := lz.readCode()
+	if !ok || code == lzwEOD {
+		lz.err = io.EOF
+		return
+	}
+	if code == lzwClear {
+		lz.table = lz.table[:0]
+		lz.width = 9
+		lz.prev = nil
+		return
+	}
+
+	next := lzwFirst + len(lz.table)
+	var entry []byte
+	switch {
+	case code < 256:
+		entry = []byte{byte(code)}
+	case code < next:
+		entry = lz.table[code-lzwFirst]
+	case code == next && lz.prev != nil:
+		entry = append(append([]byte(nil), lz.prev...), lz.prev[0])
+	default:
+		lz.err = fmt.Errorf("LZWDecode: invalid code %d", code)
+		return
+	}
+
+	if lz.prev != nil && next < 1<<lzwMaxWidth {
+		lz.table = append(lz.table, append(append([]byte(nil), lz.prev...), entry[0]))
+		next++
+		if next+lz.early >= 1<<lz.width && lz.width < lzwMaxWidth {
+			lz.width++
+		}
+	}
+	lz.prev = entry
+	lz.pend = entry
+}
+
+// readCode reads the next code of the current width.
+func (lz *lzwReader) readCode() (int, bool) {
+	for lz.nbits < lz.width {
+		c, err := lz.r.ReadByte()
+		if err != nil {
+			return 0, false
+		}
+		lz.bits = lz.bits<<8 | uint32(c)
+		lz.nbits += 8
+	}
+	lz.nbits -= lz.width
+	code := int(lz.bits>>lz.nbits) & (1<<lz.width - 1)
+	lz.bits &= 1<<lz.nbits - 1
+	return code, true
+}
+
+// pngPredictorReader undoes the PNG predictors (/Predictor 10-15). Each row
+// starts with a filter type byte, so the predictor number only says that PNG
+// prediction is in use; the actual algorithm can change from row to row.
+type pngPredictorReader struct {
+	r    io.Reader
+	bpp  int    // bytes per complete pixel, at least 1
+	prev []byte // previous decoded row, including the filter type byte
+	cur  []byte
+	pend []byte
+	done bool
+}
+
+func newPNGPredictorReader(r io.Reader, colors, bpc, columns int) *pngPredictorReader {
+	rowLen := (colors*bpc*columns + 7) / 8
+	return &pngPredictorReader{
+		r:    r,
+		bpp:  max(1, (colors*bpc+7)/8),
+		prev: make([]byte, 1+rowLen),
+		cur:  make([]byte, 1+rowLen),
+	}
+}
+
+func (pr *pngPredictorReader) Read(b []byte) (int, error) {
+	n := 0
+	for len(b) > 0 {
+		if len(pr.pend) > 0 {
+			m := copy(b, pr.pend)
+			n += m
+			b = b[m:]
+			pr.pend = pr.pend[m:]
+			continue
+		}
+		if pr.done {
+			return n, io.EOF
+		}
+		k, err := io.ReadFull(pr.r, pr.cur)
+		if err == io.ErrUnexpectedEOF && k > 1 {
+			// short final row: decode what is there
+			pr.done = true
+		} else if err != nil {
+			return n, err
+		}
+		if err := pr.unfilter(pr.cur[1:k]); err != nil {
+			return n, err
+		}
+		pr.prev, pr.cur = pr.cur, pr.prev
+		pr.pend = pr.prev[1:k]
+	}
+	return n, nil
+}
+
+// unfilter reverses the PNG filter named by pr.cur[0] on row in place.
+func (pr *pngPredictorReader) unfilter(row []byte) error {
+	prior := pr.prev[1:]
+	bpp := pr.bpp
+	switch pr.cur[0] {
+	default:
+		logger.Error(fmt.Sprintf("malformed PNG predictor row: filter type %d", pr.cur[0]))
+		return fmt.Errorf("malformed PNG predictor row: filter type %d", pr.cur[0])
+	case 0: // None
+	case 1: // Sub
+		for i := bpp; i < len(row); i++ {
+			row[i] += row[i-bpp]
+		}
+	case 2: // Up
+		for i := range row {
+			row[i] += prior[i]
+		}
+	case 3: // Average
+		for i := range row {
+			left := 0
+			if i >= bpp {
+				left = int(row[i-bpp])
+			}
+			row[i] += byte((left + int(prior[i])) / 2)
+		}
+	case 4: // Paeth
+		for i := range row {
+			var left, upLeft byte
+			if i >= bpp {
+				left, upLeft = row[i-bpp], prior[i-bpp]
+			}
+			row[i] += paeth(left, prior[i], upLeft)
+		}
+	}
+	return nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// tiffPredictorReader undoes TIFF predictor 2 (horizontal differencing).
+// Each sample is stored as the difference from the same color component
+// of the pixel to its left, for any of 1, 2, 4, 8 or 16 bits per component.
+type tiffPredictorReader struct {
+	r      io.Reader
+	colors int
+	bpc    int
+	row    []byte
+	pend   []byte
+	done   bool
+}
+
+func newTIFFPredictorReader(r io.Reader, colors, bpc, columns int) *tiffPredictorReader {
+	return &tiffPredictorReader{
+		r:      r,
+		colors: colors,
+		bpc:    bpc,
+		row:    make([]byte, (colors*bpc*columns+7)/8),
+	}
+}
+
+func (tr *tiffPredictorReader) Read(b []byte) (int, error) {
+	n := 0
+	for len(b) > 0 {
+		if len(tr.pend) > 0 {
+			m := copy(b, tr.pend)
+			n += m
+			b = b[m:]
+			tr.pend = tr.pend[m:]
+			continue
+		}
+		if tr.done {
+			return n, io.EOF
+		}
+		k, err := io.ReadFull(tr.r, tr.row)
+		if err == io.ErrUnexpectedEOF {
+			tr.done = true
+		} else if err != nil {
+			return n, err
+		}
+		tr.undiff(tr.row[:k])
+		tr.pend = tr.row[:k]
+	}
+	return n, nil
+}
+
+func (tr *tiffPredictorReader) undiff(row []byte) {
+	switch tr.bpc {
+	case 8:
+		for i := tr.colors; i < len(row); i++ {
+			row[i] += row[i-tr.colors]
+		}
+	case 16:
+		step := 2 * tr.colors
+		for i := step; i+1 < len(row); i += 2 {
+			v := uint16(row[i])<<8 | uint16(row[i+1])
+			v += uint16(row[i-step])<<8 | uint16(row[i-step+1])
+			row[i], row[i+1] = byte(v>>8), byte(v)
+		}
+	case 1, 2, 4:
+		mask := 1<<tr.bpc - 1
+		samples := len(row) * 8 / tr.bpc
+		get := func(j int) int {
+			bit := j * tr.bpc
+			return int(row[bit/8]>>(8-tr.bpc-bit%8)) & mask
+		}
+		for j := tr.colors; j < samples; j++ {
+			v := (get(j) + get(j-tr.colors)) & mask
+			bit := j * tr.bpc
+			shift := 8 - tr.bpc - bit%8
+			row[bit/8] = row[bit/8]&^byte(mask<<shift) | byte(v<<shift)
+		}
+	}
+}
+
+// contextReader fails reads with ctx.Err() once ctx is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// newContextReader returns r if ctx can never be done, and otherwise r
+// wrapped in a contextReader.
+func newContextReader(ctx context.Context, r io.Reader) io.Reader {
+	if ctx.Done() == nil {
+		return r
+	}
+	return &contextReader{ctx, r}
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
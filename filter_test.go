@@ -0,0 +1,239 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"bytes"
+	"compress/lzw"
+	"compress/zlib"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestASCIIHexReader(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"simple", "68656c6c6f>", "hello"},
+		{"whitespace and case", "68 65\n6C\t6c 6F>", "hello"},
+		{"odd digit count", "616>", "a`"},
+		{"no terminator", "6869", "hi"},
+		{"data after terminator", "6869>7a7a", "hi"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := io.ReadAll(newASCIIHexReader(bytes.NewReader([]byte(tc.in))))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(out))
+		})
+	}
+
+	_, err := io.ReadAll(newASCIIHexReader(bytes.NewReader([]byte("6g>"))))
+	assert.Error(t, err, "invalid hex digit should be an error")
+}
+
+func TestRunLengthReader(t *testing.T) {
+	// literal run of 3, repeated run of 4 'z', EOD, trailing garbage
+	in := []byte{2, 'a', 'b', 'c', 253, 'z', 128, 'q'}
+	out, err := io.ReadAll(newRunLengthReader(bytes.NewReader(in)))
+	require.NoError(t, err)
+	assert.Equal(t, "abczzzz", string(out))
+
+	// truncated literal run: keep what is there
+	out, err = io.ReadAll(newRunLengthReader(bytes.NewReader([]byte{5, 'a'})))
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(out))
+}
+
+func TestLZWReader_EarlyChange(t *testing.T) {
+	// Example from ISO 32000-1, 7.4.4.2.
+	in := []byte{0x80, 0x0B, 0x60, 0x50, 0x22, 0x0C, 0x0C, 0x85, 0x01}
+	out, err := io.ReadAll(newLZWReader(bytes.NewReader(in), true))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{45, 45, 45, 45, 45, 65, 45, 45, 45, 66}, out)
+}
+
+func TestLZWReader_NoEarlyChange(t *testing.T) {
+	// compress/lzw uses the same code-width switch as EarlyChange 0.
+	want := bytes.Repeat([]byte("the quick brown fox jumps over the lazy dog. "), 200)
+	var buf bytes.Buffer
+	zw := lzw.NewWriter(&buf, lzw.MSB, 8)
+	_, err := zw.Write(want)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	out, err := io.ReadAll(newLZWReader(bytes.NewReader(buf.Bytes()), false))
+	require.NoError(t, err)
+	assert.Equal(t, want, out)
+}
+
+func TestApplyFilter_Extended(t *testing.T) {
+	hex, err := applyFilter(bytes.NewReader([]byte("6869>")), "ASCIIHexDecode", Value{})
+	require.NoError(t, err)
+	out, err := io.ReadAll(hex)
+	require.NoError(t, err)
+	assert.Equal(t, "hi", string(out))
+
+	rl, err := applyFilter(bytes.NewReader([]byte{1, 'h', 'i', 128}), "RunLengthDecode", Value{})
+	require.NoError(t, err)
+	out, err = io.ReadAll(rl)
+	require.NoError(t, err)
+	assert.Equal(t, "hi", string(out))
+
+	lz, err := applyFilter(bytes.NewReader([]byte{0x80, 0x0B, 0x60, 0x50, 0x22, 0x0C, 0x0C, 0x85, 0x01}), "LZWDecode", Value{})
+	require.NoError(t, err)
+	out, err = io.ReadAll(lz)
+	require.NoError(t, err)
+	assert.Len(t, out, 10)
+
+	id, err := applyFilter(bytes.NewReader([]byte("raw")), "Crypt", Value{})
+	require.NoError(t, err)
+	out, err = io.ReadAll(id)
+	require.NoError(t, err)
+	assert.Equal(t, "raw", string(out))
+}
+
+func TestStreamFilters_Document(t *testing.T) {
+	var lzbuf bytes.Buffer
+	zw := lzw.NewWriter(&lzbuf, lzw.MSB, 8)
+	zw.Write([]byte("BT (LZW) Tj ET"))
+	zw.Close()
+
+	data := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents [4 0 R 5 0 R] >>",
+		streamObj("/Filter [/ASCIIHexDecode /RunLengthDecode]", []byte("0a42542028486578 2920546a fe20 80>")),
+		streamObj("/Filter /LZWDecode /DecodeParms << /EarlyChange 0 >>", lzbuf.Bytes()),
+	}, "")
+
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	contents := r.Page(1).V.Key("Contents")
+
+	out, err := io.ReadAll(contents.Index(0).Reader())
+	require.NoError(t, err)
+	assert.Equal(t, "BT (Hex) Tj   ", string(out))
+
+	out, err = io.ReadAll(contents.Index(1).Reader())
+	require.NoError(t, err)
+	assert.Equal(t, "BT (LZW) Tj ET", string(out))
+}
+
+// pngFilterRow applies PNG filter type ft to row, the inverse of what
+// pngPredictorReader undoes.
+func pngFilterRow(ft byte, row, prior []byte, bpp int) []byte {
+	out := []byte{ft}
+	for i, x := range row {
+		var left, up, upLeft byte
+		if i >= bpp {
+			left, upLeft = row[i-bpp], prior[i-bpp]
+		}
+		up = prior[i]
+		switch ft {
+		case 1:
+			x -= left
+		case 2:
+			x -= up
+		case 3:
+			x -= byte((int(left) + int(up)) / 2)
+		case 4:
+			x -= paeth(left, up, upLeft)
+		}
+		out = append(out, x)
+	}
+	return out
+}
+
+func TestPNGPredictorReader(t *testing.T) {
+	// 3 colors x 8 bits x 4 columns = 12-byte rows, one row per filter type
+	rows := [][]byte{
+		{10, 20, 30, 11, 21, 31, 12, 22, 32, 200, 100, 50},
+		{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+		{255, 0, 255, 0, 255, 0, 128, 128, 128, 7, 7, 7},
+		{9, 8, 7, 6, 5, 4, 3, 2, 1, 0, 255, 254},
+		{30, 60, 90, 120, 150, 180, 210, 240, 14, 44, 74, 104},
+	}
+	var enc, want []byte
+	prior := make([]byte, 12)
+	for ft, row := range rows {
+		enc = append(enc, pngFilterRow(byte(ft), row, prior, 3)...)
+		want = append(want, row...)
+		prior = row
+	}
+
+	out, err := io.ReadAll(newPNGPredictorReader(bytes.NewReader(enc), 3, 8, 4))
+	require.NoError(t, err)
+	assert.Equal(t, want, out)
+
+	// Up row against the zero first prior row, then a short final row.
+	out, err = io.ReadAll(newPNGPredictorReader(bytes.NewReader([]byte{2, 1, 2, 2, 1}), 1, 8, 2))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 2}, out)
+
+	// unknown filter type
+	_, err = io.ReadAll(newPNGPredictorReader(bytes.NewReader([]byte{9, 1, 1}), 1, 8, 2))
+	assert.Error(t, err)
+
+	// empty input
+	out, err = io.ReadAll(newPNGPredictorReader(bytes.NewReader(nil), 1, 8, 2))
+	require.NoError(t, err)
+	assert.Empty(t, out)
+}
+
+func TestTIFFPredictorReader(t *testing.T) {
+	cases := []struct {
+		name                string
+		colors, bpc, column int
+		in, want            []byte
+	}{
+		{"8-bit RGB", 3, 8, 2, []byte{10, 20, 30, 1, 2, 3, 5, 5, 5, 250, 0, 1}, []byte{10, 20, 30, 11, 22, 33, 5, 5, 5, 255, 5, 6}},
+		{"16-bit gray", 1, 16, 2, []byte{0x01, 0xFF, 0x00, 0x02}, []byte{0x01, 0xFF, 0x02, 0x01}},
+		{"4-bit gray", 1, 4, 4, []byte{0x31, 0x1F}, []byte{0x34, 0x54}},
+		{"1-bit gray", 1, 1, 8, []byte{0b10000001}, []byte{0b11111110}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := io.ReadAll(newTIFFPredictorReader(bytes.NewReader(tc.in), tc.colors, tc.bpc, tc.column))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, out)
+		})
+	}
+}
+
+func TestApplyFilter_Predictors(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write([]byte{1, 5, 1, 1, 2, 1, 1, 1})
+	zw.Close()
+
+	param := Value{data: dict{
+		name("Predictor"): int64(11),
+		name("Columns"):   int64(3),
+	}}
+	rd, err := applyFilter(bytes.NewReader(buf.Bytes()), "FlateDecode", param)
+	require.NoError(t, err)
+	out, err := io.ReadAll(rd)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{5, 6, 7, 6, 7, 8}, out)
+
+	param = Value{data: dict{
+		name("Predictor"): int64(2),
+		name("Columns"):   int64(4),
+	}}
+	buf.Reset()
+	zw = zlib.NewWriter(&buf)
+	zw.Write([]byte{1, 1, 1, 1})
+	zw.Close()
+	rd, err = applyFilter(bytes.NewReader(buf.Bytes()), "FlateDecode", param)
+	require.NoError(t, err)
+	out, err = io.ReadAll(rd)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3, 4}, out)
+}
@@ -0,0 +1,674 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+// Fonts without a /ToUnicode CMap often still carry enough in their glyph
+// names and embedded font programs to recover the text. A glyph name is
+// read with the Adobe Glyph List conventions (uniXXXX, uXXXXX, ligatures
+// joined by "_", suffixes after "."); glyphs are named by the TrueType
+// post table or the CFF charset, and a TrueType Unicode cmap gives the
+// Unicode value of a glyph directly.
+
+// A fontProgram holds what text extraction needs from an embedded
+// TrueType, OpenType or CFF font program.
+type fontProgram struct {
+	codeGIDs map[int]int    // glyph of each code in the font's built-in encoding
+	unicode  map[int]rune   // Unicode value of each glyph, from a Unicode cmap
+	names    []string       // glyph names by glyph ID
+	nameGIDs map[string]int // glyph ID of each name
+}
+
+// glyphText returns the text of glyph gid, or "" if it is unknown.
+func (p *fontProgram) glyphText(gid int) string {
+	if r, ok := p.unicode[gid]; ok {
+		return string(r)
+	}
+	if gid >= 0 && gid < len(p.names) {
+		return glyphNameText(p.names[gid])
+	}
+	return ""
+}
+
+// nameGID returns the glyph ID of a glyph name, either a name of the font
+// program or a name that spells a glyph ID (g12, gid12, glyph12, index12).
+func (p *fontProgram) nameGID(name string) (int, bool) {
+	if gid, ok := p.nameGIDs[name]; ok {
+		return gid, true
+	}
+	for _, prefix := range []string{"gid", "glyph", "index", "g"} {
+		if s, ok := strings.CutPrefix(name, prefix); ok {
+			if gid, err := strconv.Atoi(s); err == nil && gid >= 0 {
+				return gid, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// setNames records the glyph names by glyph ID.
+func (p *fontProgram) setNames(names []string) {
+	p.names = names
+	p.nameGIDs = make(map[string]int, len(names))
+	for gid, name := range names {
+		if _, dup := p.nameGIDs[name]; !dup && name != "" {
+			p.nameGIDs[name] = gid
+		}
+	}
+}
+
+// glyphNameText returns the text of a glyph name, or "" if the name does
+// not identify any characters.
+func glyphNameText(name string) string {
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		name = name[:i]
+	}
+	if name == "" {
+		return ""
+	}
+	var sb strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if r, ok := nameToRune[part]; ok {
+			sb.WriteRune(r)
+			continue
+		}
+		runes := glyphNameRunes(part)
+		if runes == nil {
+			return ""
+		}
+		for _, r := range runes {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// glyphNameRunes decodes the uniXXXX[XXXX...] and uXXXX[X[X]] glyph names.
+func glyphNameRunes(name string) []rune {
+	hexRune := func(s string) (rune, bool) {
+		v, err := strconv.ParseUint(s, 16, 32)
+		if err != nil || strings.ToUpper(s) != s || (v >= 0xd800 && v <= 0xdfff) || v > 0x10ffff {
+			return 0, false
+		}
+		return rune(v), true
+	}
+	if s, ok := strings.CutPrefix(name, "uni"); ok && len(s) > 0 && len(s)%4 == 0 {
+		var runes []rune
+		for ; len(s) > 0; s = s[4:] {
+			r, ok := hexRune(s[:4])
+			if !ok {
+				return nil
+			}
+			runes = append(runes, r)
+		}
+		return runes
+	}
+	if s, ok := strings.CutPrefix(name, "u"); ok && len(s) >= 4 && len(s) <= 6 {
+		if r, ok := hexRune(s); ok {
+			return []rune{r}
+		}
+	}
+	return nil
+}
+
+// program returns the parsed embedded font program of the font (or of its
+// descendant font), or nil if there is none or it cannot be read. Parsed
+// programs are cached by the Reader.
+func (f *Font) program() *fontProgram {
+	fd := f.V.Key("FontDescriptor")
+	if f.V.Key("Subtype").Name() == "Type0" {
+		fd = f.V.Key("DescendantFonts").Index(0).Key("FontDescriptor")
+	}
+	strm := fd.Key("FontFile2")
+	if strm.Kind() != Stream {
+		strm = fd.Key("FontFile3")
+	}
+	if strm.Kind() != Stream {
+		return nil
+	}
+	var cache *sync.Map
+	if strm.r != nil {
+		cache = strm.r.fontPrograms
+	}
+	if cache != nil {
+		if p, ok := cache.Load(strm.ptr); ok {
+			return p.(*fontProgram)
+		}
+	}
+	p, err := parseFontProgram(strm)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("program: font program %d %d R: %v", strm.ptr.id, strm.ptr.gen, err), true)
+		p = nil
+	}
+	if cache != nil {
+		cache.Store(strm.ptr, p)
+	}
+	return p
+}
+
+// parseFontProgram reads a FontFile2 or FontFile3 stream.
+func parseFontProgram(strm Value) (p *fontProgram, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			p = nil
+			err = fmt.Errorf("malformed font program: %v", r)
+		}
+	}()
+
+	data, err := readStream(strm)
+	if err != nil {
+		return nil, err
+	}
+	switch sub := strm.Key("Subtype").Name(); sub {
+	case "Type1C", "CIDFontType0C":
+		return parseCFF(data)
+	case "", "OpenType":
+		return parseSFNT(data)
+	default:
+		return nil, fmt.Errorf("unsupported font program %s", sub)
+	}
+}
+
+// parseSFNT reads the cmap and post tables of a TrueType or OpenType font,
+// and the glyph names of its CFF table if post has none.
+func parseSFNT(data []byte) (*fontProgram, error) {
+	if len(data) < 12 {
+		return nil, errors.New("truetype: short header")
+	}
+	tables := make(map[string][]byte)
+	n := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < n && 12+16*i+16 <= len(data); i++ {
+		rec := data[12+16*i:]
+		off, length := binary.BigEndian.Uint32(rec[8:]), binary.BigEndian.Uint32(rec[12:])
+		if uint64(off)+uint64(length) <= uint64(len(data)) {
+			tables[string(rec[:4])] = data[off : off+length]
+		}
+	}
+	p := &fontProgram{}
+	if t, ok := tables["cmap"]; ok {
+		p.readCmapTable(t)
+	}
+	if t, ok := tables["post"]; ok {
+		p.readPostTable(t)
+	}
+	if t, ok := tables["CFF "]; ok && p.names == nil {
+		if cff, err := parseCFF(t); err == nil {
+			p.setNames(cff.names)
+		}
+	}
+	return p, nil
+}
+
+// readCmapTable reads the Unicode subtable of a cmap table, reversed to
+// map glyphs to Unicode, and the symbolic (3,0) or Macintosh (1,0)
+// subtable as the font's built-in encoding.
+func (p *fontProgram) readCmapTable(t []byte) {
+	subtables := make(map[[2]uint16]map[int]int)
+	n := int(binary.BigEndian.Uint16(t[2:]))
+	for i := 0; i < n; i++ {
+		rec := t[4+8*i:]
+		id := [2]uint16{binary.BigEndian.Uint16(rec), binary.BigEndian.Uint16(rec[2:])}
+		if m := cmapSubtable(t, int(binary.BigEndian.Uint32(rec[4:]))); m != nil {
+			subtables[id] = m
+		}
+	}
+
+	for _, id := range [][2]uint16{{3, 10}, {3, 1}, {0, 4}, {0, 3}, {0, 2}, {0, 1}, {0, 0}} {
+		m, ok := subtables[id]
+		if !ok {
+			continue
+		}
+		p.unicode = make(map[int]rune, len(m))
+		for code, gid := range m {
+			if r, dup := p.unicode[gid]; !dup || rune(code) < r {
+				p.unicode[gid] = rune(code)
+			}
+		}
+		break
+	}
+
+	p.codeGIDs = make(map[int]int)
+	if m, ok := subtables[[2]uint16{3, 0}]; ok {
+		// symbolic fonts map the codes to U+F000-U+F0FF or to 0-255
+		for c := 0; c < 256; c++ {
+			for _, base := range []int{0xf000, 0xf100, 0xf200, 0} {
+				if gid, ok := m[base+c]; ok {
+					p.codeGIDs[c] = gid
+					break
+				}
+			}
+		}
+	}
+	if m, ok := subtables[[2]uint16{1, 0}]; ok {
+		for c := 0; c < 256; c++ {
+			if _, ok := p.codeGIDs[c]; !ok {
+				if gid, ok := m[c]; ok {
+					p.codeGIDs[c] = gid
+				}
+			}
+		}
+	}
+}
+
+// cmapSubtable decodes the cmap subtable at off, in format 0, 4, 6 or 12,
+// as a map from character code to glyph ID.
+func cmapSubtable(t []byte, off int) map[int]int {
+	if off < 0 || off+4 > len(t) {
+		return nil
+	}
+	u16 := func(i int) int { return int(binary.BigEndian.Uint16(t[i:])) }
+	m := make(map[int]int)
+	switch u16(off) {
+	case 0:
+		for c := 0; c < 256; c++ {
+			if gid := int(t[off+6+c]); gid != 0 {
+				m[c] = gid
+			}
+		}
+	case 4:
+		segX2 := u16(off + 6)
+		ends := off + 14
+		starts := ends + segX2 + 2
+		deltas := starts + segX2
+		rangeOffsets := deltas + segX2
+		for s := 0; s < segX2/2; s++ {
+			end, start := u16(ends+2*s), u16(starts+2*s)
+			delta, ro := u16(deltas+2*s), u16(rangeOffsets+2*s)
+			for c := start; c <= end && c != 0xffff; c++ {
+				gid := c + delta
+				if ro != 0 {
+					if gid = u16(rangeOffsets + 2*s + ro + 2*(c-start)); gid != 0 {
+						gid += delta
+					}
+				}
+				if gid &= 0xffff; gid != 0 {
+					m[c] = gid
+				}
+			}
+		}
+	case 6:
+		first, count := u16(off+6), u16(off+8)
+		for i := 0; i < count; i++ {
+			if gid := u16(off + 10 + 2*i); gid != 0 {
+				m[first+i] = gid
+			}
+		}
+	case 12:
+		groups := int(binary.BigEndian.Uint32(t[off+12:]))
+		for i := 0; i < groups; i++ {
+			g := t[off+16+12*i:]
+			start, end := binary.BigEndian.Uint32(g), binary.BigEndian.Uint32(g[4:])
+			gid := int(binary.BigEndian.Uint32(g[8:]))
+			if end < start || end > 0x10ffff || end-start > 0xffff {
+				continue
+			}
+			for c := start; c <= end; c++ {
+				m[int(c)] = gid + int(c-start)
+			}
+		}
+	default:
+		return nil
+	}
+	return m
+}
+
+// readPostTable reads the glyph names of a version 1 or 2 post table.
+func (p *fontProgram) readPostTable(t []byte) {
+	switch binary.BigEndian.Uint32(t) {
+	case 0x00010000:
+		p.setNames(macGlyphNames)
+	case 0x00020000:
+		n := int(binary.BigEndian.Uint16(t[32:]))
+		var custom []string
+		for pos := 34 + 2*n; pos < len(t); {
+			l := int(t[pos])
+			if pos+1+l > len(t) {
+				break
+			}
+			custom = append(custom, string(t[pos+1:pos+1+l]))
+			pos += 1 + l
+		}
+		names := make([]string, n)
+		for gid := range names {
+			i := int(binary.BigEndian.Uint16(t[34+2*gid:]))
+			switch {
+			case i < len(macGlyphNames):
+				names[gid] = macGlyphNames[i]
+			case i-len(macGlyphNames) < len(custom):
+				names[gid] = custom[i-len(macGlyphNames)]
+			}
+		}
+		p.setNames(names)
+	}
+}
+
+// parseCFF reads the glyph names (charset) and built-in encoding of the
+// first font of a CFF font program. CID-keyed fonts have no glyph names.
+func parseCFF(data []byte) (*fontProgram, error) {
+	if len(data) < 4 || data[0] != 1 {
+		return nil, errors.New("cff: bad header")
+	}
+	_, pos := cffIndex(data, int(data[2])) // Name INDEX
+	top, pos := cffIndex(data, pos)
+	strs, _ := cffIndex(data, pos)
+	if len(top) == 0 {
+		return nil, errors.New("cff: no Top DICT")
+	}
+	dict := cffDict(top[0])
+	p := &fontProgram{}
+	if _, ok := dict[1230]; ok { // ROS
+		return p, nil
+	}
+	charStrings, ok := dict[17]
+	if !ok {
+		return nil, errors.New("cff: no CharStrings")
+	}
+	glyphs, _ := cffIndex(data, charStrings)
+	sidName := func(sid int) string {
+		switch {
+		case sid < len(cffStandardStrings):
+			return cffStandardStrings[sid]
+		case sid >= 391 && sid-391 < len(strs):
+			return string(strs[sid-391])
+		}
+		return ""
+	}
+
+	names := make([]string, len(glyphs))
+	switch off := dict[15]; off {
+	case 0: // ISOAdobe
+		for gid := range names {
+			names[gid] = sidName(gid)
+		}
+	case 1, 2: // Expert, ExpertSubset
+	default:
+		format := data[off]
+		pos := off + 1
+		for gid := 1; gid < len(names); {
+			sid := int(binary.BigEndian.Uint16(data[pos:]))
+			pos += 2
+			if format == 0 {
+				names[gid] = sidName(sid)
+				gid++
+				continue
+			}
+			left := int(data[pos])
+			pos++
+			if format == 2 {
+				left = left<<8 | int(data[pos])
+				pos++
+			}
+			for i := 0; i <= left && gid < len(names); i++ {
+				names[gid] = sidName(sid + i)
+				gid++
+			}
+		}
+	}
+	if len(names) > 0 {
+		names[0] = ".notdef"
+	}
+	p.setNames(names)
+
+	// built-in encoding; the predefined Standard and Expert encodings
+	// are left to the font's /Encoding
+	if off := dict[16]; off > 1 {
+		p.codeGIDs = make(map[int]int)
+		format := data[off]
+		pos := off + 1
+		switch format & 0x7f {
+		case 0:
+			n := int(data[pos])
+			for i := 1; i <= n; i++ {
+				p.codeGIDs[int(data[pos+i])] = i
+			}
+			pos += 1 + n
+		case 1:
+			n := int(data[pos])
+			gid := 1
+			for i := 0; i < n; i++ {
+				first, left := int(data[pos+1+2*i]), int(data[pos+2+2*i])
+				for c := first; c <= first+left && c < 256; c++ {
+					p.codeGIDs[c] = gid
+					gid++
+				}
+			}
+			pos += 1 + 2*n
+		}
+		if format&0x80 != 0 {
+			n := int(data[pos])
+			for i := 0; i < n; i++ {
+				sup := data[pos+1+3*i:]
+				if gid, ok := p.nameGIDs[sidName(int(binary.BigEndian.Uint16(sup[1:])))]; ok {
+					p.codeGIDs[int(sup[0])] = gid
+				}
+			}
+		}
+	}
+	return p, nil
+}
+
+// cffIndex reads the INDEX at pos, returning its items and the position
+// after it.
+func cffIndex(data []byte, pos int) ([][]byte, int) {
+	count := int(binary.BigEndian.Uint16(data[pos:]))
+	if count == 0 {
+		return nil, pos + 2
+	}
+	offSize := int(data[pos+2])
+	offset := func(i int) int {
+		v := 0
+		for _, b := range data[pos+3+i*offSize : pos+3+(i+1)*offSize] {
+			v = v<<8 | int(b)
+		}
+		return v
+	}
+	base := pos + 3 + (count+1)*offSize - 1
+	items := make([][]byte, count)
+	for i := range items {
+		items[i] = data[base+offset(i) : base+offset(i+1)]
+	}
+	return items, base + offset(count)
+}
+
+// cffDict reads the integer operands of a CFF DICT, keyed by operator;
+// two-byte operators 12 x are keyed 1200+x. Real operands are skipped.
+func cffDict(b []byte) map[int]int {
+	dict := make(map[int]int)
+	var operand int
+	for i := 0; i < len(b); {
+		b0 := int(b[i])
+		switch {
+		case b0 <= 21:
+			op := b0
+			if b0 == 12 {
+				op = 1200 + int(b[i+1])
+				i++
+			}
+			dict[op] = operand
+			operand = 0
+			i++
+		case b0 == 28:
+			operand = int(int16(binary.BigEndian.Uint16(b[i+1:])))
+			i += 3
+		case b0 == 29:
+			operand = int(int32(binary.BigEndian.Uint32(b[i+1:])))
+			i += 5
+		case b0 == 30:
+			for i++; i < len(b) && b[i]&0x0f != 0x0f && b[i]&0xf0 != 0xf0; i++ {
+			}
+			i++
+		case b0 >= 32 && b0 <= 246:
+			operand = b0 - 139
+			i++
+		case b0 >= 247 && b0 <= 250:
+			operand = (b0-247)*256 + int(b[i+1]) + 108
+			i += 2
+		case b0 >= 251 && b0 <= 254:
+			operand = -(b0-251)*256 - int(b[i+1]) - 108
+			i += 2
+		default:
+			i++
+		}
+	}
+	return dict
+}
+
+// cffStandardStrings are the predefined CFF strings of the ISOAdobe
+// charset, SIDs 0 to 228. The expert strings that follow are not needed
+// to recover text.
+var cffStandardStrings = strings.Fields(`.notdef space exclam quotedbl
+numbersign dollar percent ampersand quoteright parenleft parenright asterisk
+plus comma hyphen period slash zero one two three four five six seven eight
+nine colon semicolon less equal greater question at A B C D E F G H I J K L
+M N O P Q R S T U V W X Y Z bracketleft backslash bracketright asciicircum
+underscore quoteleft a b c d e f g h i j k l m n o p q r s t u v w x y z
+braceleft bar braceright asciitilde exclamdown cent sterling fraction yen
+florin section currency quotesingle quotedblleft guillemotleft guilsinglleft
+guilsinglright fi fl endash dagger daggerdbl periodcentered paragraph bullet
+quotesinglbase quotedblbase quotedblright guillemotright ellipsis perthousand
+questiondown grave acute circumflex tilde macron breve dotaccent dieresis
+ring cedilla hungarumlaut ogonek caron emdash AE ordfeminine Lslash Oslash
+OE ordmasculine ae dotlessi lslash oslash oe germandbls onesuperior
+logicalnot mu trademark Eth onehalf plusminus Thorn onequarter divide
+brokenbar degree thorn threequarters twosuperior registered minus eth
+multiply threesuperior copyright Aacute Acircumflex Adieresis Agrave Aring
+Atilde Ccedilla Eacute Ecircumflex Edieresis Egrave Iacute Icircumflex
+Idieresis Igrave Ntilde Oacute Ocircumflex Odieresis Ograve Otilde Scaron
+Uacute Ucircumflex Udieresis Ugrave Yacute Ydieresis Zcaron aacute
+acircumflex adieresis agrave aring atilde ccedilla eacute ecircumflex
+edieresis egrave iacute icircumflex idieresis igrave ntilde oacute
+ocircumflex odieresis ograve otilde scaron uacute ucircumflex udieresis
+ugrave yacute ydieresis zcaron`)
+
+// macGlyphNames are the 258 standard Macintosh glyph names that post
+// tables refer to by index.
+var macGlyphNames = strings.Fields(`.notdef .null nonmarkingreturn space
+exclam quotedbl numbersign dollar percent ampersand quotesingle parenleft
+parenright asterisk plus comma hyphen period slash zero one two three four
+five six seven eight nine colon semicolon less equal greater question at A
+B C D E F G H I J K L M N O P Q R S T U V W X Y Z bracketleft backslash
+bracketright asciicircum underscore grave a b c d e f g h i j k l m n o p q
+r s t u v w x y z braceleft bar braceright asciitilde Adieresis Aring
+Ccedilla Eacute Ntilde Odieresis Udieresis aacute agrave acircumflex
+adieresis atilde aring ccedilla eacute egrave ecircumflex edieresis iacute
+igrave icircumflex idieresis ntilde oacute ograve ocircumflex odieresis
+otilde uacute ugrave ucircumflex udieresis dagger degree cent sterling
+section bullet paragraph germandbls registered copyright trademark acute
+dieresis notequal AE Oslash infinity plusminus lessequal greaterequal yen mu
+partialdiff summation product pi integral ordfeminine ordmasculine Omega ae
+oslash questiondown exclamdown logicalnot radical florin approxequal Delta
+guillemotleft guillemotright ellipsis nonbreakingspace Agrave Atilde Otilde
+OE oe endash emdash quotedblleft quotedblright quoteleft quoteright divide
+lozenge ydieresis Ydieresis fraction currency guilsinglleft guilsinglright
+fi fl daggerdbl periodcentered quotesinglbase quotedblbase perthousand
+Acircumflex Ecircumflex Aacute Edieresis Egrave Iacute Icircumflex
+Idieresis Igrave Oacute Ocircumflex apple Ograve Uacute Ucircumflex Ugrave
+dotlessi circumflex tilde macron breve dotaccent ring cedilla hungarumlaut
+ogonek caron Lslash lslash Scaron scaron Zcaron zcaron brokenbar Eth eth
+Yacute yacute Thorn thorn minus multiply onesuperior twosuperior
+threesuperior onehalf onequarter threequarters franc Gbreve gbreve
+Idotaccent Scedilla scedilla Cacute cacute Ccaron ccaron dcroat`)
+
+// glyphEncoder decodes the codes of a simple font through the text of its
+// glyphs, and the codes whose glyph is unknown through base.
+type glyphEncoder struct {
+	text [256]string
+	base TextEncoding
+}
+
+func (e *glyphEncoder) Decode(raw string) string {
+	logger.Debug("decoding glyphEncoder")
+	var sb strings.Builder
+	for i := 0; i < len(raw); i++ {
+		if s := e.text[raw[i]]; s != "" {
+			sb.WriteString(s)
+		} else {
+			sb.WriteString(e.base.Decode(raw[i : i+1]))
+		}
+	}
+	return sb.String()
+}
+
+// glyphEncoding returns the encoder of a simple font without /ToUnicode.
+// Each code is decoded through its glyph: the glyph name given by
+// /Differences, or the glyph of the embedded font program that the name
+// or the program's built-in encoding selects. Codes with no known glyph
+// text are decoded with base.
+func (f *Font) glyphEncoding(differences Value, base TextEncoding) TextEncoding {
+	names := make(map[int]string)
+	code := -1
+	for i := 0; i < differences.Len(); i++ {
+		switch x := differences.Index(i); x.Kind() {
+		case Integer:
+			code = int(x.Int64())
+		case Name:
+			if code >= 0 && code < 256 {
+				names[code] = x.Name()
+			}
+			code++
+		}
+	}
+	prog := f.program()
+	if len(names) == 0 && prog == nil {
+		return base
+	}
+
+	e := &glyphEncoder{base: base}
+	for c := range e.text {
+		if name, ok := names[c]; ok {
+			e.text[c] = glyphNameText(name)
+			if e.text[c] == "" && prog != nil {
+				if gid, ok := prog.nameGID(name); ok {
+					e.text[c] = prog.glyphText(gid)
+				}
+			}
+		} else if prog != nil {
+			if gid, ok := prog.codeGIDs[c]; ok {
+				e.text[c] = prog.glyphText(gid)
+			}
+		}
+	}
+	return e
+}
+
+// cidGlyphText returns the text of each CID of a CIDFontType2 font through
+// the glyphs of its embedded TrueType program, or nil if it has none.
+func (f *Font) cidGlyphText() func(cid int) string {
+	desc := f.V.Key("DescendantFonts").Index(0)
+	if desc.Key("Subtype").Name() != "CIDFontType2" {
+		return nil
+	}
+	prog := f.program()
+	if prog == nil || (prog.unicode == nil && prog.names == nil) {
+		return nil
+	}
+	var gids []byte
+	if m := desc.Key("CIDToGIDMap"); m.Kind() == Stream {
+		var err error
+		if gids, err = readStream(m); err != nil {
+			logger.Debug(fmt.Sprintf("cidGlyphText: CIDToGIDMap: %v", err), true)
+			return nil
+		}
+	}
+	return func(cid int) string {
+		gid := cid // /Identity
+		if gids != nil {
+			if 2*cid+2 > len(gids) {
+				return ""
+			}
+			gid = int(binary.BigEndian.Uint16(gids[2*cid:]))
+		}
+		return prog.glyphText(gid)
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sfntFont assembles a TrueType font program from its tables.
+func sfntFont(tables map[string][]byte) []byte {
+	var tags []string
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	var head, body bytes.Buffer
+	binary.Write(&head, binary.BigEndian, []uint32{0x00010000})
+	binary.Write(&head, binary.BigEndian, []uint16{uint16(len(tags)), 0, 0, 0})
+	off := 12 + 16*len(tags)
+	for _, tag := range tags {
+		t := tables[tag]
+		head.WriteString(tag)
+		binary.Write(&head, binary.BigEndian, []uint32{0, uint32(off + body.Len()), uint32(len(t))})
+		body.Write(t)
+		for body.Len()%4 != 0 {
+			body.WriteByte(0)
+		}
+	}
+	return append(head.Bytes(), body.Bytes()...)
+}
+
+// unicodeCmap returns a cmap table with one (3,1) format 4 subtable
+// mapping the characters from first to last to glyphs from gid on.
+func unicodeCmap(first, last rune, gid int) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, []uint16{0, 1, 3, 1, 0, 12}) // one subtable at offset 12
+	seg := []uint16{
+		4, 32, 0, 4, 4, 1, 0, // format, length, language, segCountX2, searchRange, entrySelector, rangeShift
+		uint16(last), 0xffff, 0, // endCode, reservedPad
+		uint16(first), 0xffff, // startCode
+		uint16(gid - int(first)), 1, // idDelta
+		0, 0, // idRangeOffset
+	}
+	binary.Write(&b, binary.BigEndian, seg)
+	return b.Bytes()
+}
+
+// postTable returns a version 2 post table naming the glyphs.
+func postTable(names ...string) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, []uint32{0x00020000, 0, 0, 0, 0, 0, 0, 0})
+	binary.Write(&b, binary.BigEndian, uint16(len(names)))
+	var custom []string
+	for _, name := range names {
+		i := -1
+		for j, mac := range macGlyphNames {
+			if mac == name {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			i = len(macGlyphNames) + len(custom)
+			custom = append(custom, name)
+		}
+		binary.Write(&b, binary.BigEndian, uint16(i))
+	}
+	for _, name := range custom {
+		b.WriteByte(byte(len(name)))
+		b.WriteString(name)
+	}
+	return b.Bytes()
+}
+
+// cffIndexData encodes a CFF INDEX with one-byte offsets.
+func cffIndexData(items ...string) []byte {
+	if len(items) == 0 {
+		return []byte{0, 0}
+	}
+	b := []byte{0, byte(len(items)), 1, 1}
+	off := 1
+	for _, item := range items {
+		off += len(item)
+		b = append(b, byte(off))
+	}
+	for _, item := range items {
+		b = append(b, item...)
+	}
+	return b
+}
+
+// cffFont assembles a CFF font program with four glyphs, A (SID 34),
+// Euro and uni0416, selected by the codes 0x41, 0x80 and 0x81 of its
+// built-in encoding.
+func cffFont() []byte {
+	cffInt := func(v int) string {
+		return string([]byte{29, byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)})
+	}
+	header := []byte{1, 0, 4, 1}
+	name := cffIndexData("F")
+	strs := cffIndexData("Euro", "uni0416")
+	gsubrs := cffIndexData()
+	charStrings := cffIndexData("\x0e", "\x0e", "\x0e", "\x0e")
+	charset := []byte{0, 0, 34, 0x01, 0x87, 0x01, 0x88} // SIDs 34, 391, 392
+	encoding := []byte{0, 3, 0x41, 0x80, 0x81}
+
+	topLen := len(cffIndexData(strings.Repeat("x", 18)))
+	base := len(header) + len(name) + topLen + len(strs) + len(gsubrs)
+	top := cffIndexData(cffInt(base+len(charStrings)) + "\x0f" +
+		cffInt(base+len(charStrings)+len(charset)) + "\x10" +
+		cffInt(base) + "\x11")
+
+	var b bytes.Buffer
+	for _, part := range [][]byte{header, name, top, strs, gsubrs, charStrings, charset, encoding} {
+		b.Write(part)
+	}
+	return b.Bytes()
+}
+
+func TestGlyphNameText(t *testing.T) {
+	tests := map[string]string{
+		"A":           "A",
+		"Euro":        "€",
+		"uni20AC":     "€",
+		"uni00660069": "fi",
+		"u1F600":      "😀",
+		"f_f_i":       "ffi",
+		"a.sc":        "a",
+		"uni20ac":     "", // lowercase hex is not a Unicode glyph name
+		"uniD800":     "",
+		"g123":        "",
+		".notdef":     "",
+	}
+	for name, want := range tests {
+		assert.Equal(t, want, glyphNameText(name), name)
+	}
+}
+
+func TestGlyphNameTables(t *testing.T) {
+	assert.Len(t, macGlyphNames, 258)
+	assert.Len(t, cffStandardStrings, 229)
+	assert.Equal(t, "dcroat", macGlyphNames[257])
+	assert.Equal(t, "zcaron", cffStandardStrings[228])
+}
+
+func TestParseSFNT(t *testing.T) {
+	data := sfntFont(map[string][]byte{
+		"cmap": unicodeCmap('A', 'C', 1),
+		"post": postTable(".notdef", "A", "B", "C", "f_f_i", "uni20AC"),
+	})
+	p, err := parseSFNT(data)
+	require.NoError(t, err)
+	assert.Equal(t, "A", p.glyphText(1))
+	assert.Equal(t, "C", p.glyphText(3))
+	assert.Equal(t, "ffi", p.glyphText(4))
+	assert.Equal(t, "€", p.glyphText(5))
+	assert.Equal(t, "", p.glyphText(6))
+
+	gid, ok := p.nameGID("f_f_i")
+	assert.True(t, ok)
+	assert.Equal(t, 4, gid)
+	gid, ok = p.nameGID("g2")
+	assert.True(t, ok)
+	assert.Equal(t, 2, gid)
+}
+
+func TestParseCFF(t *testing.T) {
+	p, err := parseCFF(cffFont())
+	require.NoError(t, err)
+	assert.Equal(t, []string{".notdef", "A", "Euro", "uni0416"}, p.names)
+	assert.Equal(t, map[int]int{0x41: 1, 0x80: 2, 0x81: 3}, p.codeGIDs)
+	assert.Equal(t, "Ж", p.glyphText(3))
+}
+
+func TestFontProgramText(t *testing.T) {
+	text := func(t *testing.T, font, program, content string) string {
+		t.Helper()
+		data := buildPDF([]string{
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+			streamObj("", []byte(content)),
+			font,
+			program,
+		}, "")
+		s, err := newTestReader(t, data).Page(1).GetPlainText(nil)
+		require.NoError(t, err)
+		return strings.TrimSpace(s)
+	}
+	trueType := streamObj("", sfntFont(map[string][]byte{
+		"cmap": unicodeCmap('A', 'C', 1),
+		"post": postTable(".notdef", "A", "B", "C", "f_f_i", "uni20AC"),
+	}))
+
+	// Differences naming glyphs by ID, by Unicode value and by AGL name
+	got := text(t, "<< /Type /Font /Subtype /TrueType /BaseFont /AAAAAA+Test /FontDescriptor << /FontFile2 6 0 R >>"+
+		" /Encoding << /Differences [1 /g4 /g5 /g2 /uni0041 /period] >> >>",
+		trueType, "BT /F1 12 Tf 72 700 Td <0102030405> Tj ET")
+	assert.Equal(t, "ffi€BA.", got)
+
+	// CIDFontType2 with Identity-H and no ToUnicode: CIDs are glyph IDs
+	got = text(t, "<< /Type /Font /Subtype /Type0 /BaseFont /AAAAAA+Test /Encoding /Identity-H"+
+		" /DescendantFonts [<< /Subtype /CIDFontType2 /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) >>"+
+		" /CIDToGIDMap /Identity /FontDescriptor << /FontFile2 6 0 R >> >>] >>",
+		trueType, "BT /F1 12 Tf 72 700 Td <000300040001> Tj ET")
+	assert.Equal(t, "CffiA", got)
+
+	// a Korea1 font maps only its Latin CIDs by the collection; the
+	// Hangul of CID 100 comes from the font program
+	names := []string{".notdef"}
+	for gid := 1; gid < 100; gid++ {
+		names = append(names, "g"+strconv.Itoa(gid))
+	}
+	korean := streamObj("", sfntFont(map[string][]byte{"post": postTable(append(names, "uniAC00")...)}))
+	got = text(t, "<< /Type /Font /Subtype /Type0 /BaseFont /AAAAAA+Test /Encoding /Identity-H"+
+		" /DescendantFonts [<< /Subtype /CIDFontType2 /CIDSystemInfo << /Registry (Adobe) /Ordering (Korea1) >>"+
+		" /CIDToGIDMap /Identity /FontDescriptor << /FontFile2 6 0 R >> >>] >>",
+		korean, "BT /F1 12 Tf 72 700 Td <00220064> Tj ET")
+	assert.Equal(t, "A가", got)
+
+	// CFF program with a built-in encoding and no /Encoding
+	got = text(t, "<< /Type /Font /Subtype /Type1 /BaseFont /AAAAAA+Test /FontDescriptor << /FontFile3 6 0 R >> >>",
+		streamObj("/Subtype /Type1C", cffFont()), "BT /F1 12 Tf 72 700 Td <418081> Tj ET")
+	assert.Equal(t, "A€Ж", got)
+}
@@ -0,0 +1,218 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+// FieldType is the kind of an interactive form field, derived from its
+// /FT and button or choice flags.
+type FieldType string
+
+const (
+	TextField       FieldType = "text"
+	CheckBoxField   FieldType = "checkbox"
+	RadioField      FieldType = "radio"
+	PushButtonField FieldType = "pushbutton"
+	ComboBoxField   FieldType = "combobox"
+	ListBoxField    FieldType = "listbox"
+	SignatureField  FieldType = "signature"
+)
+
+// Field flags (/Ff) used to tell button and choice fields apart.
+const (
+	fieldFlagRadio      = 1 << 15
+	fieldFlagPushButton = 1 << 16
+	fieldFlagCombo      = 1 << 17
+)
+
+// A FormField is a terminal field of the document's AcroForm.
+type FormField struct {
+	Name    string    `json:"name"` // fully qualified name, partial names joined by "."
+	Type    FieldType `json:"type"`
+	Flags   int       `json:"flags"`             // /Ff bits, such as read-only (1) and required (2)
+	Value   string    `json:"value,omitempty"`   // text, selected choice, button state or signer name
+	Values  []string  `json:"values,omitempty"`  // selected items of a multiple-selection list box
+	Options []string  `json:"options,omitempty"` // export values of the items offered by a choice field
+	Signed  bool      `json:"signed,omitempty"`  // signature fields holding a signature
+	Page    int       `json:"page,omitempty"`    // page of the field's first widget, starting at 1
+	Rect    Rect      `json:"rect"`              // rectangle of the field's first widget
+	Tooltip string    `json:"tooltip,omitempty"` // /TU alternate name
+}
+
+// fieldAttrs holds the attributes a field inherits from its ancestors.
+type fieldAttrs struct {
+	name, ft string
+	ff       int
+	v        Value
+}
+
+// FormFields returns the terminal fields of the document's interactive
+// form (/Root/AcroForm) in field tree order, with inherited field type,
+// flags and value resolved. Push buttons are included; they have no value.
+func (r *Reader) FormFields() (fields []FormField, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			fields = nil
+			logger.Error(fmt.Sprint(rec))
+			err = recoveredError(rec)
+		}
+	}()
+	r = r.raising()
+
+	list := r.Trailer().Key("Root").Key("AcroForm").Key("Fields")
+	if list.Len() == 0 {
+		return nil, nil
+	}
+	var widgetPages map[objptr]int
+	seen := make(map[objptr]bool)
+	var walk func(node Value, inherited fieldAttrs, depth int)
+	walk = func(node Value, inherited fieldAttrs, depth int) {
+		if node.Kind() != Dict || depth > maxTreeDepth || (node.ptr != objptr{} && seen[node.ptr]) {
+			return
+		}
+		seen[node.ptr] = true
+		attrs := inherited
+		if t := node.Key("T").Text(); t != "" {
+			attrs.name = strings.TrimPrefix(inherited.name+"."+t, ".")
+		}
+		if ft := node.Key("FT").Name(); ft != "" {
+			attrs.ft = ft
+		}
+		if ff := node.Key("Ff"); ff.Kind() == Integer {
+			attrs.ff = int(ff.Int64())
+		}
+		if v := node.Key("V"); v.Kind() != Null {
+			attrs.v = v
+		}
+
+		// Kids without /T are the field's widget annotations.
+		kids := node.Key("Kids")
+		var widgets []Value
+		for i := 0; i < kids.Len(); i++ {
+			kid := kids.Index(i)
+			if kid.Key("T").Kind() != Null {
+				walk(kid, attrs, depth+1)
+			} else if kid.Kind() == Dict {
+				widgets = append(widgets, kid)
+			}
+		}
+		if kids.Len() > 0 && len(widgets) == 0 {
+			return
+		}
+		if len(widgets) == 0 {
+			widgets = []Value{node} // field and widget merged
+		}
+		f := newFormField(node, attrs, widgets[0])
+		if p := widgets[0].Key("P"); p.Kind() == Dict {
+			f.Page = r.pageNumber(p.ptr)
+		} else {
+			if widgetPages == nil {
+				widgetPages = r.widgetPages()
+			}
+			f.Page = widgetPages[widgets[0].ptr]
+		}
+		fields = append(fields, f)
+	}
+	for i := 0; i < list.Len(); i++ {
+		walk(list.Index(i), fieldAttrs{}, 0)
+	}
+	logger.Debug(fmt.Sprintf("FormFields: %d fields", len(fields)), true)
+	return fields, nil
+}
+
+// newFormField decodes the type and value of the terminal field node.
+func newFormField(node Value, attrs fieldAttrs, widget Value) FormField {
+	f := FormField{
+		Name:    attrs.name,
+		Flags:   attrs.ff,
+		Rect:    rectValue(widget.Key("Rect")),
+		Tooltip: node.Key("TU").Text(),
+	}
+	v := attrs.v
+	switch attrs.ft {
+	case "Tx":
+		f.Type = TextField
+		f.Value = v.Text()
+		if v.Kind() == Stream {
+			f.Value = streamText(v)
+		}
+	case "Btn":
+		switch {
+		case attrs.ff&fieldFlagPushButton != 0:
+			f.Type = PushButtonField
+		case attrs.ff&fieldFlagRadio != 0:
+			f.Type = RadioField
+		default:
+			f.Type = CheckBoxField
+		}
+		if f.Type != PushButtonField {
+			f.Value = v.Name()
+			if f.Value == "" {
+				f.Value = widget.Key("AS").Name()
+			}
+		}
+	case "Ch":
+		f.Type = ListBoxField
+		if attrs.ff&fieldFlagCombo != 0 {
+			f.Type = ComboBoxField
+		}
+		if v.Kind() == Array {
+			for i := 0; i < v.Len(); i++ {
+				f.Values = append(f.Values, v.Index(i).Text())
+			}
+			if len(f.Values) > 0 {
+				f.Value = f.Values[0]
+			}
+		} else {
+			f.Value = v.Text()
+		}
+		opt := node.Key("Opt")
+		for i := 0; i < opt.Len(); i++ {
+			o := opt.Index(i)
+			if o.Kind() == Array {
+				o = o.Index(0) // [export value, display text]
+			}
+			f.Options = append(f.Options, o.Text())
+		}
+	case "Sig":
+		f.Type = SignatureField
+		f.Signed = v.Kind() == Dict
+		f.Value = v.Key("Name").Text()
+	default:
+		f.Type = FieldType(attrs.ft)
+	}
+	return f
+}
+
+// streamText returns the decoded contents of a text stream, which long
+// text field values may use instead of a string.
+func streamText(v Value) string {
+	rc := v.Reader()
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		logger.Error(fmt.Sprintf("reading text stream: %v", err))
+	}
+	return string(b)
+}
+
+// widgetPages maps each annotation in a page's /Annots to the page's
+// number, for widgets without a /P entry.
+func (r *Reader) widgetPages() map[objptr]int {
+	pages := make(map[objptr]int)
+	r.walkPages(func(n int, page Value) bool {
+		annots := page.Key("Annots")
+		for i := 0; i < annots.Len(); i++ {
+			pages[annots.Index(i).ptr] = n
+		}
+		return true
+	})
+	return pages
+}
@@ -0,0 +1,76 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormFields(t *testing.T) {
+	data := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [4 0 R 7 0 R 8 0 R 11 0 R 12 0 R 13 0 R] >> >>",
+		"<< /Type /Pages /Kids [3 0 R 10 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [5 0 R 6 0 R 8 0 R] >>",
+		// applicant.name and applicant.email inherit /FT from their parent
+		"<< /T (applicant) /FT /Tx /Kids [5 0 R 6 0 R] >>",
+		"<< /Type /Annot /Subtype /Widget /Parent 4 0 R /T (name) /V (Jane Doe) /Rect [100 700 300 720] /Ff 2 >>",
+		"<< /Type /Annot /Subtype /Widget /Parent 4 0 R /T (email) /V <FEFF006A00400065002E0078> /Rect [100 670 300 690] /P 3 0 R >>",
+		// radio group with one widget per option, value inherited from the field
+		"<< /T (plan) /FT /Btn /Ff 49152 /V /Gold /Kids [15 0 R 16 0 R] >>",
+		// check box merged with its widget, state from /AS
+		"<< /Type /Annot /Subtype /Widget /T (agree) /FT /Btn /AS /Yes /Rect [100 600 112 612] >>",
+		"<< /Type /Page /MediaBox [0 0 612 792] >>", // not in the page tree
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [11 0 R 12 0 R] >>",
+		"<< /Type /Annot /Subtype /Widget /T (colors) /FT /Ch /Opt [(red) [(grn) (Green)] (blue)] /V [(red) (blue)] /Rect [0 0 50 50] >>",
+		"<< /Type /Annot /Subtype /Widget /T (state) /FT /Ch /Ff 131072 /Opt [(NC) (SC)] /V (NC) /Rect [0 60 50 70] >>",
+		"<< /Type /Annot /Subtype /Widget /T (sig) /FT /Sig /V 14 0 R /Rect [0 0 0 0] /P 10 0 R >>",
+		"<< /Type /Sig /Filter /Adobe.PPKLite /Name (Jane Doe) /M (D:20260101) >>",
+		"<< /Type /Annot /Subtype /Widget /Parent 7 0 R /AS /Gold /Rect [100 500 112 512] /P 3 0 R >>",
+		"<< /Type /Annot /Subtype /Widget /Parent 7 0 R /AS /Off /Rect [100 480 112 492] /P 3 0 R >>",
+	}, "")
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	fields, err := r.FormFields()
+	require.NoError(t, err)
+	require.Len(t, fields, 7)
+
+	assert.Equal(t, FormField{Name: "applicant.name", Type: TextField, Flags: 2, Value: "Jane Doe", Page: 1,
+		Rect: Rect{Point{100, 700}, Point{300, 720}}}, fields[0])
+	assert.Equal(t, "applicant.email", fields[1].Name)
+	assert.Equal(t, "j@e.x", fields[1].Value)
+	assert.Equal(t, 1, fields[1].Page)
+
+	assert.Equal(t, RadioField, fields[2].Type)
+	assert.Equal(t, "Gold", fields[2].Value)
+	assert.Equal(t, Rect{Point{100, 500}, Point{112, 512}}, fields[2].Rect)
+
+	assert.Equal(t, CheckBoxField, fields[3].Type)
+	assert.Equal(t, "Yes", fields[3].Value)
+	assert.Equal(t, 1, fields[3].Page)
+
+	assert.Equal(t, ListBoxField, fields[4].Type)
+	assert.Equal(t, []string{"red", "blue"}, fields[4].Values)
+	assert.Equal(t, []string{"red", "grn", "blue"}, fields[4].Options)
+	assert.Equal(t, 2, fields[4].Page)
+
+	assert.Equal(t, ComboBoxField, fields[5].Type)
+	assert.Equal(t, "NC", fields[5].Value)
+
+	assert.Equal(t, SignatureField, fields[6].Type)
+	assert.True(t, fields[6].Signed)
+	assert.Equal(t, "Jane Doe", fields[6].Value)
+	assert.Equal(t, 2, fields[6].Page)
+}
+
+func TestFormFields_NoAcroForm(t *testing.T) {
+	r := annotationsTestReader(t)
+	fields, err := r.FormFields()
+	require.NoError(t, err)
+	assert.Empty(t, fields)
+}
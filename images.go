@@ -0,0 +1,430 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+// An Image is an image painted on a page, either an image XObject drawn
+// with the Do operator or an inline image (BI ... ID ... EI).
+type Image struct {
+	Name             string     `json:"name,omitempty"` // XObject resource name; empty for inline images
+	Inline           bool       `json:"inline,omitempty"`
+	Matrix           [6]float64 `json:"matrix"` // CTM when painted, mapping the unit square to page space
+	Rect             Rect       `json:"rect"`   // bounding box on the page
+	Width            int        `json:"width"`  // in samples
+	Height           int        `json:"height"`
+	ColorSpace       string     `json:"colorSpace,omitempty"` // color space family, such as DeviceRGB, ICCBased or Indexed
+	Components       int        `json:"components"`           // color components per sample
+	BitsPerComponent int        `json:"bitsPerComponent"`
+	Filter           string     `json:"filter,omitempty"` // last filter applied to the data, such as DCTDecode
+	ImageMask        bool       `json:"imageMask,omitempty"`
+
+	strm Value
+	cs   Value // color space, resolved through the page resources
+}
+
+// Filters that encode a whole image in a format of its own.
+var imageCodecs = map[string]bool{
+	"DCTDecode":      true, // JPEG
+	"JPXDecode":      true, // JPEG 2000
+	"JBIG2Decode":    true,
+	"CCITTFaxDecode": true,
+}
+
+// Images returns the images painted on the page, in content order,
+// including those inside Form XObjects.
+func (p Page) Images() (images []Image, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			images = nil
+			logger.Error(fmt.Sprint(r))
+			err = recoveredError(r)
+		}
+	}()
+	p = p.raising()
+
+	if p.V.IsNull() || p.V.Key("Contents").Kind() == Null {
+		return nil, nil
+	}
+	ctm := ident
+	var stack []matrix
+	forms := p.formStack()
+	add := func(name string, strm Value) {
+		img := newImage(strm, forms.resources(), ctm)
+		img.Name = name
+		img.Inline = name == ""
+		images = append(images, img)
+	}
+
+	var handle func(stk *Stack, op string)
+	handle = func(stk *Stack, op string) {
+		n := stk.Len()
+		args := make([]Value, n)
+		for i := n - 1; i >= 0; i-- {
+			args[i] = stk.Pop()
+		}
+		switch op {
+		case "q":
+			stack = append(stack, ctm)
+		case "Q":
+			if len(stack) > 0 {
+				ctm = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+		case "cm":
+			if len(args) != 6 {
+				logger.Error("bad cm")
+				return
+			}
+			var m matrix
+			for i := 0; i < 6; i++ {
+				m[i/2][i%2] = args[i].Float64()
+			}
+			m[2][2] = 1
+			ctm = m.mul(ctm)
+		case "Do":
+			if len(args) != 1 {
+				logger.Error("bad Do")
+				return
+			}
+			name := args[0].Name()
+			if x := forms.resources().Key("XObject").Key(name); x.Key("Subtype").Name() == "Image" {
+				add(name, x)
+				return
+			}
+			forms.doForm(name, func(form Value) {
+				saved, depth := ctm, len(stack)
+				ctm = formMatrix(form).mul(ctm)
+				forms.interpret(form, handle)
+				ctm, stack = saved, stack[:depth]
+			})
+		case "EI":
+			if len(args) == 1 && args[0].Kind() == Stream {
+				add("", args[0])
+			}
+		}
+	}
+	forms.interpret(p.V.Key("Contents"), handle)
+	logger.Debug(fmt.Sprintf("Images: %d for Page %d %d R", len(images), p.V.ptr.id, p.V.ptr.gen), true)
+	return images, nil
+}
+
+// newImage describes the image stream strm painted with the given CTM.
+func newImage(strm, res Value, ctm matrix) Image {
+	img := Image{
+		Matrix:           [6]float64{ctm[0][0], ctm[0][1], ctm[1][0], ctm[1][1], ctm[2][0], ctm[2][1]},
+		Width:            int(strm.Key("Width").Int64()),
+		Height:           int(strm.Key("Height").Int64()),
+		BitsPerComponent: int(strm.Key("BitsPerComponent").Int64()),
+		ImageMask:        strm.Key("ImageMask").Bool(),
+		strm:             strm,
+	}
+	var r Rect
+	for i, c := range [4][2]float64{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+		x, y := ctm.apply(c[0], c[1])
+		if i == 0 {
+			r = Rect{Point{x, y}, Point{x, y}}
+		}
+		r = r.union(Rect{Point{x, y}, Point{x, y}})
+	}
+	img.Rect = r
+	if names, _, _ := strm.filters(); len(names) > 0 {
+		img.Filter = names[len(names)-1]
+	}
+	if img.ImageMask {
+		img.Components, img.BitsPerComponent = 1, 1
+		return img
+	}
+	img.cs = resolveColorSpace(strm.Key("ColorSpace"), res)
+	img.ColorSpace = img.cs.Name()
+	if img.cs.Kind() == Array {
+		img.ColorSpace = img.cs.Index(0).Name()
+	}
+	img.Components = colorComponents(img.cs)
+	return img
+}
+
+// resolveColorSpace looks up a named color space in the resources; the
+// device color spaces are used by name.
+func resolveColorSpace(cs, res Value) Value {
+	if cs.Kind() == Name {
+		if named := res.Key("ColorSpace").Key(cs.Name()); named.Kind() != Null {
+			return named
+		}
+	}
+	return cs
+}
+
+// colorComponents returns the number of color components of a color
+// space, or 0 if it is unknown.
+func colorComponents(cs Value) int {
+	family := cs.Name()
+	if cs.Kind() == Array {
+		family = cs.Index(0).Name()
+	}
+	switch family {
+	case "DeviceGray", "CalGray", "Indexed", "Separation":
+		return 1
+	case "DeviceRGB", "CalRGB", "Lab":
+		return 3
+	case "DeviceCMYK":
+		return 4
+	case "ICCBased":
+		return int(cs.Index(1).Key("N").Int64())
+	case "DeviceN":
+		return cs.Index(1).Len()
+	}
+	return 0
+}
+
+// Data returns the image data with every filter applied except an image
+// codec: a DCTDecode image is returned as a JPEG file, a JPXDecode image
+// as a JPEG 2000 file, and so on. Other images are returned as decoded
+// samples, row by row, each row padded to a whole byte.
+func (img Image) Data() (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			data = nil
+			logger.Error(fmt.Sprint(r))
+			err = recoveredError(r)
+		}
+	}()
+	img.strm, img.cs = img.strm.raising(), img.cs.raising()
+
+	if img.strm.Kind() != Stream {
+		return nil, errors.New("image has no data stream")
+	}
+	rd := img.strm.rawReader()
+	names, params, err := img.strm.filters()
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range names {
+		if imageCodecs[name] {
+			break
+		}
+		if rd, err = applyFilter(rd, name, params[i]); err != nil {
+			return nil, err
+		}
+	}
+	return io.ReadAll(img.strm.r.limitStream(rd))
+}
+
+// Decode decodes the image. DCTDecode images are decoded as JPEG; other
+// image codecs are not supported. Images in a gray, RGB, CMYK or indexed
+// color space are converted from their samples, and image masks become
+// gray images with the painted samples black. Images whose samples or
+// decoded pixels would take more than the Reader's Limits.MaxStreamBytes
+// fail with a *LimitError.
+func (img Image) Decode() (_ image.Image, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error(fmt.Sprint(r))
+			err = recoveredError(r)
+		}
+	}()
+	img.strm, img.cs = img.strm.raising(), img.cs.raising()
+
+	if imageCodecs[img.Filter] && img.Filter != "DCTDecode" {
+		return nil, fmt.Errorf("%w: image filter %s", ErrUnsupportedFilter, img.Filter)
+	}
+	var maxBytes int64
+	if img.strm.r != nil {
+		maxBytes = img.strm.r.limits.MaxStreamBytes
+	}
+	if img.Filter == "DCTDecode" {
+		data, err := img.Data()
+		if err != nil {
+			return nil, err
+		}
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		// 4 bytes per pixel covers the largest JPEG image, CMYK
+		if _, err := imageSize(cfg.Width, cfg.Height, 1, 8, 4, maxBytes); err != nil {
+			return nil, err
+		}
+		return jpeg.Decode(bytes.NewReader(data))
+	}
+
+	w, h, bpc, comps := img.Width, img.Height, img.BitsPerComponent, img.Components
+	switch bpc {
+	case 1, 2, 4, 8, 16:
+	default:
+		return nil, fmt.Errorf("unsupported image bits per component %d", bpc)
+	}
+	// bytes per pixel of the decoded image
+	var pixel int
+	switch {
+	case img.ImageMask, img.ColorSpace == "Indexed":
+		pixel = 1
+	case comps == 1:
+		pixel = 2
+	case comps == 3:
+		pixel = 8
+	case comps == 4:
+		pixel = 4
+	default:
+		return nil, fmt.Errorf("unsupported image color space %s with %d components", img.ColorSpace, comps)
+	}
+	stride, err := imageSize(w, h, comps, bpc, pixel, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	data, err := img.Data()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < stride*h {
+		return nil, fmt.Errorf("image data short: %d of %d bytes", len(data), stride*h)
+	}
+	// raw returns component c of pixel x in row y; sample scales it to
+	// 16 bits.
+	maxVal := uint32(1)<<bpc - 1
+	raw := func(x, y, c int) uint32 {
+		row := data[y*stride:]
+		i := (x*comps + c) * bpc
+		switch bpc {
+		case 16:
+			return uint32(row[i/8])<<8 | uint32(row[i/8+1])
+		case 8:
+			return uint32(row[i/8])
+		}
+		return uint32(row[i/8]>>(8-bpc-i%8)) & maxVal
+	}
+	sample := func(x, y, c int) uint16 {
+		return uint16(raw(x, y, c) * 0xffff / maxVal)
+	}
+
+	rect := image.Rect(0, 0, w, h)
+	switch {
+	case img.ImageMask:
+		// samples of 0 are painted, unless /Decode is [1 0]
+		paint := uint16(0)
+		if d := img.strm.Key("Decode"); d.Len() == 2 && d.Index(0).Float64() == 1 {
+			paint = 0xffff
+		}
+		out := image.NewGray(rect)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				if sample(x, y, 0) != paint {
+					out.Pix[y*out.Stride+x] = 0xff
+				}
+			}
+		}
+		return out, nil
+
+	case img.ColorSpace == "Indexed":
+		pal, err := img.palette()
+		if err != nil {
+			return nil, err
+		}
+		out := image.NewPaletted(rect, pal)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				out.Pix[y*out.Stride+x] = uint8(min(int(raw(x, y, 0)), len(pal)-1))
+			}
+		}
+		return out, nil
+
+	case comps == 1:
+		out := image.NewGray16(rect)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				out.SetGray16(x, y, color.Gray16{Y: sample(x, y, 0)})
+			}
+		}
+		return out, nil
+
+	case comps == 3:
+		out := image.NewNRGBA64(rect)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				out.SetNRGBA64(x, y, color.NRGBA64{R: sample(x, y, 0), G: sample(x, y, 1), B: sample(x, y, 2), A: 0xffff})
+			}
+		}
+		return out, nil
+
+	case comps == 4:
+		out := image.NewCMYK(rect)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				out.SetCMYK(x, y, color.CMYK{
+					C: uint8(sample(x, y, 0) >> 8), M: uint8(sample(x, y, 1) >> 8),
+					Y: uint8(sample(x, y, 2) >> 8), K: uint8(sample(x, y, 3) >> 8),
+				})
+			}
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unsupported image color space %s with %d components", img.ColorSpace, comps)
+}
+
+// imageSize checks the dimensions of a w×h image of comps components of
+// bpc bits, decoded to pixel bytes per pixel, and returns the size of a
+// row of its samples. It fails if the samples or the decoded image would
+// not fit in an int, or take more than max bytes when max is positive.
+func imageSize(w, h, comps, bpc, pixel int, max int64) (stride int, err error) {
+	if w <= 0 || h <= 0 {
+		return 0, fmt.Errorf("bad image size %dx%d", w, h)
+	}
+	const maxInt = int(^uint(0) >> 1)
+	if w > maxInt/(comps*bpc) || w > maxInt/pixel {
+		return 0, fmt.Errorf("image too large: %dx%d", w, h)
+	}
+	stride = (w*comps*bpc + 7) / 8
+	if h > maxInt/stride || h > maxInt/(w*pixel) {
+		return 0, fmt.Errorf("image too large: %dx%d", w, h)
+	}
+	if max > 0 && (int64(stride*h) > max || int64(w*pixel*h) > max) {
+		return 0, limitError("MaxStreamBytes", max)
+	}
+	return stride, nil
+}
+
+// palette builds the color table of an Indexed color space,
+// [/Indexed base hival lookup], whose base is a gray, RGB or CMYK space.
+func (img Image) palette() (color.Palette, error) {
+	base := img.cs.Index(1)
+	n := colorComponents(base)
+	hival := int(img.cs.Index(2).Int64())
+	lookup := img.cs.Index(3)
+	table := []byte(lookup.RawString())
+	if lookup.Kind() == Stream {
+		var err error
+		if table, err = readStream(lookup); err != nil {
+			return nil, err
+		}
+	}
+	if n != 1 && n != 3 && n != 4 {
+		return nil, fmt.Errorf("unsupported Indexed base color space with %d components", n)
+	}
+	pal := make(color.Palette, 0, max(min(hival+1, 256), 0))
+	for i := 0; i <= hival && (i+1)*n <= len(table); i++ {
+		c := table[i*n : (i+1)*n]
+		switch n {
+		case 1:
+			pal = append(pal, color.Gray{Y: c[0]})
+		case 3:
+			pal = append(pal, color.RGBA{R: c[0], G: c[1], B: c[2], A: 0xff})
+		case 4:
+			pal = append(pal, color.CMYK{C: c[0], M: c[1], Y: c[2], K: c[3]})
+		}
+	}
+	if len(pal) == 0 {
+		return nil, errors.New("empty Indexed color table")
+	}
+	return pal, nil
+}
@@ -0,0 +1,183 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func imagesTestReader(t *testing.T, content string) (*Reader, []byte) {
+	t.Helper()
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write([]byte{255, 0, 0, 0, 0, 255}) // red, blue
+	zw.Close()
+
+	src := image.NewGray(image.Rect(0, 0, 8, 8))
+	for i := range src.Pix {
+		src.Pix[i] = 200
+	}
+	var jpg bytes.Buffer
+	require.NoError(t, jpeg.Encode(&jpg, src, nil))
+
+	data := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R " +
+			"/Resources << /Font << /F1 5 0 R >> /XObject << /Im1 6 0 R /Fm1 7 0 R >> /ColorSpace << /CS0 [/Indexed /DeviceRGB 1 <00ff00 0000ff>] >> >> >>",
+		streamObj("", []byte(content)),
+		layoutFont,
+		streamObj("/Type /XObject /Subtype /Image /Width 2 /Height 1 /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode", z.Bytes()),
+		streamObj("/Type /XObject /Subtype /Form /BBox [0 0 1 1] /Matrix [1 0 0 1 10 20] /Resources << /XObject << /Im2 8 0 R >> >>",
+			[]byte("q 30 0 0 40 0 0 cm /Im2 Do Q")),
+		streamObj("/Type /XObject /Subtype /Image /Width 8 /Height 8 /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /DCTDecode", jpg.Bytes()),
+	}, "")
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	return r, jpg.Bytes()
+}
+
+func TestImages(t *testing.T) {
+	content := strings.Join([]string{
+		"q 100 0 0 50 72 600 cm /Im1 Do Q",
+		"/Fm1 Do",
+		// 2x2 1-bit gray inline image, hex encoded
+		"q 20 0 0 20 300 300 cm BI /W 2 /H 2 /CS /G /BPC 1 /F /AHx ID 40 80> EI Q",
+		// indexed inline image using a named color space
+		"BI /W 2 /H 1 /CS /CS0 /BPC 8 ID \x01\x00 EI",
+		// image mask
+		"BI /IM true /W 3 /H 1 /D [1 0] ID \xa0 EI",
+		"BT /F1 10 Tf 72 700 Td (after images) Tj ET",
+	}, "\n")
+	r, jpg := imagesTestReader(t, content)
+	page := r.Page(1)
+	images, err := page.Images()
+	require.NoError(t, err)
+	require.Len(t, images, 5)
+
+	im := images[0]
+	assert.Equal(t, "Im1", im.Name)
+	assert.False(t, im.Inline)
+	assert.Equal(t, [6]float64{100, 0, 0, 50, 72, 600}, im.Matrix)
+	assert.Equal(t, Rect{Point{72, 600}, Point{172, 650}}, im.Rect)
+	assert.Equal(t, 2, im.Width)
+	assert.Equal(t, 1, im.Height)
+	assert.Equal(t, "DeviceRGB", im.ColorSpace)
+	assert.Equal(t, 3, im.Components)
+	assert.Equal(t, 8, im.BitsPerComponent)
+	assert.Equal(t, "FlateDecode", im.Filter)
+	data, err := im.Data()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{255, 0, 0, 0, 0, 255}, data)
+	dec, err := im.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA64{R: 0xffff, A: 0xffff}, dec.At(0, 0))
+	assert.Equal(t, color.NRGBA64{B: 0xffff, A: 0xffff}, dec.At(1, 0))
+
+	// inside a form, placed by the form matrix
+	im = images[1]
+	assert.Equal(t, "Im2", im.Name)
+	assert.Equal(t, Rect{Point{10, 20}, Point{40, 60}}, im.Rect)
+	assert.Equal(t, "DCTDecode", im.Filter)
+	data, err = im.Data()
+	require.NoError(t, err)
+	assert.Equal(t, jpg, data)
+	dec, err = im.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 8, 8), dec.Bounds())
+
+	im = images[2]
+	assert.True(t, im.Inline)
+	assert.Equal(t, "DeviceGray", im.ColorSpace)
+	assert.Equal(t, "ASCIIHexDecode", im.Filter)
+	assert.Equal(t, Rect{Point{300, 300}, Point{320, 320}}, im.Rect)
+	dec, err = im.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, color.Gray16{Y: 0}, dec.At(0, 0))
+	assert.Equal(t, color.Gray16{Y: 0xffff}, dec.At(1, 0))
+	assert.Equal(t, color.Gray16{Y: 0xffff}, dec.At(0, 1))
+
+	im = images[3]
+	assert.Equal(t, "Indexed", im.ColorSpace)
+	dec, err = im.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{B: 0xff, A: 0xff}, dec.At(0, 0))
+	assert.Equal(t, color.RGBA{G: 0xff, A: 0xff}, dec.At(1, 0))
+
+	im = images[4]
+	assert.True(t, im.ImageMask)
+	dec, err = im.Decode()
+	require.NoError(t, err)
+	// with /Decode [1 0], samples of 1 are painted
+	assert.Equal(t, color.Gray{Y: 0}, dec.At(0, 0))
+	assert.Equal(t, color.Gray{Y: 0xff}, dec.At(1, 0))
+	assert.Equal(t, color.Gray{Y: 0}, dec.At(2, 0))
+
+	// text after inline images is still extracted
+	text, err := page.GetPlainText(nil)
+	require.NoError(t, err)
+	assert.Contains(t, text, "after images")
+}
+
+func TestImage_DecodeHostile(t *testing.T) {
+	decode := func(limits Limits, inline string) error {
+		t.Helper()
+		r, err := openLimited(t, errorTestPDF("BI "+inline+" EI"), limits)
+		require.NoError(t, err)
+		images, err := r.Page(1).Images()
+		require.NoError(t, err)
+		require.Len(t, images, 1)
+		_, err = images[0].Decode()
+		return err
+	}
+	for _, inline := range []string{
+		"/W 4000000000 /H 4000000000 /CS /G /BPC 16 ID \x00\x00",
+		"/W 9223372036854775807 /H 2 /CS /RGB /BPC 16 ID \x00\x00",
+		"/W 1000 /H 1000 /CS /G /BPC 8 ID \x00\x00",
+		"/W 1 /H 1 /CS [/I /RGB -5 <ff0000>] /BPC 8 ID \x00",
+		"/W 1 /H 1 /CS /Unknown /BPC 8 ID \x00",
+		"/W -1 /H 1 /CS /G /BPC 8 ID \x00",
+	} {
+		assert.Error(t, decode(Limits{}, inline), inline)
+	}
+
+	// the length is not trusted to size the data
+	assert.NoError(t, decode(Limits{}, "/W 2 /H 1 /CS /G /BPC 8 /L 9223372036854775807 ID \x00\x00"))
+
+	inline := "/W 2000 /H 2000 /CS /G /BPC 1 ID \x00"
+	assertLimit(t, decode(Limits{MaxStreamBytes: 1 << 20}, inline), "MaxStreamBytes")
+	assert.NotErrorIs(t, decode(Limits{}, inline), ErrLimitExceeded)
+}
+
+func TestReadInlineImage(t *testing.T) {
+	for _, tc := range []struct {
+		name, src string
+		want      []byte
+	}{
+		{"binary data with delimiters", "/W 4 /H 1 /BPC 8 /CS /G ID ()<\x00 EI", []byte("()<\x00")},
+		{"EI inside the data", "/W 4 /H 1 /BPC 8 /CS /G ID aEIb EI", []byte("aEIb")},
+		{"EI not followed by white space", "/W 4 /H 1 /BPC 8 /CS /G ID a EIb EI", []byte("a EIb")},
+		{"explicit length", "/W 4 /H 1 /BPC 8 /CS /G /L 4 ID a EI EI", []byte("a EI")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newBuffer(strings.NewReader(tc.src+" Q"), 0)
+			b.allowEOF = true
+			b.allowObjptr = false
+			b.allowStream = false
+			img := b.readInlineImage()
+			assert.Equal(t, tc.want, img.data)
+			assert.Equal(t, name("DeviceGray"), img.hdr["ColorSpace"])
+			assert.Equal(t, keyword("Q"), b.readToken())
+		})
+	}
+}
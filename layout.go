@@ -0,0 +1,233 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+// Layout heuristics, as fractions of the font size unless noted otherwise.
+const (
+	// baselineTolerance is how far apart two baselines may be and still
+	// belong to the same line (covers superscripts and mixed sizes).
+	baselineTolerance = 0.5
+	// wordGap is the horizontal gap between two glyphs above which they
+	// are treated as separate words.
+	wordGap = 0.2
+	// missingWidth is the advance assumed for glyphs whose font has no
+	// width information, and missingWidthGap the word gap used after them
+	// to allow for the error in that guess.
+	missingWidth    = 0.5
+	missingWidthGap = 0.6
+	// paragraphGap is the multiple of the page's typical line spacing
+	// above which a blank line is inserted between two lines.
+	paragraphGap = 1.5
+)
+
+// layoutGlyph is a glyph placed by Page.content together with the distance
+// by which the text position advanced after it.
+type layoutGlyph struct {
+	Text
+	adv float64
+}
+
+// size returns the glyph's font size, falling back to a nominal size for
+// rotated or degenerate text matrices.
+func (g layoutGlyph) size() float64 {
+	if s := math.Abs(g.FontSize); s > 0 {
+		return s
+	}
+	return 10
+}
+
+// center returns the middle of the glyph's box, estimating its width and
+// height from the font size where needed.
+func (g layoutGlyph) center() (float64, float64) {
+	return g.X + math.Max(g.W, missingWidth*g.size())/2, g.Y + 0.3*g.size()
+}
+
+// layoutLine is a set of glyphs sharing a baseline, sorted left to right.
+type layoutLine struct {
+	y      float64 // baseline of the first glyph placed on the line
+	size   float64 // largest font size on the line
+	glyphs []layoutGlyph
+}
+
+// GetLayoutText returns the page's text in reading order. Unlike
+// GetPlainText, which follows the order of the content stream, it
+// rebuilds words from the gaps between positioned glyphs, lines from
+// glyphs sharing a baseline (top to bottom, left to right) and separates
+// paragraphs with a blank line. Text in vertical writing mode follows,
+// its lines read top to bottom and ordered right to left.
+func (p Page) GetLayoutText() (result string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = ""
+			logger.Error(fmt.Sprint(r))
+			err = recoveredError(r)
+		}
+	}()
+	p = p.raising()
+
+	if p.V.IsNull() || p.V.Key("Contents").Kind() == Null {
+		return "", nil
+	}
+	horizontal, vertical := splitVertical(p.layoutGlyphs())
+	lines := buildLines(horizontal)
+	vlines := buildLines(vertical)
+	logger.Debug(fmt.Sprintf("GetLayoutText: %d lines, %d vertical lines for Page %d %d R", len(lines), len(vlines), p.V.ptr.id, p.V.ptr.gen), true)
+	return joinLines(lines) + joinLines(vlines), nil
+}
+
+// splitVertical separates the glyphs of vertical writing mode from the
+// others and turns them a quarter turn, so that buildLines groups them
+// into lines by their center x, orders the lines right to left and the
+// glyphs in each line top to bottom.
+func splitVertical(glyphs []layoutGlyph) (horizontal, vertical []layoutGlyph) {
+	for _, g := range glyphs {
+		if !g.Vertical {
+			horizontal = append(horizontal, g)
+			continue
+		}
+		x, _ := g.center()
+		g.X, g.Y = -(g.Y + g.size()), x
+		g.W = g.adv
+		vertical = append(vertical, g)
+	}
+	return horizontal, vertical
+}
+
+// layoutGlyphs returns the glyphs shown on the page in content order.
+func (p Page) layoutGlyphs() []layoutGlyph {
+	var glyphs []layoutGlyph
+	p.content(func(t Text, adv float64) {
+		glyphs = append(glyphs, layoutGlyph{t, adv})
+	}, nil)
+	return glyphs
+}
+
+// buildLines clusters glyphs into lines by baseline and returns the lines
+// from the top of the page down. Glyphs within a line keep their content
+// order when they share an x position.
+func buildLines(glyphs []layoutGlyph) []*layoutLine {
+	var lines []*layoutLine
+	for _, g := range glyphs {
+		if g.S == "" || (g.S != " " && strings.TrimSpace(g.S) == "") {
+			continue
+		}
+		var line *layoutLine
+		for i := len(lines) - 1; i >= 0; i-- {
+			l := lines[i]
+			if math.Abs(l.y-g.Y) <= baselineTolerance*math.Max(l.size, g.size()) {
+				line = l
+				break
+			}
+		}
+		if line == nil {
+			line = &layoutLine{y: g.Y}
+			lines = append(lines, line)
+		}
+		line.size = math.Max(line.size, g.size())
+		line.glyphs = append(line.glyphs, g)
+	}
+	// drop lines holding nothing but space glyphs
+	kept := lines[:0]
+	for _, l := range lines {
+		for _, g := range l.glyphs {
+			if g.S != " " {
+				sort.SliceStable(l.glyphs, func(i, j int) bool { return l.glyphs[i].X < l.glyphs[j].X })
+				kept = append(kept, l)
+				break
+			}
+		}
+	}
+	lines = kept
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].y > lines[j].y })
+	return lines
+}
+
+// text returns the line's glyphs joined into words. A space is inserted
+// where the font shows a space glyph or where the gap to the previous glyph
+// is wider than wordGap. Overprinted duplicates (fake bold) are dropped;
+// this needs glyph widths, as glyphs of fonts without them share an x.
+func (l *layoutLine) text() string {
+	var sb strings.Builder
+	var prev *layoutGlyph
+	end := math.Inf(-1)
+	space := false
+	for i := range l.glyphs {
+		g := &l.glyphs[i]
+		size := g.size()
+		if g.S == " " {
+			space = sb.Len() > 0
+			end = g.advanceFrom(end)
+			continue
+		}
+		if prev != nil && prev.S == g.S && g.W > 0 && math.Abs(prev.X-g.X) < wordGap*size {
+			continue
+		}
+		gap := wordGap * size
+		if prev != nil && prev.W <= 0 {
+			gap = missingWidthGap * size
+		}
+		if sb.Len() > 0 && (space || g.X-end > gap) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(g.S)
+		space = false
+		end = g.advanceFrom(end)
+		prev = g
+	}
+	return sb.String()
+}
+
+// advanceFrom returns the x position at which the next glyph is expected,
+// given the expected position end before g. Glyphs from fonts without
+// widths do not move the text position, so their advance is estimated.
+func (g layoutGlyph) advanceFrom(end float64) float64 {
+	if g.W > 0 {
+		return g.X + g.adv
+	}
+	return math.Max(end, g.X) + missingWidth*g.size() + g.adv
+}
+
+// joinLines joins lines with newlines, inserting a blank line wherever the
+// baseline gap is clearly larger than the typical line spacing.
+func joinLines(lines []*layoutLine) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	var gaps []float64
+	for i := 1; i < len(lines); i++ {
+		gaps = append(gaps, lines[i-1].y-lines[i].y)
+	}
+	var lead float64
+	if len(gaps) > 1 {
+		sorted := append([]float64(nil), gaps...)
+		sort.Float64s(sorted)
+		lead = sorted[len(sorted)/2]
+	}
+
+	var sb strings.Builder
+	for i, l := range lines {
+		if i > 0 {
+			sb.WriteByte('\n')
+			limit := paragraphGap * lead
+			if lead == 0 {
+				limit = 2 * l.size
+			}
+			if gaps[i-1] > limit {
+				sb.WriteByte('\n')
+			}
+		}
+		sb.WriteString(l.text())
+	}
+	sb.WriteByte('\n')
+	return sb.String()
+}
@@ -0,0 +1,130 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// layoutFont is a simple font in which every glyph is 500 units wide.
+var layoutFont = "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /FirstChar 32 /LastChar 126 /Widths [" +
+	strings.TrimSpace(strings.Repeat("500 ", 126-32+1)) + "] >>"
+
+// layoutTestPDF returns a one-page document showing content with the
+// given font resource as /F1.
+func layoutTestPDF(font, content string) []byte {
+	return buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		streamObj("", []byte(content)),
+		font,
+	}, "")
+}
+
+func layoutText(t *testing.T, font, content string) string {
+	t.Helper()
+	data := layoutTestPDF(font, content)
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	text, err := r.Page(1).GetLayoutText()
+	require.NoError(t, err)
+	return text
+}
+
+func TestGetLayoutText(t *testing.T) {
+	// Lines are drawn bottom-up and each word is positioned separately,
+	// without space characters between them.
+	content := strings.Join([]string{
+		"BT /F1 10 Tf 1 0 0 1 72 600 Tm (Thank) Tj 1 0 0 1 105 600 Tm (you) Tj ET",
+		"BT /F1 10 Tf 1 0 0 1 72 668 Tm (Due) Tj 1 0 0 1 100 668 Tm (today) Tj ET",
+		"BT /F1 10 Tf 1 0 0 1 72 680 Tm (Total) Tj 1 0 0 1 110 680 Tm (Amount) Tj ET",
+		"BT /F1 10 Tf 1 0 0 1 72 692 Tm [(Inv) -20 (oice)] TJ ET",
+		"BT /F1 10 Tf 1 0 0 1 300 692 Tm (No.) Tj ET",
+	}, "\n")
+	text := layoutText(t, layoutFont, content)
+	assert.Equal(t, "Invoice No.\nTotal Amount\nDue today\n\nThank you\n", text)
+
+	plain := func() string {
+		data := layoutTestPDF(layoutFont, content)
+		r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+		require.NoError(t, err)
+		s, err := r.Page(1).GetPlainText(nil)
+		require.NoError(t, err)
+		return s
+	}()
+	assert.Contains(t, plain, "TotalAmount", "plain mode keeps positioned runs together")
+}
+
+func TestGetLayoutText_Spacing(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"space glyph", "BT /F1 10 Tf 72 700 Td (two words) Tj ET", "two words\n"},
+		{"character spacing", "BT /F1 10 Tf 3 Tc 72 700 Td (spaced) Tj ET", "spaced\n"},
+		{"word spacing", "BT /F1 10 Tf 20 Tw 72 700 Td (wide gap) Tj ET", "wide gap\n"},
+		{"superscript", "BT /F1 10 Tf 72 700 Td (E=mc) Tj 3 Ts (2) Tj ET", "E=mc2\n"},
+		{"overprinted bold", "BT /F1 10 Tf 1 0 0 1 72 700 Tm (B) Tj 1 0 0 1 72.5 700 Tm (B) Tj ET", "B\n"},
+		{"empty page", "q Q", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, layoutText(t, layoutFont, tc.content))
+		})
+	}
+}
+
+func TestGetLayoutText_NoWidths(t *testing.T) {
+	// Without /Widths glyphs do not advance, so word gaps are estimated.
+	font := "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"
+	content := "BT /F1 10 Tf 1 0 0 1 72 700 Tm (Hello) Tj 1 0 0 1 200 700 Tm (World) Tj ET"
+	assert.Equal(t, "Hello World\n", layoutText(t, font, content))
+}
+
+func TestGetLayoutText_Vertical(t *testing.T) {
+	// two columns of vertical text, the left one drawn first, under a
+	// horizontal page header
+	font := type0Font("/Identity-V", "Japan1")
+	content := strings.Join([]string{
+		"BT /F1 10 Tf 1 0 0 1 185 700 Tm <00220023> Tj ET",
+		"BT /F1 10 Tf 1 0 0 1 200 700 Tm <0465034A> Tj [<0022> 500 <0023>] TJ ET",
+	}, "\n")
+	assert.Equal(t, "亜ぁA B\nAB\n", layoutText(t, font, content))
+
+	data := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> >>",
+		streamObj("", []byte(content+"\nBT /F2 10 Tf 72 750 Td (Header) Tj ET")),
+		font,
+		layoutFont,
+	}, "")
+	text, err := newTestReader(t, data).Page(1).GetLayoutText()
+	require.NoError(t, err)
+	assert.Equal(t, "Header\n亜ぁA B\nAB\n", text)
+}
+
+func TestProcessor_Extract_LayoutMode(t *testing.T) {
+	data := layoutTestPDF(layoutFont,
+		"BT /F1 10 Tf 1 0 0 1 72 680 Tm (Total) Tj 1 0 0 1 110 680 Tm (Amount) Tj ET")
+	path, cleanup := writeTempFile(t, string(data))
+	defer cleanup()
+
+	cfg := NewDefaultConfig()
+	cfg.TextMode = LayoutText
+	text, _, err := NewProcessor(cfg).Extract(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, "Total Amount\n", text)
+
+	cfg.TextMode = "columns-of-doom"
+	assert.Error(t, cfg.Validate())
+}
@@ -6,7 +6,6 @@
 package xtract
 
 import (
-	"fmt"
 	"io"
 	"strconv"
 )
@@ -42,7 +41,7 @@ type buffer struct {
 	allowStream bool
 	eof         bool
 	key         []byte
-	useAES      bool
+	method      string // crypt filter method for strings
 	objptr      objptr
 }
 
@@ -69,8 +68,10 @@ func (b *buffer) readByte() byte {
 	return c
 }
 
+// errorf aborts parsing with an ObjectError for the object being read. The
+// panic is recovered and returned as an error by the exported entry points.
 func (b *buffer) errorf(format string, args ...interface{}) {
-	panic(fmt.Errorf(format, args...))
+	panic(objectError(b.objptr, b.readOffset(), format, args...))
 }
 
 func (b *buffer) reload() bool {
@@ -83,7 +84,10 @@ func (b *buffer) reload() bool {
 			b.eof = true
 			return false
 		}
-		b.errorf("malformed PDF: reading at offset %d: %v", b.offset, err)
+		if isAbort(err) {
+			panic(err)
+		}
+		b.errorf("reading at offset %d: %w", b.offset, err)
 		return false
 	}
 	b.offset += int64(n)
@@ -197,7 +201,7 @@ func (b *buffer) readHexString() token {
 		tmp = append(tmp, byte(x))
 	}
 	b.tmp = tmp
-	return string(tmp)
+	return b.decryptString(string(tmp))
 }
 
 func unhex(b byte) int {
@@ -271,7 +275,17 @@ Loop:
 		}
 	}
 	b.tmp = tmp
-	return string(tmp)
+	return b.decryptString(string(tmp))
+}
+
+// decryptString decrypts s if the buffer is reading an indirect object
+// of an encrypted document. Strings outside of an object definition,
+// such as those of the trailer, are never encrypted.
+func (b *buffer) decryptString(s string) string {
+	if b.key == nil || b.objptr == (objptr{}) {
+		return s
+	}
+	return decryptString(b.key, b.method, b.objptr, s)
 }
 
 func (b *buffer) readName() token {
@@ -388,6 +402,7 @@ type stream struct {
 	hdr    dict
 	ptr    objptr
 	offset int64
+	data   []byte // data of an inline image; nil for streams stored in the file
 }
 
 type objptr struct {
@@ -503,7 +518,96 @@ func (b *buffer) readDict() object {
 		b.errorf("stream keyword not followed by newline")
 	}
 
-	return stream{x, b.objptr, b.readOffset()}
+	return stream{x, b.objptr, b.readOffset(), nil}
+}
+
+// Abbreviations used in inline image dictionaries.
+var (
+	inlineImageKeys = map[name]name{
+		"BPC": "BitsPerComponent", "CS": "ColorSpace", "D": "Decode", "DP": "DecodeParms",
+		"F": "Filter", "H": "Height", "IM": "ImageMask", "I": "Interpolate", "L": "Length", "W": "Width",
+	}
+	inlineImageNames = map[name]name{
+		"G": "DeviceGray", "RGB": "DeviceRGB", "CMYK": "DeviceCMYK", "I": "Indexed",
+		"AHx": "ASCIIHexDecode", "A85": "ASCII85Decode", "LZW": "LZWDecode", "Fl": "FlateDecode",
+		"RL": "RunLengthDecode", "CCF": "CCITTFaxDecode", "DCT": "DCTDecode",
+	}
+)
+
+// readInlineImage reads an inline image after the BI operator: the image
+// dictionary up to ID, then the image data up to EI. Abbreviated keys,
+// color spaces and filters are expanded, so that the image can be read
+// like an image XObject.
+func (b *buffer) readInlineImage() stream {
+	hdr := make(dict)
+	for {
+		tok := b.readToken()
+		if tok == keyword("ID") || tok == io.EOF {
+			break
+		}
+		n, ok := tok.(name)
+		if !ok {
+			b.errorf("unexpected non-name key %T(%v) in inline image", tok, tok)
+			continue
+		}
+		if full, ok := inlineImageKeys[n]; ok {
+			n = full
+		}
+		v := b.readObject()
+		if n == "ColorSpace" || n == "Filter" {
+			v = expandInlineNames(v)
+		}
+		hdr[n] = v
+	}
+
+	// ID is followed by a single white-space character.
+	b.readByte()
+	data := []byte{}
+	if n, ok := hdr["Length"].(int64); ok && n >= 0 {
+		// /L is not trusted to size the buffer
+		data = make([]byte, 0, min(n, 4096))
+		for int64(len(data)) < n && !b.eof {
+			data = append(data, b.readByte())
+		}
+		if tok := b.readToken(); tok != keyword("EI") {
+			b.unreadToken(tok)
+		}
+	} else {
+		// The data ends at the first EI set off by white space.
+		for {
+			c := b.readByte()
+			if b.eof {
+				break
+			}
+			data = append(data, c)
+			n := len(data)
+			if n >= 3 && data[n-1] == 'I' && data[n-2] == 'E' && isSpace(data[n-3]) {
+				next := b.readByte()
+				if b.eof || isSpace(next) || isDelim(next) {
+					b.unreadByte()
+					data = data[:n-3]
+					break
+				}
+				b.unreadByte()
+			}
+		}
+	}
+	hdr["Length"] = int64(len(data))
+	return stream{hdr: hdr, data: data}
+}
+
+func expandInlineNames(v object) object {
+	switch x := v.(type) {
+	case name:
+		if full, ok := inlineImageNames[x]; ok {
+			return full
+		}
+	case array:
+		for i := range x {
+			x[i] = expandInlineNames(x[i])
+		}
+	}
+	return v
 }
 
 func isSpace(b byte) bool {
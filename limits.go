@@ -0,0 +1,144 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+// defaultMaxDepth is the nesting depth allowed when Limits.MaxDepth is 0.
+// Well-formed documents stay far below it; deeper nesting is found in
+// cyclic page trees and /Parent or /Extends chains.
+const defaultMaxDepth = 256
+
+// Limits bounds the resources a single document may use, so that a crafted
+// file (a compression bomb, a huge page tree, an endless content stream)
+// cannot exhaust the memory or time of the process reading it. A document
+// exceeding a limit fails with a *LimitError. A zero field means no limit,
+// except for MaxDepth. The PDFs the processor extracts from attachments
+// count against the limits of the document they are attached to.
+type Limits struct {
+	// MaxStreamBytes limits the decoded size of any one stream.
+	MaxStreamBytes int64 `validate:"min=0"`
+	// MaxDocumentBytes limits the decoded size of all the streams read
+	// from the document, counting a stream each time it is read.
+	MaxDocumentBytes int64 `validate:"min=0"`
+	// MaxObjects limits the number of indirect objects loaded from the
+	// file, counting an object each time it is resolved.
+	MaxObjects int64 `validate:"min=0"`
+	// MaxOperators limits the number of content stream operators
+	// interpreted for one page, including those of the forms it paints.
+	MaxOperators int64 `validate:"min=0"`
+	// MaxDepth limits the depth of the page tree, of /Parent and object
+	// stream /Extends chains, and of nested Form XObjects. Zero means
+	// defaultMaxDepth (256).
+	MaxDepth int `validate:"min=0"`
+	// MaxPages limits the number of pages of the document.
+	MaxPages int `validate:"min=0"`
+}
+
+// DefaultLimits are the limits of NewDefaultConfig, generous enough for
+// any legitimate document.
+var DefaultLimits = Limits{
+	MaxStreamBytes:   256 << 20,
+	MaxDocumentBytes: 16 << 30,
+	MaxObjects:       50_000_000,
+	MaxOperators:     10_000_000,
+	MaxDepth:         defaultMaxDepth,
+	MaxPages:         100_000,
+}
+
+// A LimitError reports that a document exceeded one of its Limits. It
+// wraps ErrLimitExceeded.
+type LimitError struct {
+	Limit string // the Limits field, such as "MaxStreamBytes"
+	Max   int64  // its value
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v: %s %d", ErrLimitExceeded, e.Limit, e.Max)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+func limitError(limit string, max int64) *LimitError {
+	logger.Error(fmt.Sprintf("limit exceeded: %s %d", limit, max))
+	return &LimitError{Limit: limit, Max: max}
+}
+
+// usage counts the resources used by a document. It is shared by the
+// copies of a Reader, which may read pages concurrently.
+type usage struct {
+	bytes   atomic.Int64
+	objects atomic.Int64
+}
+
+// maxDepth returns the depth limit in effect.
+func (l Limits) maxDepth() int {
+	if l.MaxDepth > 0 {
+		return l.MaxDepth
+	}
+	return defaultMaxDepth
+}
+
+// checkDepth aborts with a LimitError once depth exceeds the depth limit.
+func (r *Reader) checkDepth(depth int) {
+	var l Limits
+	if r != nil {
+		l = r.limits
+	}
+	if depth > l.maxDepth() {
+		panic(limitError("MaxDepth", int64(l.maxDepth())))
+	}
+}
+
+// countObject records that an indirect object was loaded, aborting with a
+// LimitError once Limits.MaxObjects is exceeded.
+func (r *Reader) countObject() {
+	if r.limits.MaxObjects > 0 && r.usage.objects.Add(1) > r.limits.MaxObjects {
+		panic(limitError("MaxObjects", r.limits.MaxObjects))
+	}
+}
+
+// limitStream wraps the decoded data of a stream so that reads fail with a
+// LimitError once Limits.MaxStreamBytes or Limits.MaxDocumentBytes is
+// exceeded.
+func (r *Reader) limitStream(rd io.Reader) io.Reader {
+	if r == nil || r.limits.MaxStreamBytes <= 0 && r.limits.MaxDocumentBytes <= 0 {
+		return rd
+	}
+	return &limitedReader{r: rd, limits: r.limits, usage: r.usage}
+}
+
+// limitedReader counts the bytes read from a decoded stream.
+type limitedReader struct {
+	r      io.Reader
+	n      int64
+	limits Limits
+	usage  *usage
+	err    error
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	switch {
+	case l.limits.MaxStreamBytes > 0 && l.n > l.limits.MaxStreamBytes:
+		l.err = limitError("MaxStreamBytes", l.limits.MaxStreamBytes)
+	case l.limits.MaxDocumentBytes > 0 && l.usage.bytes.Add(int64(n)) > l.limits.MaxDocumentBytes:
+		l.err = limitError("MaxDocumentBytes", l.limits.MaxDocumentBytes)
+	default:
+		return n, err
+	}
+	return 0, l.err
+}
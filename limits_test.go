@@ -0,0 +1,165 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openLimited opens data with the given limits.
+func openLimited(t *testing.T, data []byte, limits Limits) (*Reader, error) {
+	t.Helper()
+	return NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{Limits: limits})
+}
+
+// assertLimit asserts that err is a LimitError for the named limit.
+func assertLimit(t *testing.T, err error, limit string) {
+	t.Helper()
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+	var le *LimitError
+	if assert.True(t, errors.As(err, &le), "got %v", err) {
+		assert.Equal(t, limit, le.Limit)
+	}
+}
+
+// flateBomb returns a page whose content stream inflates to n bytes.
+func flateBomb(n int) []byte {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write([]byte("BT (boom) Tj ET "))
+	zw.Write(bytes.Repeat([]byte(" "), n))
+	zw.Close()
+	return buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R >>",
+		streamObj("/Filter /FlateDecode", buf.Bytes()),
+	}, "")
+}
+
+func TestLimits_StreamBytes(t *testing.T) {
+	data := flateBomb(1 << 20)
+	r, err := openLimited(t, data, Limits{MaxStreamBytes: 64 << 10})
+	require.NoError(t, err)
+	_, err = r.Page(1).GetPlainText(nil)
+	assertLimit(t, err, "MaxStreamBytes")
+
+	r, err = openLimited(t, data, Limits{MaxStreamBytes: 2 << 20})
+	require.NoError(t, err)
+	text, err := r.Page(1).GetPlainText(nil)
+	require.NoError(t, err)
+	assert.Contains(t, text, "boom")
+}
+
+func TestLimits_DocumentBytes(t *testing.T) {
+	content := "BT /F1 12 Tf 72 700 Td (first) Tj ET" + strings.Repeat(" ", 1000)
+	r, err := openLimited(t, errorTestPDF(content), Limits{MaxDocumentBytes: 1500})
+	require.NoError(t, err)
+	_, err = r.Page(1).GetPlainText(nil)
+	require.NoError(t, err)
+	_, err = r.Page(1).GetPlainText(nil)
+	assertLimit(t, err, "MaxDocumentBytes")
+}
+
+func TestLimits_Objects(t *testing.T) {
+	data := errorTestPDF("BT /F1 12 Tf 72 700 Td (hello) Tj ET")
+	r, err := openLimited(t, data, Limits{MaxObjects: 5})
+	require.NoError(t, err)
+	_, err = r.Page(1).GetPlainText(nil)
+	assertLimit(t, err, "MaxObjects")
+}
+
+func TestLimits_Operators(t *testing.T) {
+	content := "BT /F1 12 Tf 72 700 Td (hello) Tj ET " + strings.Repeat("q Q ", 100)
+	r, err := openLimited(t, errorTestPDF(content), Limits{MaxOperators: 100})
+	require.NoError(t, err)
+	_, err = r.Page(1).GetLayoutText()
+	assertLimit(t, err, "MaxOperators")
+
+	r, err = openLimited(t, errorTestPDF(content), Limits{MaxOperators: 1000})
+	require.NoError(t, err)
+	text, err := r.Page(1).GetLayoutText()
+	require.NoError(t, err)
+	assert.Contains(t, text, "hello")
+}
+
+func TestLimits_Depth(t *testing.T) {
+	// a page tree node that lists itself as its kid
+	cyclic := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [2 0 R] /Count 5 >>",
+	}, "")
+	r, err := openLimited(t, cyclic, Limits{MaxDepth: 10})
+	require.NoError(t, err)
+	_, err = r.page(1)
+	assertLimit(t, err, "MaxDepth")
+	assert.True(t, r.Page(1).V.IsNull())
+
+	// a page whose /Parent chain loops, so that inherited resources are
+	// never found
+	loop := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /Parent 2 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		streamObj("", []byte("BT /F1 12 Tf (hello) Tj ET")),
+	}, "")
+	r, err = openLimited(t, loop, Limits{})
+	require.NoError(t, err)
+	_, err = r.Page(1).GetPlainText(nil)
+	assertLimit(t, err, "MaxDepth")
+
+	// forms nested five deep, each painting the next
+	objs := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /XObject << /Fm 5 0 R >> >> >>",
+		streamObj("", []byte("/Fm Do")),
+	}
+	for i := 5; i < 10; i++ {
+		objs = append(objs, streamObj(fmt.Sprintf("/Subtype /Form /Resources << /XObject << /Fm %d 0 R >> >>", i+1), []byte("/Fm Do")))
+	}
+	nested := buildPDF(objs, "")
+	r, err = openLimited(t, nested, Limits{MaxDepth: 3})
+	require.NoError(t, err)
+	_, err = r.Page(1).GetPlainText(nil)
+	assertLimit(t, err, "MaxDepth")
+	r, err = openLimited(t, nested, Limits{MaxDepth: 5})
+	require.NoError(t, err)
+	_, err = r.Page(1).GetPlainText(nil)
+	assert.NoError(t, err)
+}
+
+func TestLimits_Pages(t *testing.T) {
+	data := buildPDF(recoverTestObjs(), "")
+	_, err := openLimited(t, data, Limits{MaxPages: 1})
+	assertLimit(t, err, "MaxPages")
+
+	_, err = openLimited(t, data, Limits{MaxPages: 2})
+	assert.NoError(t, err)
+}
+
+func TestProcessor_Extract_Limits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bomb.pdf")
+	require.NoError(t, os.WriteFile(path, flateBomb(1<<20), 0o600))
+
+	cfg := NewDefaultConfig()
+	text, _, err := NewProcessor(cfg).Extract(context.Background(), path)
+	require.NoError(t, err)
+	assert.Contains(t, text, "boom")
+
+	cfg.Limits.MaxStreamBytes = 64 << 10
+	_, _, err = NewProcessor(cfg).Extract(context.Background(), path)
+	assertLimit(t, err, "MaxStreamBytes")
+}
@@ -6,6 +6,7 @@ package xtract
 import (
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
 	"io"
 	"strings"
 
@@ -284,7 +285,15 @@ func stripXMLTags(s string) string {
 }
 
 // Metadata returns unified metadata with XMP taking precedence over /Info.
-func (r *Reader) Metadata() (Meta, error) {
+func (r *Reader) Metadata() (meta Meta, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			logger.Error(fmt.Sprint(e))
+			meta, err = Meta{}, recoveredError(e)
+		}
+	}()
+	r = r.raising()
+
 	info := r.readInfo()
 
 	xmpXML, err := r.readXMP()
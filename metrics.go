@@ -0,0 +1,368 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// fontMetrics splits the strings shown with a font into character codes
+// and gives the width of each, for positioning text.
+type fontMetrics struct {
+	simple [256]float64 // glyph widths of a simple font by code
+	cmap   *cidCMap     // Type0 fonts: codes to CIDs
+	cids   *cidWidths   // Type0 fonts: glyph widths by CID
+	scale  float64      // glyph space to text space
+	// vertical is set for Type0 fonts in vertical writing mode, whose
+	// glyphs advance down the page by their /W2 displacement.
+	vertical bool
+}
+
+// metrics returns the metrics of the font. enc is the font's encoder,
+// used to name the glyphs of a standard font without /Widths.
+func (f Font) metrics(enc TextEncoding) *fontMetrics {
+	m := &fontMetrics{scale: 0.001}
+	// the glyph space of a Type3 font is given by its FontMatrix
+	if fm := f.V.Key("FontMatrix"); f.V.Key("Subtype").Name() == "Type3" && fm.Index(0).Float64() != 0 {
+		m.scale = fm.Index(0).Float64()
+	}
+	if f.V.Key("Subtype").Name() == "Type0" {
+		m.cmap = f.cidCodes()
+		m.cids = newCIDWidths(f.V.Key("DescendantFonts").Index(0))
+		m.vertical = f.vertical()
+		return m
+	}
+	if widths := f.V.Key("Widths"); widths.Kind() == Array {
+		first := int(f.V.Key("FirstChar").Int64())
+		missing := f.V.Key("FontDescriptor").Key("MissingWidth").Float64()
+		for c := range m.simple {
+			m.simple[c] = missing
+			if i := c - first; i >= 0 && i < widths.Len() {
+				m.simple[c] = widths.Index(i).Float64()
+			}
+		}
+	} else if afm := standardFontWidths(f.BaseFont()); afm != nil {
+		for c := range m.simple {
+			r, _ := utf8.DecodeRuneInString(enc.Decode(string([]byte{byte(c)})))
+			m.simple[c] = afm.width(r)
+		}
+	}
+	return m
+}
+
+// next returns the character code at the start of raw.
+func (m *fontMetrics) next(raw string) string {
+	if m.cmap != nil {
+		code, _ := m.cmap.nextCode(raw)
+		return code
+	}
+	return raw[:1]
+}
+
+// width returns the horizontal displacement of the glyph of code, in text
+// space units for a font size of 1.
+func (m *fontMetrics) width(code string) float64 {
+	if m.cmap != nil {
+		return m.cids.width(m.cmap.lookup(code)) * m.scale
+	}
+	return m.simple[code[0]] * m.scale
+}
+
+// verticalMetrics returns the vertical displacement of the glyph of code
+// and its position vector, in text space units for a font size of 1.
+func (m *fontMetrics) verticalMetrics(code string) (w1y, vx, vy float64) {
+	w1y, vx, vy = m.cids.vertical(m.cmap.lookup(code))
+	return w1y * m.scale, vx * m.scale, vy * m.scale
+}
+
+// vertical reports whether a Type0 font is in vertical writing mode: its
+// CMap is one of the predefined -V CMaps or has /WMode 1.
+func (f Font) vertical() bool {
+	enc := f.V.Key("Encoding")
+	if enc.Kind() == Stream {
+		return enc.Key("WMode").Int64() == 1
+	}
+	name := enc.Name()
+	return name == "V" || strings.HasSuffix(name, "-V")
+}
+
+// cidCodes returns the CMap that splits the strings of a Type0 font into
+// codes and maps them to CIDs. The CIDs of predefined CMaps other than
+// Identity-H/V are unknown; their glyphs get the default width.
+func (f Font) cidCodes() *cidCMap {
+	enc := f.V.Key("Encoding")
+	switch enc.Kind() {
+	case Stream:
+		if m := readCIDCmap(enc); m != nil {
+			return m
+		}
+	case Name:
+		if e, ok := predefinedCMap(enc.Name()).(interface{ codeLen(string) int }); ok {
+			return &cidCMap{codeLen: e.codeLen}
+		}
+	}
+	return &cidCMap{identity: true}
+}
+
+// A cidWidthRange gives the metrics of the CIDs first through last: one
+// set of values for each CID, or a single set for all of them.
+type cidWidthRange struct {
+	first, last int
+	values      []float64
+	constant    bool
+}
+
+// cidWidths holds the horizontal (/W, /DW) and vertical (/W2, /DW2)
+// metrics of a CIDFont, in glyph space units.
+type cidWidths struct {
+	dw  float64
+	w   []cidWidthRange
+	dw2 [2]float64 // position vector y and vertical displacement
+	w2  []cidWidthRange
+}
+
+// newCIDWidths reads the metrics of the CIDFont desc.
+func newCIDWidths(desc Value) *cidWidths {
+	c := &cidWidths{dw: 1000, dw2: [2]float64{880, -1000}}
+	if dw := desc.Key("DW"); dw.Kind() == Integer || dw.Kind() == Real {
+		c.dw = dw.Float64()
+	}
+	if dw2 := desc.Key("DW2"); dw2.Len() == 2 {
+		c.dw2 = [2]float64{dw2.Index(0).Float64(), dw2.Index(1).Float64()}
+	}
+	c.w = parseCIDMetrics(desc.Key("W"), 1)
+	c.w2 = parseCIDMetrics(desc.Key("W2"), 3)
+	return c
+}
+
+// parseCIDMetrics reads a /W or /W2 array, whose entries are either
+// "c [v1 v2 ...]" or "cfirst clast v", with n values per CID.
+func parseCIDMetrics(a Value, n int) []cidWidthRange {
+	var ranges []cidWidthRange
+	for i := 0; i+1 < a.Len(); {
+		first := int(a.Index(i).Float64())
+		if list := a.Index(i + 1); list.Kind() == Array {
+			r := cidWidthRange{first: first, last: first + list.Len()/n - 1}
+			for j := 0; j < list.Len(); j++ {
+				r.values = append(r.values, list.Index(j).Float64())
+			}
+			ranges = append(ranges, r)
+			i += 2
+			continue
+		}
+		r := cidWidthRange{first: first, last: int(a.Index(i + 1).Float64()), constant: true}
+		for j := 0; j < n; j++ {
+			r.values = append(r.values, a.Index(i+2+j).Float64())
+		}
+		ranges = append(ranges, r)
+		i += 2 + n
+	}
+	return ranges
+}
+
+// lookupCIDMetrics returns the n values of cid in ranges, or nil.
+func lookupCIDMetrics(ranges []cidWidthRange, cid, n int) []float64 {
+	for _, r := range ranges {
+		if cid < r.first || cid > r.last {
+			continue
+		}
+		if r.constant {
+			return r.values
+		}
+		if i := (cid - r.first) * n; i+n <= len(r.values) {
+			return r.values[i : i+n]
+		}
+	}
+	return nil
+}
+
+// width returns the horizontal displacement of cid, or the default width
+// if cid is unknown (-1) or not listed in /W.
+func (c *cidWidths) width(cid int) float64 {
+	if v := lookupCIDMetrics(c.w, cid, 1); v != nil {
+		return v[0]
+	}
+	return c.dw
+}
+
+// vertical returns the vertical displacement of cid and its position
+// vector, which moves the glyph origin from the horizontal to the
+// vertical writing position.
+func (c *cidWidths) vertical(cid int) (w1y, vx, vy float64) {
+	if v := lookupCIDMetrics(c.w2, cid, 3); v != nil {
+		return v[0], v[1], v[2]
+	}
+	return c.dw2[1], c.width(cid) / 2, c.dw2[0]
+}
+
+// afmWidths are the glyph widths of a standard font, from its AFM file.
+type afmWidths struct {
+	ascii []float64        // U+0020 to U+007E
+	extra map[rune]float64 // other characters of the Latin text fonts
+	def   float64          // characters not listed
+}
+
+// width returns the width of the glyph for r. Accented letters have the
+// width of their base letter (of the dotless i for i).
+func (a *afmWidths) width(r rune) float64 {
+	if r >= 0x20 && r <= 0x7e && a.ascii != nil {
+		return a.ascii[r-0x20]
+	}
+	if w, ok := a.extra[r]; ok {
+		return w
+	}
+	if r == 0xa0 {
+		return a.width(' ')
+	}
+	if base, _ := utf8.DecodeRuneInString(norm.NFD.String(string(r))); base != r && base < 0x80 {
+		if base == 'i' {
+			base = 'ı'
+		}
+		return a.width(base)
+	}
+	return a.def
+}
+
+// afmTable parses 95 widths for U+0020 to U+007E.
+func afmTable(s string) []float64 {
+	f := strings.Fields(s)
+	w := make([]float64, len(f))
+	for i, x := range f {
+		w[i], _ = strconv.ParseFloat(x, 64)
+	}
+	return w
+}
+
+// afmPunctuation lists the widths of quotes, dashes, bullet, ellipsis,
+// copyright, registered, trademark, degree and dotless i, in that order.
+func afmPunctuation(s string) map[rune]float64 {
+	w := afmTable(s)
+	m := make(map[rune]float64)
+	for i, r := range []rune("‘’“”–—•…©®™°ı") {
+		m[r] = w[i]
+	}
+	return m
+}
+
+var (
+	helveticaWidths = &afmWidths{
+		ascii: afmTable(`278 278 355 556 556 889 667 191 333 333 389 584 278 333 278 278
+			556 556 556 556 556 556 556 556 556 556 278 278 584 584 584 556 1015
+			667 667 722 722 667 611 778 722 278 500 667 556 833 722 778 667 778 722 667 611 722 667 944 667 667 611
+			278 278 278 469 556 333
+			556 556 500 556 556 278 556 556 222 222 500 222 833 556 556 556 556 333 500 278 556 500 722 500 500 500
+			334 260 334 584`),
+		extra: afmPunctuation(`222 222 333 333 556 1000 350 1000 737 737 1000 400 278`),
+		def:   556,
+	}
+	helveticaBoldWidths = &afmWidths{
+		ascii: afmTable(`278 333 474 556 556 889 722 238 333 333 389 584 278 333 278 278
+			556 556 556 556 556 556 556 556 556 556 333 333 584 584 584 611 975
+			722 722 722 722 667 611 778 722 278 556 722 611 833 722 778 667 778 722 667 611 722 667 944 667 667 611
+			333 278 333 584 556 333
+			556 611 556 611 556 333 611 611 278 278 556 278 889 611 611 611 611 389 556 333 611 556 778 556 556 500
+			389 280 389 584`),
+		extra: afmPunctuation(`278 278 500 500 556 1000 350 1000 737 737 1000 400 278`),
+		def:   556,
+	}
+	timesRomanWidths = &afmWidths{
+		ascii: afmTable(`250 333 408 500 500 833 778 180 333 333 500 564 250 333 250 278
+			500 500 500 500 500 500 500 500 500 500 278 278 564 564 564 444 921
+			722 667 667 722 611 556 722 722 333 389 722 611 889 722 722 556 722 667 556 611 722 722 944 722 722 611
+			333 278 333 469 500 333
+			444 500 444 500 444 333 500 500 278 278 500 278 778 500 500 500 500 333 389 278 500 500 722 500 500 444
+			480 200 480 541`),
+		extra: afmPunctuation(`333 333 444 444 500 1000 350 1000 760 760 980 400 278`),
+		def:   500,
+	}
+	timesBoldWidths = &afmWidths{
+		ascii: afmTable(`250 333 555 500 500 1000 833 278 333 333 500 570 250 333 250 278
+			500 500 500 500 500 500 500 500 500 500 333 333 570 570 570 500 930
+			722 667 722 722 667 611 778 778 389 500 778 667 944 722 778 611 778 722 556 667 722 722 1000 722 722 667
+			333 278 333 581 500 333
+			500 556 444 556 444 333 500 556 278 333 556 278 833 556 500 556 556 444 389 333 556 500 722 500 500 444
+			394 220 394 520`),
+		extra: afmPunctuation(`333 333 500 500 500 1000 350 1000 747 747 1000 400 278`),
+		def:   500,
+	}
+	timesItalicWidths = &afmWidths{
+		ascii: afmTable(`250 333 420 500 500 833 778 214 333 333 500 675 250 333 250 278
+			500 500 500 500 500 500 500 500 500 500 333 333 675 675 675 500 920
+			611 611 667 722 611 611 722 722 333 444 667 556 833 667 722 611 722 611 500 556 722 611 833 611 556 556
+			389 278 389 422 500 333
+			500 500 444 500 444 278 500 500 278 278 444 278 722 500 500 500 500 389 389 278 500 444 667 444 444 389
+			400 275 400 541`),
+		extra: afmPunctuation(`333 333 556 556 500 889 350 889 760 760 980 400 278`),
+		def:   500,
+	}
+	timesBoldItalicWidths = &afmWidths{
+		ascii: afmTable(`250 389 555 500 500 833 778 278 333 333 500 570 250 333 250 278
+			500 500 500 500 500 500 500 500 500 500 333 333 570 570 570 500 832
+			667 667 667 722 667 667 722 778 389 500 667 611 889 722 722 611 722 667 556 611 722 667 889 667 611 611
+			333 278 333 570 500 333
+			500 500 444 500 444 333 500 556 278 278 500 278 778 556 500 500 500 389 389 278 556 444 667 500 444 389
+			348 220 348 570`),
+		extra: afmPunctuation(`333 333 500 500 500 1000 350 1000 747 747 1000 400 278`),
+		def:   500,
+	}
+	courierWidths = &afmWidths{def: 600}
+
+	// The symbol fonts are approximated by a typical glyph width.
+	symbolWidths       = &afmWidths{def: 500}
+	zapfDingbatsWidths = &afmWidths{def: 790}
+)
+
+// standardFonts maps the names of the standard 14 fonts, and the names
+// commonly used for them, to their widths.
+var standardFonts = map[string]*afmWidths{
+	"Courier":               courierWidths,
+	"Courier-Bold":          courierWidths,
+	"Courier-Oblique":       courierWidths,
+	"Courier-BoldOblique":   courierWidths,
+	"Helvetica":             helveticaWidths,
+	"Helvetica-Bold":        helveticaBoldWidths,
+	"Helvetica-Oblique":     helveticaWidths,
+	"Helvetica-BoldOblique": helveticaBoldWidths,
+	"Times-Roman":           timesRomanWidths,
+	"Times-Bold":            timesBoldWidths,
+	"Times-Italic":          timesItalicWidths,
+	"Times-BoldItalic":      timesBoldItalicWidths,
+	"Symbol":                symbolWidths,
+	"ZapfDingbats":          zapfDingbatsWidths,
+
+	"CourierNew":                   courierWidths,
+	"CourierNew,Bold":              courierWidths,
+	"CourierNew,Italic":            courierWidths,
+	"CourierNew,BoldItalic":        courierWidths,
+	"CourierNewPSMT":               courierWidths,
+	"Arial":                        helveticaWidths,
+	"Arial,Bold":                   helveticaBoldWidths,
+	"Arial,Italic":                 helveticaWidths,
+	"Arial,BoldItalic":             helveticaBoldWidths,
+	"ArialMT":                      helveticaWidths,
+	"Arial-BoldMT":                 helveticaBoldWidths,
+	"Arial-ItalicMT":               helveticaWidths,
+	"Arial-BoldItalicMT":           helveticaBoldWidths,
+	"TimesNewRoman":                timesRomanWidths,
+	"TimesNewRoman,Bold":           timesBoldWidths,
+	"TimesNewRoman,Italic":         timesItalicWidths,
+	"TimesNewRoman,BoldItalic":     timesBoldItalicWidths,
+	"TimesNewRomanPSMT":            timesRomanWidths,
+	"TimesNewRomanPS-BoldMT":       timesBoldWidths,
+	"TimesNewRomanPS-ItalicMT":     timesItalicWidths,
+	"TimesNewRomanPS-BoldItalicMT": timesBoldItalicWidths,
+}
+
+// standardFontWidths returns the widths of a standard font, ignoring a
+// subset prefix, or nil if the font is not one.
+func standardFontWidths(name string) *afmWidths {
+	if i := strings.Index(name, "+"); i >= 0 {
+		name = name[i+1:]
+	}
+	return standardFonts[name]
+}
@@ -0,0 +1,155 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStandardFontTables(t *testing.T) {
+	for name, w := range standardFonts {
+		if w.ascii != nil {
+			assert.Len(t, w.ascii, 95, name)
+		}
+	}
+	helv := standardFontWidths("ABCDEF+Helvetica")
+	require.NotNil(t, helv)
+	assert.Equal(t, 667.0, helv.width('A'))
+	assert.Equal(t, 278.0, helv.width(0xa0))
+	assert.Equal(t, 667.0, helv.width('Á'))
+	assert.Equal(t, 278.0, helv.width('í'), "accented i has the width of the dotless i")
+	assert.Equal(t, 600.0, standardFontWidths("Courier-Bold").width('W'))
+	assert.Nil(t, standardFontWidths("Calibri"))
+}
+
+func TestCIDWidths(t *testing.T) {
+	font := "<< /Type /Font /Subtype /Type0 /Encoding /Identity-H /DescendantFonts [<< /Subtype /CIDFontType0" +
+		" /DW 500 /W [1 [250 300] 10 20 1000] /W2 [5 [-900 250 800] 30 40 -800 500 880] >>] >>"
+	desc := newTestReader(t, layoutTestPDF(font, "")).Page(1).Font("F1").V.Key("DescendantFonts").Index(0)
+	c := newCIDWidths(desc)
+	assert.Equal(t, 250.0, c.width(1))
+	assert.Equal(t, 300.0, c.width(2))
+	assert.Equal(t, 500.0, c.width(3))
+	assert.Equal(t, 1000.0, c.width(15))
+	assert.Equal(t, 500.0, c.width(-1))
+
+	w1y, vx, vy := c.vertical(5)
+	assert.Equal(t, []float64{-900, 250, 800}, []float64{w1y, vx, vy})
+	w1y, vx, vy = c.vertical(35)
+	assert.Equal(t, []float64{-800, 500, 880}, []float64{w1y, vx, vy})
+	w1y, vx, vy = c.vertical(3)
+	assert.Equal(t, []float64{-1000, 250, 880}, []float64{w1y, vx, vy}, "DW2 defaults to [880 -1000]")
+}
+
+func TestGlyphPositions(t *testing.T) {
+	xs := func(t *testing.T, font, content string) []float64 {
+		t.Helper()
+		var x []float64
+		for _, txt := range newTestReader(t, layoutTestPDF(font, content)).Page(1).Content().Text {
+			if txt.S != "\n" {
+				x = append(x, txt.X)
+			}
+		}
+		return x
+	}
+
+	// standard 14 font without /Widths
+	got := xs(t, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>", "BT /F1 10 Tf 100 700 Td (AiW) Tj ET")
+	assert.InDeltaSlice(t, []float64{100, 106.67, 108.89}, got, 0.001)
+
+	// Type0 font with two-byte codes measured by /W
+	got = xs(t, "<< /Type /Font /Subtype /Type0 /BaseFont /Test /Encoding /Identity-H"+
+		" /DescendantFonts [<< /Subtype /CIDFontType0 /CIDSystemInfo << /Registry (Adobe) /Ordering (Japan1) >>"+
+		" /DW 1000 /W [34 [600 500]] >>] >>",
+		"BT /F1 10 Tf 100 700 Td <002200230465> Tj ET")
+	assert.InDeltaSlice(t, []float64{100, 106, 111}, got, 0.001)
+}
+
+func TestVerticalGlyphPositions(t *testing.T) {
+	content := func(t *testing.T, font string) []Text {
+		t.Helper()
+		var text []Text
+		for _, txt := range newTestReader(t, layoutTestPDF(font, "BT /F1 10 Tf 200 700 Td <00220023> Tj [500 <0465>] TJ ET")).Page(1).Content().Text {
+			if txt.S != "\n" {
+				text = append(text, txt)
+			}
+		}
+		return text
+	}
+	pos := func(text []Text) (xy [][2]float64) {
+		for _, t := range text {
+			xy = append(xy, [2]float64{t.X, t.Y})
+		}
+		return xy
+	}
+
+	// default metrics: glyphs centered on x = 200, 1 em apart, with their
+	// tops at the current point; TJ adjustments move down the page
+	text := content(t, type0Font("/Identity-V", "Japan1"))
+	require.Len(t, text, 3)
+	assert.True(t, text[0].Vertical)
+	assert.Equal(t, [][2]float64{{195, 691.2}, {195, 681.2}, {195, 666.2}}, pos(text))
+
+	// /W2 gives CID 34 a half-em displacement and its own position vector
+	font := "<< /Type /Font /Subtype /Type0 /Encoding /Identity-V /DescendantFonts [<< /Subtype /CIDFontType0" +
+		" /CIDSystemInfo << /Registry (Adobe) /Ordering (Japan1) >> /W2 [34 [-500 250 400]] >>] >>"
+	got := pos(content(t, font))
+	assert.InDeltaSlice(t, []float64{197.5, 696, 195, 686.2}, []float64{got[0][0], got[0][1], got[1][0], got[1][1]}, 0.001)
+
+	// horizontal fonts are unaffected
+	assert.False(t, content(t, type0Font("/Identity-H", "Japan1"))[0].Vertical)
+}
+
+func TestType3Font(t *testing.T) {
+	font := func(toUnicode string) string {
+		return "<< /Type /Font /Subtype /Type3 /FontBBox [0 0 100 100] /FontMatrix [0.01 0 0 0.01 0 0]" +
+			" /CharProcs << /A 6 0 R /eacute 6 0 R /f_i 6 0 R /a7 6 0 R >> /Resources << >>" +
+			" /Encoding << /Type /Encoding /Differences [65 /A /eacute /f_i /a7] >>" +
+			" /FirstChar 65 /LastChar 68 /Widths [50 40 100 60]" + toUnicode + " >>"
+	}
+	content := func(t *testing.T, font string, extra ...string) []Text {
+		t.Helper()
+		data := buildPDF(append([]string{
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+			streamObj("", []byte("BT /F1 10 Tf 100 700 Td (ABCD) Tj ET")),
+			font,
+			streamObj("", []byte("50 0 d0")),
+		}, extra...), "")
+		r := newTestReader(t, data)
+		assert.Equal(t, 500.0, r.Page(1).Font("F1").Width(65))
+		var text []Text
+		for _, txt := range r.Page(1).Content().Text {
+			if txt.S != "\n" {
+				text = append(text, txt)
+			}
+		}
+		return text
+	}
+
+	// Differences names decode through the glyph list; widths are in the
+	// glyph space of the FontMatrix, and a ligature shares its width
+	text := content(t, font(""))
+	var s string
+	var x []float64
+	for _, txt := range text {
+		s += txt.S
+		x = append(x, txt.X)
+	}
+	assert.Equal(t, "AéfiD", s)
+	assert.InDeltaSlice(t, []float64{100, 105, 109, 114, 119}, x, 0.001)
+
+	// ToUnicode takes precedence over the glyph names
+	toUnicode := "/CIDInit /ProcSet findresource begin 12 dict begin begincmap\n" +
+		"1 begincodespacerange <00> <FF> endcodespacerange\n" +
+		"1 beginbfchar <44> <0037> endbfchar\n" +
+		"endcmap CMapName currentdict /CMap defineresource pop end end"
+	text = content(t, font(" /ToUnicode 7 0 R"), streamObj("", []byte(toUnicode)))
+	require.NotEmpty(t, text)
+	assert.Equal(t, "7", text[len(text)-1].S)
+}
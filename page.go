@@ -5,7 +5,7 @@ package xtract
 
 import (
 	"bytes"
-	"errors"
+	"context"
 	"fmt"
 	"io"
 	"sort"
@@ -17,30 +17,65 @@ import (
 // A Page represent a single page in a PDF file.
 // The methods interpret a Page dictionary stored in V.
 type Page struct {
-	V Value
+	V   Value
+	ctx context.Context
+}
+
+// WithContext returns a copy of p whose content is interpreted under ctx:
+// its text methods stop and return ctx.Err() once ctx is done.
+func (p Page) WithContext(ctx context.Context) Page {
+	p.ctx = ctx
+	return p
+}
+
+// context returns the page's context, or context.Background.
+func (p Page) context() context.Context {
+	if p.ctx != nil {
+		return p.ctx
+	}
+	return context.Background()
 }
 
 // Page returns the page for the given page number.
 // Page numbers are indexed starting at 1, not 0.
 // If the page is not found, Page returns a Page with p.V.IsNull().
 func (r *Reader) Page(num int) Page {
+	page, _ := r.page(num)
+	return page
+}
+
+// page returns the page for the given page number, or a null Page and the
+// error that stopped the page tree from being searched.
+func (r *Reader) page(num int) (page Page, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			logger.Error(fmt.Sprintf("page %d: %v", num, e))
+			page, err = Page{}, recoveredError(e)
+		}
+	}()
+	orig := r
+	r = r.raising()
+
 	logger.Debug(fmt.Sprintf("Reading Page %d", num), true)
 	num-- // now 0-indexed
-	page := r.Trailer().Key("Root").Key("Pages")
+	node := r.Trailer().Key("Root").Key("Pages")
+	depth := 0
 Search:
-	for page.Key("Type").Name() == "Pages" {
-		count := int(page.Key("Count").Int64())
+	for node.Key("Type").Name() == "Pages" {
+		depth++
+		r.checkDepth(depth)
+		count := int(node.Key("Count").Int64())
 		if count < num {
-			return Page{}
+			return Page{}, nil
 		}
-		kids := page.Key("Kids")
+		kids := node.Key("Kids")
 		logger.Debug(fmt.Sprintf("count of pages: %d, kids: %d", count, kids.Int64()))
 		for i := 0; i < kids.Len(); i++ {
 			kid := kids.Index(i)
 			if kid.Key("Type").Name() == "Pages" {
 				c := int(kid.Key("Count").Int64())
 				if num < c {
-					page = kid
+					node = kid
 					continue Search
 				}
 				num -= c
@@ -48,23 +83,49 @@ Search:
 			}
 			if kid.Key("Type").Name() == "Page" {
 				if num == 0 {
-					return Page{kid}
+					// the page reads its objects like the Reader it is from
+					kid.r = orig
+					return Page{V: kid}, nil
 				}
 				num--
 			}
 		}
 		break
 	}
-	return Page{}
+	return Page{}, nil
 }
 
-// NumPage returns the number of pages in the PDF file.
+// NumPage returns the number of pages in the PDF file, or 0 if the page
+// tree cannot be read.
 func (r *Reader) NumPage() int {
-	return int(r.Trailer().Key("Root").Key("Pages").Key("Count").Int64())
+	n, _ := r.numPage()
+	return n
+}
+
+// numPage is like NumPage, but also returns the error that stopped the
+// page count from being read.
+func (r *Reader) numPage() (n int, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			logger.Error(fmt.Sprintf("page count: %v", e))
+			n, err = 0, recoveredError(e)
+		}
+	}()
+	r = r.raising()
+	return int(r.Trailer().Key("Root").Key("Pages").Key("Count").Int64()), nil
 }
 
 // GetPlainText returns all the text in the PDF file
 func (r *Reader) GetPlainText() (reader io.Reader, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			reader = &bytes.Buffer{}
+			logger.Error(fmt.Sprint(e))
+			err = recoveredError(e)
+		}
+	}()
+	r = r.raising()
+
 	pages := r.NumPage()
 	logger.Debug(fmt.Sprintf("total pages = %d", pages), true)
 	var buf bytes.Buffer
@@ -93,6 +154,15 @@ func (r *Reader) GetPlainText() (reader io.Reader, err error) {
 
 // GetStyledTexts returns list all sentences in an array, that are included styles
 func (r *Reader) GetStyledTexts() (sentences []Text, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			sentences = nil
+			logger.Error(fmt.Sprint(e))
+			err = recoveredError(e)
+		}
+	}()
+	r = r.raising()
+
 	totalPage := r.NumPage()
 	for pageIndex := 1; pageIndex <= totalPage; pageIndex++ {
 		p := r.Page(pageIndex)
@@ -123,9 +193,13 @@ func (r *Reader) GetStyledTexts() (sentences []Text, err error) {
 	return sentences, err
 }
 
-func (p Page) findInherited(key string) Value {
+func (p Page) findInherited(key string) (_ Value) {
 	logger.Debug("inside findInherited")
+	defer p.V.r.catch()
+	depth := 0
 	for v := p.V; !v.IsNull(); v = v.Key("Parent") {
+		depth++
+		p.V.r.checkDepth(depth)
 		if r := v.Key(key); !r.IsNull() {
 			logger.Debug(fmt.Sprintf("findInherited: found key %q in object %d %d R", key, v.ptr.id, v.ptr.gen))
 			return r
@@ -195,14 +269,23 @@ func (f Font) Widths() []float64 {
 	return out
 }
 
-// Width returns the width of the given code point.
+// Width returns the width of the given code point, in thousandths of a
+// text space unit. For a Type0 font, code is a CID, measured with the
+// /W and /DW entries of its CIDFont. The widths of a Type3 font are
+// scaled by its FontMatrix. A simple font without /Widths is measured
+// with the metrics of the standard 14 fonts, if it is one.
 func (f Font) Width(code int) float64 {
-	first := f.FirstChar()
-	last := f.LastChar()
-	if code < first || last < code {
+	if f.V.Key("Subtype").Name() == "Type0" {
+		return newCIDWidths(f.V.Key("DescendantFonts").Index(0)).width(code)
+	}
+	if code < 0 || code > 255 {
+		return 0
+	}
+	if f.V.Key("Widths").Kind() != Array && standardFontWidths(f.BaseFont()) == nil {
 		return 0
 	}
-	return f.V.Key("Widths").Index(code - first).Float64()
+	m := f.metrics(f.Encoder())
+	return m.simple[code] * m.scale * 1000
 }
 
 // Encoder returns the encoding between font code point sequences and UTF-8.
@@ -216,6 +299,9 @@ func (f Font) Encoder() TextEncoding {
 
 func (f Font) getEncoder() TextEncoding {
 	logger.Debug(fmt.Sprintf("getEncoder: determining text encoding for Font %d %d R", f.V.ptr.id, f.V.ptr.gen))
+	if f.V.Key("Subtype").Name() == "Type0" {
+		return f.cidEncoding()
+	}
 	enc := f.V.Key("Encoding")
 	switch enc.Kind() {
 	case Name:
@@ -232,9 +318,15 @@ func (f Font) getEncoder() TextEncoding {
 			return &nopEncoder{}
 		}
 	case Dict:
-		return &dictEncoder{enc.Key("Differences")}
+		if f.V.Key("ToUnicode").Kind() == Stream {
+			return f.charmapEncoding()
+		}
+		return f.glyphEncoding(enc.Key("Differences"), &dictEncoder{enc.Key("Differences")})
 	case Null:
-		return f.charmapEncoding()
+		if f.V.Key("ToUnicode").Kind() == Stream {
+			return f.charmapEncoding()
+		}
+		return f.glyphEncoding(Value{}, &byteEncoder{&pdfDocEncoding})
 	default:
 		logger.Debug("unexpected encoding : %d", enc.String())
 
@@ -489,7 +581,7 @@ func readCmap(toUnicode Value) *cmap {
 	n := -1
 	var m cmap
 	ok := true
-	Interpret(toUnicode, func(stk *Stack, op string) {
+	err := Interpret(toUnicode, func(stk *Stack, op string) {
 		if !ok {
 			return
 		}
@@ -524,8 +616,7 @@ func readCmap(toUnicode Value) *cmap {
 			n = int(stk.Pop().Int64())
 		case "endbfchar":
 			if n < 0 {
-				logger.Error("missing beginbfchar")
-				panic("missing beginbfchar")
+				panic(objectError(toUnicode.ptr, -1, "ToUnicode CMap: missing beginbfchar"))
 			}
 			for i := 0; i < n; i++ {
 				repl, orig := stk.Pop().RawString(), stk.Pop().RawString()
@@ -535,8 +626,7 @@ func readCmap(toUnicode Value) *cmap {
 			n = int(stk.Pop().Int64())
 		case "endbfrange":
 			if n < 0 {
-				logger.Error("missing beginbfrange")
-				panic("missing beginbfrange")
+				panic(objectError(toUnicode.ptr, -1, "ToUnicode CMap: missing beginbfrange"))
 			}
 			for i := 0; i < n; i++ {
 				dst, srcHi, srcLo := stk.Pop(), stk.Pop().RawString(), stk.Pop().RawString()
@@ -553,6 +643,10 @@ func readCmap(toUnicode Value) *cmap {
 			}
 		}
 	})
+	if err != nil {
+		toUnicode.r.fail(err)
+		return nil
+	}
 	if !ok {
 		return nil
 	}
@@ -575,6 +669,11 @@ func (x matrix) mul(y matrix) matrix {
 	return z
 }
 
+// apply transforms the point (x, y) by m.
+func (m matrix) apply(x, y float64) (float64, float64) {
+	return x*m[0][0] + y*m[1][0] + m[2][0], x*m[0][1] + y*m[1][1] + m[2][1]
+}
+
 // A Text represents a single piece of text drawn on a page.
 type Text struct {
 	Font     string  // the font used
@@ -583,17 +682,19 @@ type Text struct {
 	Y        float64 // the Y coordinate, in points, increasing bottom to top
 	W        float64 // the width of the text, in points
 	S        string  // the actual UTF-8 text
+	Vertical bool    // the text is written top to bottom (vertical writing mode)
 }
 
 // A Rect represents a rectangle.
 type Rect struct {
-	Min, Max Point
+	Min Point `json:"min"`
+	Max Point `json:"max"`
 }
 
 // A Point represents an X, Y pair.
 type Point struct {
-	X float64
-	Y float64
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
 }
 
 // Content describes the basic content on a page: the text and any drawn rectangles.
@@ -624,9 +725,10 @@ func (p Page) GetPlainText(fonts map[string]*Font) (result string, err error) {
 		if r := recover(); r != nil {
 			result = ""
 			logger.Error(fmt.Sprint(r))
-			err = errors.New(fmt.Sprint(r))
+			err = recoveredError(r)
 		}
 	}()
+	p = p.raising()
 
 	// Handle in case the content page is empty
 	if p.V.IsNull() || p.V.Key("Contents").Kind() == Null {
@@ -660,7 +762,9 @@ func (p Page) GetPlainText(fonts map[string]*Font) (result string, err error) {
 	}
 	logger.Debug("Parsing content", true)
 
-	Interpret(strm, func(stk *Stack, op string) {
+	forms := p.formStack()
+	var handle func(stk *Stack, op string)
+	handle = func(stk *Stack, op string) {
 		n := stk.Len()
 		args := make([]Value, n)
 		for i := n - 1; i >= 0; i-- {
@@ -677,13 +781,25 @@ func (p Page) GetPlainText(fonts map[string]*Font) (result string, err error) {
 			showText("\n")
 		case "T*": // move to start of next line
 			showEncodedText("\n")
+		case "Do": // paint XObject; only forms can contain text
+			if len(args) != 1 {
+				logger.Error("bad Do")
+				return
+			}
+			forms.doForm(args[0].Name(), func(form Value) {
+				saved := enc
+				forms.interpret(form, handle)
+				enc = saved
+			})
 		case "Tf": // set text font and size
-			logger.Debug(fmt.Sprintf("operator: Tf (%s %v)", args[0].Name(), args[1].Float64()), true)
 			if len(args) != 2 {
 				logger.Error("bad TL")
-				panic("bad TL")
+				return
 			}
-			if font, ok := fonts[args[0].Name()]; ok {
+			logger.Debug(fmt.Sprintf("operator: Tf (%s %v)", args[0].Name(), args[1].Float64()), true)
+			if forms.depth() > 0 {
+				enc = forms.font(args[0].Name()).Encoder()
+			} else if font, ok := fonts[args[0].Name()]; ok {
 				enc = font.Encoder()
 			} else {
 				enc = &nopEncoder{}
@@ -692,25 +808,30 @@ func (p Page) GetPlainText(fonts map[string]*Font) (result string, err error) {
 		case "\"": // set spacing, move to next line, and show text
 			if len(args) != 3 {
 				logger.Error("bad \" operator")
-				panic("bad \" operator")
+				return
 			}
+			args = args[2:]
 			fallthrough
 		case "'": // move to next line and show text
 			if len(args) != 1 {
 				logger.Error("bad ' operator")
-				panic("bad ' operator")
+				return
 			}
 			fallthrough
 		case "Tj": // show text
 			if len(args) != 1 {
 				logger.Error("bad Tj operator")
-				panic("bad Tj operator")
+				return
 			}
 			raw := args[0].RawString()
 			mapped := enc.Decode(raw)
 			logger.Debug(fmt.Sprintf("operator: Tj -> bytes=%#x -> mapped %q", []byte(raw), mapped), true)
 			showEncodedText(raw)
 		case "TJ": // show text, allowing individual glyph positioning
+			if len(args) != 1 {
+				logger.Error("bad TJ operator")
+				return
+			}
 			v := args[0]
 			for i := 0; i < v.Len(); i++ {
 				x := v.Index(i)
@@ -720,7 +841,8 @@ func (p Page) GetPlainText(fonts map[string]*Font) (result string, err error) {
 			}
 			logger.Debug("operator: TJ", true)
 		}
-	})
+	}
+	forms.interpret(strm, handle)
 
 	logger.Debug("Completed content parsing", true)
 
@@ -736,19 +858,21 @@ type Column struct {
 // Columns is a list of column
 type Columns []*Column
 
-// GetTextByColumn returns the page's all text grouped by column
-func (p Page) GetTextByColumn() (Columns, error) {
+// GetTextByColumn returns the page's all text grouped by column.
+// Columns are keyed by the x position of each text run; use GetTextBlocks
+// to detect the columns of a multi-column layout.
+func (p Page) GetTextByColumn() (result Columns, err error) {
 	logger.Debug("retreiving all text grouped by column")
 
-	result := Columns{}
-	var err error
+	result = Columns{}
 
 	defer func() {
 		if r := recover(); r != nil {
 			result = Columns{}
-			err = errors.New(fmt.Sprint(r))
+			err = recoveredError(r)
 		}
 	}()
+	p = p.raising()
 
 	showText := func(enc TextEncoding, currentX, currentY float64, s string) {
 		var textBuilder bytes.Buffer
@@ -809,18 +933,18 @@ type Row struct {
 type Rows []*Row
 
 // GetTextByRow returns the page's all text grouped by rows
-func (p Page) GetTextByRow() (Rows, error) {
+func (p Page) GetTextByRow() (result Rows, err error) {
 	logger.Debug("retrieving all text grouped by columns")
 
-	result := Rows{}
-	var err error
+	result = Rows{}
 
 	defer func() {
 		if r := recover(); r != nil {
 			result = Rows{}
-			err = errors.New(fmt.Sprint(r))
+			err = recoveredError(r)
 		}
 	}()
+	p = p.raising()
 
 	showText := func(enc TextEncoding, currentX, currentY float64, s string) {
 		var textBuilder bytes.Buffer
@@ -893,7 +1017,15 @@ func (p Page) walkTextBlocks(walker func(enc TextEncoding, x, y float64, s strin
 
 	var enc TextEncoding = &nopEncoder{}
 	var currentX, currentY float64
-	Interpret(strm, func(stk *Stack, op string) {
+	// formCTM maps the coordinates of the innermost form to page space.
+	formCTM := ident
+	walk := func(s string) {
+		x, y := formCTM.apply(currentX, currentY)
+		walker(enc, x, y, s)
+	}
+	forms := p.formStack()
+	var handle func(stk *Stack, op string)
+	handle = func(stk *Stack, op string) {
 		n := stk.Len()
 		args := make([]Value, n)
 		for i := n - 1; i >= 0; i-- {
@@ -903,52 +1035,95 @@ func (p Page) walkTextBlocks(walker func(enc TextEncoding, x, y float64, s strin
 		default:
 			return
 		case "T*": // move to start of next line
+		case "Do": // paint XObject; only forms can contain text
+			if len(args) != 1 {
+				logger.Error("bad Do")
+				return
+			}
+			forms.doForm(args[0].Name(), func(form Value) {
+				savedEnc, savedCTM := enc, formCTM
+				savedX, savedY := currentX, currentY
+				formCTM = formMatrix(form).mul(formCTM)
+				forms.interpret(form, handle)
+				enc, formCTM = savedEnc, savedCTM
+				currentX, currentY = savedX, savedY
+			})
 		case "Tf": // set text font and size
 			if len(args) != 2 {
-				panic("bad TL")
+				logger.Error("bad TL")
+				return
 			}
 
-			if font, ok := fonts[args[0].Name()]; ok {
+			if forms.depth() > 0 {
+				enc = forms.font(args[0].Name()).Encoder()
+			} else if font, ok := fonts[args[0].Name()]; ok {
 				enc = font.Encoder()
 			} else {
 				enc = &nopEncoder{}
 			}
 		case "\"": // set spacing, move to next line, and show text
 			if len(args) != 3 {
-				panic("bad \" operator")
+				logger.Error("bad \" operator")
+				return
 			}
+			args = args[2:]
 			fallthrough
 		case "'": // move to next line and show text
 			if len(args) != 1 {
-				panic("bad ' operator")
+				logger.Error("bad ' operator")
+				return
 			}
 			fallthrough
 		case "Tj": // show text
 			if len(args) != 1 {
-				panic("bad Tj operator")
+				logger.Error("bad Tj operator")
+				return
 			}
 
-			walker(enc, currentX, currentY, args[0].RawString())
+			walk(args[0].RawString())
 		case "TJ": // show text, allowing individual glyph positioning
+			if len(args) != 1 {
+				logger.Error("bad TJ operator")
+				return
+			}
 			v := args[0]
 			for i := 0; i < v.Len(); i++ {
 				x := v.Index(i)
 				if x.Kind() == String {
-					walker(enc, currentX, currentY, x.RawString())
+					walk(x.RawString())
 				}
 			}
 		case "Td":
-			walker(enc, currentX, currentY, "")
+			walk("")
 		case "Tm":
 			currentX = args[4].Float64()
 			currentY = args[5].Float64()
 		}
-	})
+	}
+	forms.interpret(strm, handle)
 }
 
-// Content returns the page's content.
-func (p Page) Content() Content {
+// Content returns the page's content, or an empty Content if the content
+// stream is malformed.
+func (p Page) Content() (c Content) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error(fmt.Sprint(r))
+			c = Content{}
+		}
+	}()
+	p = p.raising()
+
 	logger.Debug(fmt.Sprintf("Content: starting content extraction for Page %d %d R", p.V.ptr.id, p.V.ptr.gen))
+	return p.content(nil, nil)
+}
+
+// content interprets the page's content stream. If onGlyph is not nil it is
+// called for every glyph shown, along with the distance in page space by
+// which the text position advanced after it (glyph width, Tc and Tw). If
+// onLine is not nil it is called, in page space, for every straight path
+// segment that is stroked or filled.
+func (p Page) content(onGlyph func(t Text, adv float64), onLine func(from, to Point)) Content {
 	// Handle in case the content page is empty
 	if p.V.IsNull() || p.V.Key("Contents").Kind() == Null {
 		return Content{}
@@ -962,33 +1137,109 @@ func (p Page) Content() Content {
 	}
 
 	var text []Text
+	metrics := Font{}.metrics(enc)
+	// showVertical shows the glyph of code in vertical writing mode: it is
+	// placed by its position vector below the current point, which then
+	// moves down by the glyph's vertical displacement.
+	showVertical := func(f, code string, w0 float64) {
+		w1y, vx, vy := metrics.verticalMetrics(code)
+		ty := w1y*g.Tfs + g.Tc
+		if code == " " {
+			ty += g.Tw
+		}
+
+		chars := []rune(enc.Decode(code))
+		n := float64(max(len(chars), 1))
+		Trm := matrix{{g.Tfs * g.Th, 0, 0}, {0, g.Tfs, 0}, {-vx * g.Tfs, g.Trise - vy*g.Tfs, 1}}.mul(g.Tm).mul(g.CTM)
+		adv := -ty * g.Tm.mul(g.CTM)[1][1] / n
+		for i, ch := range chars {
+			t := Text{f, Trm[0][0], Trm[2][0], Trm[2][1] - float64(i)*adv, w0 * Trm[0][0], string(ch), true}
+			text = append(text, t)
+			if onGlyph != nil {
+				onGlyph(t, adv)
+			}
+		}
+		g.Tm = matrix{{1, 0, 0}, {0, 1, 0}, {0, ty, 1}}.mul(g.Tm)
+	}
 	showText := func(s string) {
-		n := 0
-		decoded := enc.Decode(s)
-		for _, ch := range decoded {
-			var w0 float64
-			if n < len(s) {
-				w0 = g.Tf.Width(int(s[n]))
+		f := g.Tf.BaseFont()
+		if i := strings.Index(f, "+"); i >= 0 {
+			f = f[i+1:]
+		}
+		for len(s) > 0 {
+			code := metrics.next(s)
+			s = s[len(code):]
+			w0 := metrics.width(code)
+			if metrics.vertical {
+				showVertical(f, code, w0)
+				continue
 			}
-			n++
+			// word spacing applies to the single-byte code 32
+			space := code == " "
 
-			f := g.Tf.BaseFont()
-			if i := strings.Index(f, "+"); i >= 0 {
-				f = f[i+1:]
+			tx := w0*g.Tfs + g.Tc
+			if space {
+				tx += g.Tw
 			}
+			tx *= g.Th
 
+			// a glyph decoding to several characters, such as a
+			// ligature, shares its width among them
+			chars := []rune(enc.Decode(code))
 			Trm := matrix{{g.Tfs * g.Th, 0, 0}, {0, g.Tfs, 0}, {0, g.Trise, 1}}.mul(g.Tm).mul(g.CTM)
-			text = append(text, Text{f, Trm[0][0], Trm[2][0], Trm[2][1], w0 / 1000 * Trm[0][0], string(ch)})
-
-			tx := w0/1000*g.Tfs + g.Tc
-			tx *= g.Th
+			w := w0 * Trm[0][0] / float64(max(len(chars), 1))
+			for i, ch := range chars {
+				t := Text{f, Trm[0][0], Trm[2][0] + float64(i)*w, Trm[2][1], w, string(ch), false}
+				text = append(text, t)
+				if onGlyph != nil {
+					onGlyph(t, tx*g.Tm.mul(g.CTM)[0][0]/float64(len(chars)))
+				}
+			}
 			g.Tm = matrix{{1, 0, 0}, {0, 1, 0}, {tx, 0, 1}}.mul(g.Tm)
 		}
 	}
 
+	// showBreak marks the end of a TJ array with a newline at the current
+	// point, without moving it.
+	showBreak := func() {
+		f := g.Tf.BaseFont()
+		if i := strings.Index(f, "+"); i >= 0 {
+			f = f[i+1:]
+		}
+		Trm := matrix{{g.Tfs * g.Th, 0, 0}, {0, g.Tfs, 0}, {0, g.Trise, 1}}.mul(g.Tm).mul(g.CTM)
+		t := Text{f, Trm[0][0], Trm[2][0], Trm[2][1], 0, "\n", metrics.vertical}
+		text = append(text, t)
+		if onGlyph != nil {
+			onGlyph(t, 0)
+		}
+	}
+
 	var rect []Rect
 	var gstack []gstate
-	Interpret(strm, func(stk *Stack, op string) {
+
+	// straight segments of the current path, in page space
+	var path [][2]Point
+	var cur, start Point
+	point := func(x, y Value) Point {
+		px, py := g.CTM.apply(x.Float64(), y.Float64())
+		return Point{px, py}
+	}
+	lineTo := func(to Point) {
+		path = append(path, [2]Point{cur, to})
+		cur = to
+	}
+	paint := func() {
+		if onLine != nil {
+			for _, seg := range path {
+				onLine(seg[0], seg[1])
+			}
+		}
+		path = path[:0]
+	}
+
+	forms := p.formStack()
+	var handle func(stk *Stack, op string)
+	handle = func(stk *Stack, op string) {
 		n := stk.Len()
 		args := make([]Value, n)
 		for i := n - 1; i >= 0; i-- {
@@ -1003,7 +1254,8 @@ func (p Page) Content() Content {
 
 		case "cm": // update g.CTM
 			if len(args) != 6 {
-				panic("bad g.Tm")
+				logger.Error("bad g.Tm")
+				return
 			}
 			var m matrix
 			for i := 0; i < 6; i++ {
@@ -1021,28 +1273,90 @@ func (p Page) Content() Content {
 			// }
 			//}
 
-		case "f": // fill
+		case "Do": // paint XObject; forms run with an implicit q/Q
+			if len(args) != 1 {
+				logger.Error("bad Do")
+				return
+			}
+			forms.doForm(args[0].Name(), func(form Value) {
+				saved, savedEnc, savedMetrics, depth := g, enc, metrics, len(gstack)
+				g.CTM = formMatrix(form).mul(g.CTM)
+				forms.interpret(form, handle)
+				g, enc, metrics, gstack = saved, savedEnc, savedMetrics, gstack[:depth]
+			})
+
 		case "g": // setgray
-		case "l": // lineto
+
 		case "m": // moveto
+			if len(args) != 2 {
+				logger.Error("bad m")
+				return
+			}
+			cur = point(args[0], args[1])
+			start = cur
+
+		case "l": // lineto
+			if len(args) != 2 {
+				logger.Error("bad l")
+				return
+			}
+			lineTo(point(args[0], args[1]))
+
+		case "c", "v", "y": // curveto: only the end point matters for rulings
+			if len(args) < 4 {
+				logger.Error("bad " + op)
+				return
+			}
+			cur = point(args[len(args)-2], args[len(args)-1])
+
+		case "h": // close subpath
+			lineTo(start)
+
+		case "s", "b", "b*": // close, then stroke or fill
+			lineTo(start)
+			paint()
+		case "S", "f", "F", "f*", "B", "B*": // stroke or fill
+			paint()
+		case "n": // end path without painting
+			path = path[:0]
 
 		case "cs": // set colorspace non-stroking
 		case "scn": // set color non-stroking
 
 		case "re": // append rectangle to path
 			if len(args) != 4 {
-				panic("bad re")
+				logger.Error("bad re")
+				return
 			}
 			x, y, w, h := args[0].Float64(), args[1].Float64(), args[2].Float64(), args[3].Float64()
 			rect = append(rect, Rect{Point{x, y}, Point{x + w, y + h}})
+			cur = point(args[0], args[1])
+			start = cur
+			for _, c := range [][2]float64{{x + w, y}, {x + w, y + h}, {x, y + h}, {x, y}} {
+				px, py := g.CTM.apply(c[0], c[1])
+				lineTo(Point{px, py})
+			}
 
 		case "q": // save graphics state
 			gstack = append(gstack, g)
 
 		case "Q": // restore graphics state
 			n := len(gstack) - 1
+			if n < 0 {
+				logger.Error("bad Q: no saved graphics state")
+				return
+			}
+			tf := g.Tf
 			g = gstack[n]
 			gstack = gstack[:n]
+			// a Tf since q changed how codes are split and measured
+			if g.Tf.V.ptr != tf.V.ptr || g.Tf.V.ptr == (objptr{}) {
+				enc = g.Tf.enc
+				if enc == nil {
+					enc = &nopEncoder{}
+				}
+				metrics = g.Tf.metrics(enc)
+			}
 
 		case "BT": // begin text (reset text matrix and line matrix)
 			g.Tm = ident
@@ -1058,21 +1372,21 @@ func (p Page) Content() Content {
 		case "Tc": // set character spacing
 			if len(args) != 1 {
 				logger.Error("bad g.Tc")
-				panic("bad g.Tc")
+				return
 			}
 			g.Tc = args[0].Float64()
 
 		case "TD": // move text position and set leading
 			if len(args) != 2 {
 				logger.Error("bad Td")
-				panic("bad Td")
+				return
 			}
 			g.Tl = -args[1].Float64()
 			fallthrough
 		case "Td": // move text position
 			if len(args) != 2 {
 				logger.Error("bad Td")
-				panic("bad Td")
+				return
 			}
 			tx := args[0].Float64()
 			ty := args[1].Float64()
@@ -1083,10 +1397,10 @@ func (p Page) Content() Content {
 		case "Tf": // set text font and size
 			if len(args) != 2 {
 				logger.Error("bad TL")
-				panic("bad TL")
+				return
 			}
 			f := args[0].Name()
-			g.Tf = p.Font(f)
+			g.Tf = forms.font(f)
 			enc = g.Tf.Encoder()
 			if enc == nil {
 				if DebugOn {
@@ -1095,12 +1409,14 @@ func (p Page) Content() Content {
 				logger.Debug(fmt.Sprintf("no cmap for %s", f))
 				enc = &nopEncoder{}
 			}
+			g.Tf.enc = enc // restored with g by Q
+			metrics = g.Tf.metrics(enc)
 			g.Tfs = args[1].Float64()
 
 		case "\"": // set spacing, move to next line, and show text
 			if len(args) != 3 {
 				logger.Error("bad \" operator")
-				panic("bad \" operator")
+				return
 			}
 			g.Tw = args[0].Float64()
 			g.Tc = args[1].Float64()
@@ -1109,7 +1425,7 @@ func (p Page) Content() Content {
 		case "'": // move to next line and show text
 			if len(args) != 1 {
 				logger.Error("bad ' operator")
-				panic("bad ' operator")
+				return
 			}
 			x := matrix{{1, 0, 0}, {0, 1, 0}, {0, -g.Tl, 1}}
 			g.Tlm = x.mul(g.Tlm)
@@ -1118,34 +1434,41 @@ func (p Page) Content() Content {
 		case "Tj": // show text
 			if len(args) != 1 {
 				logger.Error("bad Tj operator")
-				panic("bad Tj operator")
+				return
 			}
 			showText(args[0].RawString())
 
 		case "TJ": // show text, allowing individual glyph positioning
+			if len(args) != 1 {
+				logger.Error("bad TJ operator")
+				return
+			}
 			v := args[0]
 			for i := 0; i < v.Len(); i++ {
 				x := v.Index(i)
 				if x.Kind() == String {
 					showText(x.RawString())
+				} else if metrics.vertical {
+					ty := -x.Float64() / 1000 * g.Tfs
+					g.Tm = matrix{{1, 0, 0}, {0, 1, 0}, {0, ty, 1}}.mul(g.Tm)
 				} else {
 					tx := -x.Float64() / 1000 * g.Tfs * g.Th
 					g.Tm = matrix{{1, 0, 0}, {0, 1, 0}, {tx, 0, 1}}.mul(g.Tm)
 				}
 			}
-			showText("\n")
+			showBreak()
 
 		case "TL": // set text leading
 			if len(args) != 1 {
 				logger.Error("bad TL")
-				panic("bad TL")
+				return
 			}
 			g.Tl = args[0].Float64()
 
 		case "Tm": // set text matrix and line matrix
 			if len(args) != 6 {
 				logger.Error("bad g.Tm")
-				panic("bad g.Tm")
+				return
 			}
 			var m matrix
 			for i := 0; i < 6; i++ {
@@ -1158,32 +1481,33 @@ func (p Page) Content() Content {
 		case "Tr": // set text rendering mode
 			if len(args) != 1 {
 				logger.Error("bad Tr")
-				panic("bad Tr")
+				return
 			}
 			g.Tmode = int(args[0].Int64())
 
 		case "Ts": // set text rise
 			if len(args) != 1 {
 				logger.Error("bad Ts")
-				panic("bad Ts")
+				return
 			}
 			g.Trise = args[0].Float64()
 
 		case "Tw": // set word spacing
 			if len(args) != 1 {
 				logger.Error("bad g.Tw")
-				panic("bad g.Tw")
+				return
 			}
 			g.Tw = args[0].Float64()
 
 		case "Tz": // set horizontal text scaling
 			if len(args) != 1 {
 				logger.Error("bad Tz")
-				panic("bad Tz")
+				return
 			}
 			g.Th = args[0].Float64() / 100
 		}
-	})
+	}
+	forms.interpret(strm, handle)
 	return Content{text, rect}
 }
 
@@ -4,12 +4,16 @@
 package xtract
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"runtime"
 	"strings"
 	"sync"
+	"time"
+	"unicode"
 
 	"github.com/sassoftware/pdf-xtract/logger"
 	"golang.org/x/sync/semaphore"
@@ -28,20 +32,28 @@ type ExtractorStrategy interface {
 
 // StrictExtractor enforces strict parsing.
 // If any page fails, the entire extraction fails.
-type StrictExtractor struct{}
+type StrictExtractor struct {
+	Mode TextMode
+}
 
 func (s *StrictExtractor) ExtractPage(ctx context.Context, page *Page) (string, error) {
-	fonts := cacheFonts(page)
-	return page.GetPlainText(fonts)
+	p := page.WithContext(ctx)
+	return pageText(&p, s.Mode)
 }
 
 // BestEffortExtractor tolerates errors.
-// If a page fails, it simply skips that page.
-type BestEffortExtractor struct{}
+// If a page fails, it simply skips that page, unless the page exceeds the
+// document's Limits or ctx is done before the page is extracted.
+type BestEffortExtractor struct {
+	Mode TextMode
+}
 
 func (b *BestEffortExtractor) ExtractPage(ctx context.Context, page *Page) (string, error) {
-	fonts := cacheFonts(page)
-	text, err := page.GetPlainText(fonts)
+	p := page.WithContext(ctx)
+	text, err := pageText(&p, b.Mode)
+	if isAbort(err) {
+		return "", err
+	}
 	if err != nil {
 		// In best-effort mode, ignore errors and continue.
 		logger.Debug("BestEffortExtractor: failed to extract page text, ignoring error", "page", page, "err", err, true)
@@ -50,12 +62,17 @@ func (b *BestEffortExtractor) ExtractPage(ctx context.Context, page *Page) (stri
 	return text, nil
 }
 
+// maxAttachmentDepth limits how deeply attached PDFs are followed when
+// Config.ExtractAttachments is set.
+const maxAttachmentDepth = 3
+
 // processor manages PDF extraction with concurrency control
 // and delegates page-level work to the chosen ExtractorStrategy.
 type processor struct {
 	cfg       *Config
 	sem       *semaphore.Weighted
 	extractor ExtractorStrategy
+	fonts     bool // workers read the fonts of each page, for ExtractPages
 }
 
 // NewProcessor validates the config and creates a new processor.
@@ -65,9 +82,9 @@ func NewProcessor(cfg *Config) *processor {
 	var extractor ExtractorStrategy
 	switch cfg.ParsingMode {
 	case Strict:
-		extractor = &StrictExtractor{}
+		extractor = &StrictExtractor{Mode: cfg.TextMode}
 	case BestEffort:
-		extractor = &BestEffortExtractor{}
+		extractor = &BestEffortExtractor{Mode: cfg.TextMode}
 	}
 
 	//Validate the config object
@@ -80,8 +97,8 @@ func NewProcessor(cfg *Config) *processor {
 		logger.SetLogger(cfg.Logger)
 	}
 
-	logger.Debug(fmt.Sprintf("Processor initialized: parsing_mode=%v, max_concurrent_pdfs=%d, max_workers_per_pdf=%d",
-		cfg.ParsingMode, cfg.MaxConcurrentPDFs, cfg.MaxWorkersPerPDF), true)
+	logger.Debug(fmt.Sprintf("Processor initialized: parsing_mode=%v, text_mode=%v, max_concurrent_pdfs=%d, max_workers_per_pdf=%d",
+		cfg.ParsingMode, cfg.TextMode, cfg.MaxConcurrentPDFs, cfg.MaxWorkersPerPDF), true)
 
 	return &processor{
 		cfg:       cfg,
@@ -90,9 +107,43 @@ func NewProcessor(cfg *Config) *processor {
 	}
 }
 
+// readerOptions returns the options documents are opened with. In
+// BestEffort mode, damaged cross-reference data is rebuilt by scanning
+// the file.
+func (p *processor) readerOptions() ReaderOptions {
+	return ReaderOptions{Password: p.cfg.Password, Recover: p.cfg.ParsingMode == BestEffort, Limits: p.cfg.Limits}
+}
+
 // Extract extracts PDF text in order, respecting maxChars or Config.MaxTotalChars as a limit.
 // Returns the full text (or up to the limit) and a truncated flag if the output hits the character limit.
 func (p *processor) Extract(ctx context.Context, path string) (string, bool, error) {
+	return p.extract(ctx, fileSource(path))
+}
+
+// ExtractReaderAt is like Extract, but reads the size bytes of the document
+// from ra, which must not change until ExtractReaderAt returns.
+func (p *processor) ExtractReaderAt(ctx context.Context, ra io.ReaderAt, size int64) (string, bool, error) {
+	return p.extract(ctx, readerAtSource("reader", ra, size))
+}
+
+// ExtractBytes is like Extract, but reads the document from data.
+func (p *processor) ExtractBytes(ctx context.Context, data []byte) (string, bool, error) {
+	return p.extract(ctx, readerAtSource("bytes", bytes.NewReader(data), int64(len(data))))
+}
+
+// ExtractReader is like Extract, but reads the document from rd. A reader
+// that also implements io.ReaderAt and io.Seeker is read in place; any
+// other reader is read to the end first, into memory if the document has
+// at most Config.SpoolThreshold bytes and into a temporary file in
+// Config.SpoolDir otherwise. The file is removed before ExtractReader
+// returns.
+func (p *processor) ExtractReader(ctx context.Context, rd io.Reader) (string, bool, error) {
+	return p.extract(ctx, p.readerSource(rd))
+}
+
+// extract extracts the text of the document src.
+func (p *processor) extract(ctx context.Context, src source) (string, bool, error) {
+	path := src.name
 	logger.Debug(fmt.Sprintf("Starting extraction: path=%s", path), true)
 
 	if err := p.acquireSlot(ctx); err != nil {
@@ -102,11 +153,12 @@ func (p *processor) Extract(ctx context.Context, path string) (string, bool, err
 	defer p.sem.Release(1)
 	logger.Debug(fmt.Sprintf("Slot acquired for extraction: path=%s", path), true)
 
-	_, r, err := Open(path)
+	r, release, err := src.open(ctx, p.readerOptions())
 	if err != nil {
 		logger.Debug(fmt.Sprintf("Failed to open PDF: path=%s err=%v", path, err), true)
 		return "", false, err
 	}
+	defer release()
 
 	total := r.NumPage()
 	logger.Debug(fmt.Sprintf("Total pages detected: path=%s pages=%d", path, total), true)
@@ -121,9 +173,16 @@ func (p *processor) Extract(ctx context.Context, path string) (string, bool, err
 
 	jobs, results := make(chan int, total), make(chan pageResult, total)
 
+	// when the extraction stops early, the workers are stopped too, and
+	// are done with r before it is released
+	workCtx, cancel := context.WithCancel(ctx)
 	var wg sync.WaitGroup
-	p.startWorkers(ctx, *r, jobs, results, numWorkers, &wg)
-	p.feedJobs(ctx, total, jobs)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	p.startWorkers(workCtx, *r, jobs, results, numWorkers, &wg)
+	p.feedJobs(workCtx, total, jobs)
 	close(jobs)
 
 	// In-order collection with truncation
@@ -138,14 +197,55 @@ func (p *processor) Extract(ctx context.Context, path string) (string, bool, err
 	if err != nil {
 		return "", false, err
 	}
+	if err := ctx.Err(); err != nil {
+		logger.Debug(fmt.Sprintf("Extraction cancelled: path=%s err=%v", path, err), true)
+		return "", false, err
+	}
+	text := out.String()
+	if p.cfg.AppendXFA && !truncated {
+		if text, truncated, err = p.appendXFA(r, text); err != nil {
+			return "", false, err
+		}
+	}
+	if p.cfg.ExtractAttachments && !truncated {
+		if text, truncated, err = p.appendAttachments(ctx, r, text, 1); err != nil {
+			return "", false, err
+		}
+	}
 
-	logger.Debug(fmt.Sprintf("Extraction completed: path=%s truncated=%v total_chars=%d", path, truncated, out.Len()), true)
-	return out.String(), truncated, nil
+	logger.Debug(fmt.Sprintf("Extraction completed: path=%s truncated=%v total_chars=%d", path, truncated, len(text)), true)
+	return text, truncated, nil
 }
 
 // ExtractAsStream streams PDF text in order, respecting maxChars or Config.MaxTotalChars as a limit.
 // Stops emitting further text once the effective character limit is reached, supporting unlimited extraction if limit is 0.
 func (p *processor) ExtractAsStream(ctx context.Context, path string) (<-chan string, bool, error) {
+	return p.extractAsStream(ctx, fileSource(path))
+}
+
+// ExtractAsStreamReaderAt is like ExtractAsStream, but reads the size bytes
+// of the document from ra, which must not change until the channel is
+// closed.
+func (p *processor) ExtractAsStreamReaderAt(ctx context.Context, ra io.ReaderAt, size int64) (<-chan string, bool, error) {
+	return p.extractAsStream(ctx, readerAtSource("reader", ra, size))
+}
+
+// ExtractAsStreamBytes is like ExtractAsStream, but reads the document from
+// data.
+func (p *processor) ExtractAsStreamBytes(ctx context.Context, data []byte) (<-chan string, bool, error) {
+	return p.extractAsStream(ctx, readerAtSource("bytes", bytes.NewReader(data), int64(len(data))))
+}
+
+// ExtractAsStreamReader is like ExtractAsStream, but reads the document from
+// rd as ExtractReader does. A temporary file is removed before the channel
+// is closed.
+func (p *processor) ExtractAsStreamReader(ctx context.Context, rd io.Reader) (<-chan string, bool, error) {
+	return p.extractAsStream(ctx, p.readerSource(rd))
+}
+
+// extractAsStream streams the text of the document src.
+func (p *processor) extractAsStream(ctx context.Context, src source) (<-chan string, bool, error) {
+	path := src.name
 	logger.Debug(fmt.Sprintf("Starting streaming extraction: path=%s", path), true)
 
 	if err := p.acquireSlot(ctx); err != nil {
@@ -154,7 +254,7 @@ func (p *processor) ExtractAsStream(ctx context.Context, path string) (<-chan st
 	}
 	defer p.sem.Release(1)
 
-	_, r, err := Open(path)
+	r, release, err := src.open(ctx, p.readerOptions())
 	if err != nil {
 		logger.Debug(fmt.Sprintf("Failed to open PDF for streaming: err=%v", err), true)
 		return nil, false, err
@@ -164,6 +264,7 @@ func (p *processor) ExtractAsStream(ctx context.Context, path string) (<-chan st
 	logger.Debug(fmt.Sprintf("Streaming: total pages=%d", total), true)
 
 	if total == 0 {
+		release()
 		ch := make(chan string)
 		close(ch)
 		return ch, false, nil
@@ -174,8 +275,10 @@ func (p *processor) ExtractAsStream(ctx context.Context, path string) (<-chan st
 
 	var wg sync.WaitGroup
 
-	p.startWorkers(ctx, *r, jobs, results, numWorkers, &wg)
-	p.feedJobs(ctx, total, jobs)
+	// the workers stop when the streaming does
+	workCtx, cancel := context.WithCancel(ctx)
+	p.startWorkers(workCtx, *r, jobs, results, numWorkers, &wg)
+	p.feedJobs(workCtx, total, jobs)
 	close(jobs)
 
 	outCh := make(chan string)
@@ -185,9 +288,11 @@ func (p *processor) ExtractAsStream(ctx context.Context, path string) (<-chan st
 		defer close(outCh)
 		go func() {
 			wg.Wait()
+			release()
 			close(results)
 		}()
 		truncated = p.streamInOrder(results, outCh)
+		cancel()
 		logger.Debug(fmt.Sprintf("Streaming extraction completed: path=%s truncated=%v", path, truncated), true)
 	}()
 
@@ -195,21 +300,24 @@ func (p *processor) ExtractAsStream(ctx context.Context, path string) (<-chan st
 }
 
 func (p *processor) emitInOrder(results chan pageResult) (strings.Builder, bool, error) {
-	pageBuffer := make(map[int]string)
-	nextPage := 1
+	queue := newPageQueue()
 	var out strings.Builder
 	truncated := false
 	for res := range results {
+		if errors.Is(res.err, ErrLimitExceeded) {
+			logger.Debug(fmt.Sprintf("Limit exceeded — stopping extraction: page=%d err=%v", res.index, res.err), true)
+			return out, false, fmt.Errorf("page %d: %w", res.index, res.err)
+		}
 		if res.err != nil && p.cfg.ParsingMode == Strict {
 			logger.Debug(fmt.Sprintf("Strict mode error — stopping extraction: page=%d err=%v", res.index, res.err))
 			return out, false, fmt.Errorf("strict mode failed on page %d: %w", res.index, res.err)
 		}
-		pageBuffer[res.index] = res.text
+		queue.add(res.index, res.text)
 
 		// Emit in-order pages immediately
 		for {
-			text, ok := pageBuffer[nextPage]
-			if !ok || text == "" {
+			nextPage, text, ok := queue.next()
+			if !ok {
 				break
 			}
 
@@ -233,9 +341,6 @@ func (p *processor) emitInOrder(results chan pageResult) (strings.Builder, bool,
 				out.WriteString(text)
 			}
 
-			delete(pageBuffer, nextPage)
-			nextPage++
-
 			if truncated {
 				break
 			}
@@ -247,22 +352,143 @@ func (p *processor) emitInOrder(results chan pageResult) (strings.Builder, bool,
 	return out, truncated, nil
 }
 
+// appendXFA appends the document's XFA form data to text, within the
+// Config.MaxTotalChars limit. XFA errors fail the extraction only in
+// Strict mode.
+func (p *processor) appendXFA(r *Reader, text string) (string, bool, error) {
+	fields, err := r.XFAFields()
+	if err != nil {
+		logger.Debug(fmt.Sprintf("XFA data not extracted: err=%v", err), true)
+		if p.cfg.ParsingMode == Strict {
+			return "", false, fmt.Errorf("strict mode failed on XFA data: %w", err)
+		}
+		return text, false, nil
+	}
+	var out strings.Builder
+	out.WriteString(text)
+	for _, f := range fields {
+		line := f.Key + ": " + f.Value + "\n"
+		if p.cfg.MaxTotalChars > 0 {
+			if remaining := p.cfg.MaxTotalChars - out.Len(); len(line) > remaining {
+				out.WriteString(line[:max(remaining, 0)])
+				logger.Debug(fmt.Sprintf("Truncation reached in XFA data: limit=%d", p.cfg.MaxTotalChars), true)
+				return out.String(), true, nil
+			}
+		}
+		out.WriteString(line)
+	}
+	logger.Debug(fmt.Sprintf("Appended XFA data: fields=%d", len(fields)), true)
+	return out.String(), false, nil
+}
+
+// appendAttachments appends the text of each PDF attached to r, headed by
+// its name, to text within the Config.MaxTotalChars limit. Attached PDFs
+// are read page by page and their own attachments followed up to
+// maxAttachmentDepth levels deep.
+func (p *processor) appendAttachments(ctx context.Context, r *Reader, text string, depth int) (string, bool, error) {
+	files, err := r.Attachments()
+	if err != nil {
+		logger.Debug(fmt.Sprintf("Attachments not extracted: err=%v", err), true)
+		if isAbort(err) {
+			return "", false, fmt.Errorf("attachments: %w", err)
+		}
+		if p.cfg.ParsingMode == Strict {
+			return "", false, fmt.Errorf("strict mode failed on attachments: %w", err)
+		}
+		return text, false, nil
+	}
+	for _, a := range files {
+		if !a.IsPDF() {
+			continue
+		}
+		sub, err := p.attachmentText(ctx, r, a, depth)
+		if err != nil {
+			logger.Debug(fmt.Sprintf("Attachment not extracted: name=%s err=%v", a.Name, err), true)
+			if isAbort(err) {
+				return "", false, fmt.Errorf("attachment %q: %w", a.Name, err)
+			}
+			if p.cfg.ParsingMode == Strict {
+				return "", false, fmt.Errorf("strict mode failed on attachment %q: %w", a.Name, err)
+			}
+			continue
+		}
+		text += "\n--- attachment: " + a.Name + " ---\n" + sub
+		if p.cfg.MaxTotalChars > 0 && len(text) >= p.cfg.MaxTotalChars {
+			logger.Debug(fmt.Sprintf("Truncation reached in attachments: limit=%d", p.cfg.MaxTotalChars), true)
+			return text[:p.cfg.MaxTotalChars], true, nil
+		}
+	}
+	return text, false, nil
+}
+
+// attachmentText extracts the text of a PDF attached to the document r,
+// including the PDFs attached to it. The attached PDF counts against the
+// Limits of r.
+func (p *processor) attachmentText(ctx context.Context, r *Reader, a Attachment, depth int) (string, error) {
+	rc, err := a.Open()
+	if err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return "", err
+	}
+	opts := p.readerOptions()
+	opts.usage = r.usage
+	r, err = NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), opts)
+	if err != nil {
+		return "", err
+	}
+	total, err := r.numPage()
+	if err != nil {
+		return "", err
+	}
+	logger.Debug(fmt.Sprintf("Extracting attachment: name=%s pages=%d depth=%d", a.Name, total, depth), true)
+
+	var out strings.Builder
+	for i := 1; i <= total; i++ {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		page := r.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		text, _, err := p.extractPage(ctx, &page, i)
+		if err != nil {
+			if p.cfg.ParsingMode == Strict || isAbort(err) {
+				return "", fmt.Errorf("page %d: %w", i, err)
+			}
+			continue
+		}
+		out.WriteString(text)
+		if p.cfg.MaxTotalChars > 0 && out.Len() >= p.cfg.MaxTotalChars {
+			return out.String(), nil
+		}
+	}
+	text := out.String()
+	if depth < maxAttachmentDepth {
+		text, _, err = p.appendAttachments(ctx, r, text, depth+1)
+	}
+	return text, err
+}
+
 func (p *processor) streamInOrder(results chan pageResult, outCh chan string) (truncated bool) {
-	pageBuffer := make(map[int]string)
-	nextPage := 1
+	queue := newPageQueue()
 	totalChars := 0
 
 	for res := range results {
-		if res.err != nil && p.cfg.ParsingMode == Strict {
-			logger.Debug(fmt.Sprintf("Strict mode error — stopping streaming: page=%d err=%v", res.index, res.err), true)
+		if res.err != nil && (p.cfg.ParsingMode == Strict || errors.Is(res.err, ErrLimitExceeded)) {
+			logger.Debug(fmt.Sprintf("Page error — stopping streaming: page=%d err=%v", res.index, res.err), true)
 			return false
 		}
-		pageBuffer[res.index] = res.text
+		queue.add(res.index, res.text)
 
 		// Emit pages in-order
 		for {
-			text, ok := pageBuffer[nextPage]
-			if !ok || text == "" {
+			nextPage, text, ok := queue.next()
+			if !ok {
 				break
 			}
 
@@ -286,15 +512,45 @@ func (p *processor) streamInOrder(results chan pageResult, outCh chan string) (t
 				outCh <- text
 				totalChars += len(text)
 			}
-
-			delete(pageBuffer, nextPage)
-			nextPage++
 		}
 	}
 
 	return truncated
 }
 
+// pageQueue holds the text of pages extracted out of order until it can
+// be emitted in page order.
+type pageQueue struct {
+	pages map[int]string
+	num   int // number of the next page to emit
+}
+
+func newPageQueue() *pageQueue {
+	return &pageQueue{pages: make(map[int]string), num: 1}
+}
+
+// add adds the text of page num.
+func (q *pageQueue) add(num int, text string) {
+	q.pages[num] = text
+}
+
+// next removes and returns the text of the next page, if it has been
+// added. Pages without text are skipped, so that they do not hold back
+// the pages after them.
+func (q *pageQueue) next() (num int, text string, ok bool) {
+	for {
+		text, ok := q.pages[q.num]
+		if !ok {
+			return 0, "", false
+		}
+		delete(q.pages, q.num)
+		q.num++
+		if text != "" {
+			return q.num - 1, text, true
+		}
+	}
+}
+
 func (p *processor) acquireSlot(ctx context.Context) error {
 	if err := p.sem.Acquire(ctx, 1); err != nil {
 		return fmt.Errorf("acquire slot: %w", err)
@@ -315,9 +571,12 @@ func (p *processor) adjustWorkerCount(maxWorkers int) int {
 }
 
 type pageResult struct {
-	index int
-	text  string
-	err   error
+	index    int
+	text     string
+	err      error
+	duration time.Duration
+	retries  int
+	fonts    []string
 }
 
 func (p *processor) startWorkers(ctx context.Context, r Reader, jobs <-chan int, results chan<- pageResult, numWorkers int, wg *sync.WaitGroup) {
@@ -328,15 +587,25 @@ func (p *processor) startWorkers(ctx context.Context, r Reader, jobs <-chan int,
 			defer wg.Done()
 			logger.Debug(fmt.Sprintf("Worker started: id=%d", id), true)
 			for i := range jobs {
-				page := r.Page(i)
+				page, err := r.page(i)
+				if err != nil {
+					logger.Debug(fmt.Sprintf("Page not found: index=%d err=%v", i, err), true)
+					results <- pageResult{index: i, err: err}
+					continue
+				}
 				if page.V.IsNull() {
 					logger.Debug(fmt.Sprintf("Null page encountered: index=%d", i), true)
-					results <- pageResult{i, "", fmt.Errorf("null page")}
+					results <- pageResult{index: i, err: fmt.Errorf("null page")}
 					continue
 				}
 
-				text, err := p.extractPageWithRetries(ctx, &page)
-				results <- pageResult{i, text, err}
+				start := time.Now()
+				text, retries, err := p.extractPage(ctx, &page, i)
+				res := pageResult{index: i, text: text, err: err, duration: time.Since(start), retries: retries}
+				if p.fonts {
+					res.fonts = pageFonts(page)
+				}
+				results <- res
 				if err != nil {
 					logger.Debug(fmt.Sprintf("Worker: page extraction error: worker_id=%d page=%d err=%v", id, i, err), true)
 				} else {
@@ -348,19 +617,69 @@ func (p *processor) startWorkers(ctx context.Context, r Reader, jobs <-chan int,
 	}
 }
 
-func (p *processor) extractPageWithRetries(ctx context.Context, page *Page) (string, error) {
+// extractPage extracts the text of page number num, falling back to the
+// configured OCRProvider when the page has too little text. A page that
+// cannot be parsed is reported as its error. extractPage also returns the
+// number of times the page was retried.
+func (p *processor) extractPage(ctx context.Context, page *Page, num int) (text string, retries int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error(fmt.Sprintf("page %d: %v", num, r))
+			text, err = "", recoveredError(r)
+		}
+	}()
+
+	text, retries, err = p.extractPageWithRetries(ctx, page)
+	if err != nil || p.cfg.OCR == nil || !p.needsOCR(text) {
+		return text, retries, err
+	}
+	images, err := page.Images()
+	if err != nil || len(images) == 0 {
+		logger.Debug(fmt.Sprintf("OCR skipped: page=%d images=%d err=%v", num, len(images), err), true)
+		return text, retries, nil
+	}
+	ocrPage := OCRPage{Number: num, MediaBox: rectValue(page.findInherited("MediaBox")), Text: text, Images: images}
+	ocr, err := p.cfg.OCR.RecognizePage(ctx, ocrPage)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("OCR failed: page=%d err=%v", num, err), true)
+		if p.cfg.ParsingMode == Strict {
+			return "", retries, fmt.Errorf("OCR: %w", err)
+		}
+		return text, retries, nil
+	}
+	logger.Debug(fmt.Sprintf("OCR recognized text: page=%d images=%d chars=%d", num, len(images), len(ocr)), true)
+	if strings.TrimSpace(text) == "" {
+		return ocr, retries, nil
+	}
+	return text + "\n" + ocr, retries, nil
+}
+
+// needsOCR reports whether text has fewer than Config.OCRMinChars
+// non-space characters, or none at all.
+func (p *processor) needsOCR(text string) bool {
+	n := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+	return n == 0 || n < p.cfg.OCRMinChars
+}
+
+func (p *processor) extractPageWithRetries(ctx context.Context, page *Page) (string, int, error) {
 	var text string
 	var err error
-	for attempt := 0; attempt <= p.cfg.MaxRetries; attempt++ {
+	attempt := 0
+	for ; attempt <= p.cfg.MaxRetries; attempt++ {
 		ctxPage, cancel := context.WithTimeout(ctx, p.cfg.WorkerTimeout)
 		text, err = p.extractor.ExtractPage(ctxPage, page)
 		cancel()
-		if err == nil {
+		if err == nil || errors.Is(err, ErrLimitExceeded) || ctx.Err() != nil {
 			break
 		}
 		logger.Debug(fmt.Sprintf("Retrying page extraction: attempt=%d err=%v", attempt, err), true)
 	}
-	return text, err
+	return text, min(attempt, p.cfg.MaxRetries), err
 }
 
 func (p *processor) feedJobs(ctx context.Context, total int, jobs chan<- int) error {
@@ -377,6 +696,18 @@ func (p *processor) feedJobs(ctx context.Context, total int, jobs chan<- int) er
 	return nil
 }
 
+// pageText extracts the text of a page using the given text mode.
+func pageText(page *Page, mode TextMode) (string, error) {
+	switch mode {
+	case LayoutText:
+		return page.GetLayoutText()
+	case ColumnText:
+		return page.GetColumnText()
+	default:
+		return page.GetPlainText(cacheFonts(page))
+	}
+}
+
 // cacheFonts creates a one-time map of fonts for a page to avoid
 // repeatedly parsing font charmaps.
 func cacheFonts(page *Page) map[string]*Font {
@@ -393,18 +724,43 @@ func cacheFonts(page *Page) map[string]*Font {
 
 // Metadata prints PDF metadata as JSON to the provided writer
 func (p *processor) Metadata(ctx context.Context, path string, w io.Writer) error {
+	return p.metadata(ctx, fileSource(path), w)
+}
+
+// MetadataReaderAt is like Metadata, but reads the size bytes of the
+// document from ra.
+func (p *processor) MetadataReaderAt(ctx context.Context, ra io.ReaderAt, size int64, w io.Writer) error {
+	return p.metadata(ctx, readerAtSource("reader", ra, size), w)
+}
+
+// MetadataBytes is like Metadata, but reads the document from data.
+func (p *processor) MetadataBytes(ctx context.Context, data []byte, w io.Writer) error {
+	return p.metadata(ctx, readerAtSource("bytes", bytes.NewReader(data), int64(len(data))), w)
+}
+
+// MetadataReader is like Metadata, but reads the document from rd as
+// ExtractReader does.
+func (p *processor) MetadataReader(ctx context.Context, rd io.Reader, w io.Writer) error {
+	return p.metadata(ctx, p.readerSource(rd), w)
+}
+
+// metadata prints the metadata of the document src as JSON to w.
+func (p *processor) metadata(ctx context.Context, src source, w io.Writer) error {
+	path := src.name
 	logger.Debug(fmt.Sprintf("Reading metadata: path=%s", path), true)
 
-	_, r, err := Open(path)
+	if err := p.acquireSlot(ctx); err != nil {
+		logger.Debug(fmt.Sprintf("Failed to acquire slot for metadata: err=%v", err), true)
+		return err
+	}
+	defer p.sem.Release(1)
+
+	r, release, err := src.open(ctx, p.readerOptions())
 	if err != nil {
 		logger.Error("failed to open PDF for metadata:")
 		return err
 	}
-	defer func() {
-		if closer, ok := r.f.(io.Closer); ok {
-			_ = closer.Close()
-		}
-	}()
+	defer release()
 	if err := r.MetadataJSON(w); err != nil {
 		logger.Error("failed to read metadata")
 		return err
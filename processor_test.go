@@ -4,12 +4,21 @@
 package xtract
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -115,6 +124,448 @@ func TestProcessor_Extract(t *testing.T) {
 	}
 }
 
+// processor.Extract on an encrypted document using Config.Password
+func TestProcessor_Extract_Password(t *testing.T) {
+	e := newTestEncrypter(t, 4, 4, 128, "AESV2", "tenant-pw", "owner-pw")
+	path, cleanup := writeTempFile(t, string(e.pdf("AESV2")))
+	defer cleanup()
+	ctx := context.Background()
+
+	proc := newTestProcessor(BestEffort)
+	_, _, err := proc.Extract(ctx, path)
+	assert.ErrorIs(t, err, ErrPasswordRequired)
+
+	cfg := NewDefaultConfig()
+	cfg.Password = "wrong"
+	_, _, err = NewProcessor(cfg).Extract(ctx, path)
+	assert.ErrorIs(t, err, ErrBadPassword)
+
+	cfg.Password = "tenant-pw"
+	text, _, err := NewProcessor(cfg).Extract(ctx, path)
+	require.NoError(t, err)
+	assert.Contains(t, text, "Secret text")
+}
+
+// processor.Extract with a page that outlives Config.WorkerTimeout
+func TestProcessor_Extract_Timeout(t *testing.T) {
+	path, cleanup := writeTempFile(t, string(errorTestPDF("BT /F1 12 Tf 72 700 Td (hello) Tj ET")))
+	defer cleanup()
+	ctx := context.Background()
+
+	cfg := NewDefaultConfig()
+	cfg.ParsingMode = Strict
+	cfg.MaxRetries = 0
+	cfg.WorkerTimeout = time.Nanosecond
+	_, _, err := NewProcessor(cfg).Extract(ctx, path)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	ex := &BestEffortExtractor{}
+	page := loadPage(t, path)
+	expired, cancel := context.WithTimeout(ctx, -time.Second)
+	defer cancel()
+	_, err = ex.ExtractPage(expired, page)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	text, err := ex.ExtractPage(ctx, page)
+	require.NoError(t, err)
+	assert.Contains(t, text, "hello")
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, _, err = newTestProcessor(BestEffort).Extract(cancelled, path)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// onlyReader hides all but the Read method of its reader, as a network
+// stream would.
+type onlyReader struct{ io.Reader }
+
+// pagesPDF returns a document with a page for each content stream.
+func pagesPDF(contents []string) []byte {
+	objs := []string{"<< /Type /Catalog /Pages 2 0 R >>", ""}
+	var kids []string
+	for i, content := range contents {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 3+2*i))
+		objs = append(objs,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents %d 0 R >>", 4+2*i),
+			streamObj("", []byte(content)))
+	}
+	objs[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(contents))
+	return buildPDF(objs, "")
+}
+
+// lateReaderAt counts the reads made after done is set.
+type lateReaderAt struct {
+	io.ReaderAt
+	done atomic.Bool
+	late atomic.Int64
+}
+
+func (r *lateReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if r.done.Load() {
+		r.late.Add(1)
+	}
+	return r.ReaderAt.ReadAt(p, off)
+}
+
+// processor.Extract and ExtractPages stopping at the first page
+func TestProcessor_StopsWorkers(t *testing.T) {
+	contents := []string{"q q q q q q Q Q Q Q Q Q"}
+	for len(contents) < 50 {
+		contents = append(contents, "BT ET")
+	}
+	data := pagesPDF(contents)
+
+	cfg := NewDefaultConfig()
+	cfg.Limits.MaxOperators = 5
+	proc := NewProcessor(cfg)
+	ctx := context.Background()
+
+	// the workers are done with the document when the extraction returns
+	ra := &lateReaderAt{ReaderAt: bytes.NewReader(data)}
+	_, _, err := proc.ExtractReaderAt(ctx, ra, int64(len(data)))
+	assertLimit(t, err, "MaxOperators")
+	ra.done.Store(true)
+
+	ra2 := &lateReaderAt{ReaderAt: bytes.NewReader(data)}
+	_, err = proc.extractPages(ctx, readerAtSource("reader", ra2, int64(len(data))))
+	assertLimit(t, err, "MaxOperators")
+	ra2.done.Store(true)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Zero(t, ra.late.Load(), "Extract")
+	assert.Zero(t, ra2.late.Load(), "ExtractPages")
+}
+
+// processor.ExtractBytes, ExtractReaderAt and ExtractReader
+func TestProcessor_ExtractReader(t *testing.T) {
+	data := errorTestPDF("BT /F1 12 Tf 72 700 Td (hello) Tj ET")
+	ctx := context.Background()
+	proc := newTestProcessor(BestEffort)
+
+	text, _, err := proc.ExtractBytes(ctx, data)
+	require.NoError(t, err)
+	assert.Contains(t, text, "hello")
+
+	text, _, err = proc.ExtractReaderAt(ctx, bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	assert.Contains(t, text, "hello")
+
+	_, _, err = proc.ExtractBytes(ctx, []byte("not a pdf"))
+	assert.ErrorIs(t, err, ErrNotPDF)
+
+	stream, _, err := proc.ExtractAsStreamBytes(ctx, data)
+	require.NoError(t, err)
+	var streamed strings.Builder
+	for s := range stream {
+		streamed.WriteString(s)
+	}
+	assert.Contains(t, streamed.String(), "hello")
+
+	var meta bytes.Buffer
+	require.NoError(t, proc.MetadataBytes(ctx, data, &meta))
+	assert.Contains(t, meta.String(), "{")
+	assert.ErrorIs(t, proc.MetadataReaderAt(ctx, bytes.NewReader(nil), 0, &meta), ErrNotPDF)
+
+	// spooled to memory, then to a temporary file that is removed
+	dir := t.TempDir()
+	for _, threshold := range []int64{32 << 20, 0, int64(len(data)) - 1, int64(len(data))} {
+		cfg := NewDefaultConfig()
+		cfg.SpoolThreshold = threshold
+		cfg.SpoolDir = dir
+		proc := NewProcessor(cfg)
+		text, _, err := proc.ExtractReader(ctx, onlyReader{bytes.NewReader(data)})
+		require.NoError(t, err, "threshold %d", threshold)
+		assert.Contains(t, text, "hello")
+
+		stream, _, err := proc.ExtractAsStreamReader(ctx, onlyReader{bytes.NewReader(data)})
+		require.NoError(t, err, "threshold %d", threshold)
+		streamed.Reset()
+		for s := range stream {
+			streamed.WriteString(s)
+		}
+		assert.Contains(t, streamed.String(), "hello")
+
+		meta.Reset()
+		require.NoError(t, proc.MetadataReader(ctx, onlyReader{bytes.NewReader(data)}, &meta), "threshold %d", threshold)
+		assert.Contains(t, meta.String(), "{")
+
+		left, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, left, "threshold %d", threshold)
+	}
+
+	// the reader is spooled only once a slot is acquired, and holds it
+	cfg := NewDefaultConfig()
+	cfg.MaxConcurrentPDFs = 1
+	proc = NewProcessor(cfg)
+	pr, pw := io.Pipe()
+	done := make(chan error)
+	go func() {
+		text, _, err := proc.ExtractReader(ctx, onlyReader{pr})
+		if err == nil && !strings.Contains(text, "hello") {
+			err = fmt.Errorf("text %q", text)
+		}
+		done <- err
+	}()
+	_, err = pw.Write(data[:10])
+	require.NoError(t, err)
+	short, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, _, err = proc.ExtractBytes(short, data)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = pw.Write(data[10:])
+	require.NoError(t, err)
+	pw.Close()
+	require.NoError(t, <-done)
+}
+
+func TestProcessor_Spool(t *testing.T) {
+	data := errorTestPDF("BT /F1 12 Tf 72 700 Td (hello) Tj ET")
+	ctx := context.Background()
+	dir := t.TempDir()
+	cfg := NewDefaultConfig()
+	cfg.SpoolDir = dir
+
+	for _, threshold := range []int64{int64(len(data)), int64(len(data)) - 1} {
+		cfg.SpoolThreshold = threshold
+		ra, size, release, err := NewProcessor(cfg).spool(ctx, onlyReader{bytes.NewReader(data)})
+		require.NoError(t, err, "threshold %d", threshold)
+		require.Equal(t, int64(len(data)), size)
+		got := make([]byte, size)
+		_, err = ra.ReadAt(got, 0)
+		require.NoError(t, err)
+		assert.Equal(t, data, got)
+
+		// only documents above the threshold are written to a file
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		if threshold < size {
+			require.Len(t, files, 1)
+			assert.True(t, strings.HasPrefix(files[0].Name(), "pdf-xtract-"), files[0].Name())
+		} else {
+			assert.Empty(t, files)
+		}
+
+		release()
+		files, err = os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, files, "threshold %d", threshold)
+	}
+}
+
+// processor.ExtractPages reporting the page that fails
+func TestProcessor_ExtractPages(t *testing.T) {
+	objs := recoverTestObjs()
+	objs[5] = streamObj("/Filter /JBIG2Decode", []byte("BT /F1 12 Tf 72 700 Td (second) Tj ET"))
+	path, cleanup := writeTempFile(t, string(buildPDF(objs, "")))
+	defer cleanup()
+	ctx := context.Background()
+
+	for _, mode := range []ParsingMode{Strict, BestEffort} {
+		cfg := NewDefaultConfig()
+		cfg.ParsingMode = mode
+		cfg.MaxRetries = 1
+		doc, err := NewProcessor(cfg).ExtractPages(ctx, path)
+		require.NoError(t, err, mode)
+		require.Len(t, doc.Pages, 2)
+		assert.True(t, doc.Partial())
+		assert.Equal(t, 1, doc.FailedPages)
+
+		first, second := doc.Pages[0], doc.Pages[1]
+		assert.Equal(t, 1, first.Number)
+		assert.NoError(t, first.Err)
+		assert.Contains(t, first.Text, "first")
+		assert.Equal(t, utf8.RuneCountInString(first.Text), first.CharCount)
+		assert.Equal(t, []string{"Helvetica"}, first.FontsUsed)
+		assert.Zero(t, first.Retries)
+
+		assert.Equal(t, 2, second.Number)
+		assert.ErrorIs(t, second.Err, ErrUnsupportedFilter)
+		assert.Empty(t, second.Text)
+		assert.Equal(t, 1, second.Retries)
+		assert.Equal(t, first.CharCount, doc.CharCount)
+		assert.Equal(t, 1, doc.Retries)
+		assert.False(t, doc.Truncated)
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.MaxTotalChars = 3
+	doc, err := NewProcessor(cfg).ExtractPages(ctx, path)
+	require.NoError(t, err)
+	assert.True(t, doc.Truncated)
+	assert.True(t, doc.Pages[0].Truncated)
+	assert.Equal(t, 3, doc.Pages[0].CharCount)
+
+	// the text is cut at a rune boundary, and the cut stops the later pages
+	objs = recoverTestObjs()
+	objs[4] = streamObj("", []byte(`BT /F1 12 Tf 72 700 Td (\351\351\351) Tj ET`))
+	objs[6] = "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"
+	accented, cleanup := writeTempFile(t, string(buildPDF(objs, "")))
+	defer cleanup()
+	cfg.MaxTotalChars = 4 // within the second é
+	doc, err = NewProcessor(cfg).ExtractPages(ctx, accented)
+	require.NoError(t, err)
+	assert.Equal(t, "\né", doc.Pages[0].Text)
+	assert.Empty(t, doc.Pages[1].Text)
+	assert.True(t, doc.Pages[1].Truncated)
+
+	cfg = NewDefaultConfig()
+	cfg.Limits.MaxOperators = 2
+	_, err = NewProcessor(cfg).ExtractPages(ctx, path)
+	assertLimit(t, err, "MaxOperators")
+}
+
+// processor.Extract appending XFA form data
+func TestProcessor_Extract_AppendXFA(t *testing.T) {
+	data := xfaTestPDF("[(datasets) 6 0 R]", xfaTestDatasets)
+	path, cleanup := writeTempFile(t, string(data))
+	defer cleanup()
+	ctx := context.Background()
+
+	cfg := NewDefaultConfig()
+	text, _, err := NewProcessor(cfg).Extract(ctx, path)
+	require.NoError(t, err)
+	assert.NotContains(t, text, "Jane Doe")
+
+	cfg.AppendXFA = true
+	text, truncated, err := NewProcessor(cfg).Extract(ctx, path)
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	assert.Contains(t, text, "Please wait...")
+	assert.Contains(t, text, "form1.applicant.name: Jane Doe\nform1.applicant.ssn: 123-45-6789\n")
+
+	cfg.MaxTotalChars = len(text) - 5
+	text, truncated, err = NewProcessor(cfg).Extract(ctx, path)
+	require.NoError(t, err)
+	assert.True(t, truncated)
+	assert.Len(t, text, cfg.MaxTotalChars)
+}
+
+// processor.Extract following attached PDFs, including a portfolio inside
+// a portfolio
+func TestProcessor_Extract_Attachments(t *testing.T) {
+	inner := layoutTestPDF(layoutFont, "BT /F1 10 Tf 72 700 Td (Inner text) Tj ET")
+	data := portfolioPDF("Outer cover", portfolioPDF("Middle cover", inner))
+	path, cleanup := writeTempFile(t, string(data))
+	defer cleanup()
+	ctx := context.Background()
+
+	cfg := NewDefaultConfig()
+	text, _, err := NewProcessor(cfg).Extract(ctx, path)
+	require.NoError(t, err)
+	assert.NotContains(t, text, "Middle cover")
+
+	cfg.ExtractAttachments = true
+	text, truncated, err := NewProcessor(cfg).Extract(ctx, path)
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	assert.Contains(t, text, "Outer cover")
+	assert.Contains(t, text, "--- attachment: répé.pdf ---\n")
+	assert.Contains(t, text, "Middle cover")
+	assert.Contains(t, text, "Inner text")
+	assert.NotContains(t, text, "remember the milk")
+
+	cfg.MaxTotalChars = len(text) - 3
+	text, truncated, err = NewProcessor(cfg).Extract(ctx, path)
+	require.NoError(t, err)
+	assert.True(t, truncated)
+	assert.Len(t, text, cfg.MaxTotalChars)
+
+	// an attachment counts against the objects its document has left: the
+	// limit is enough for the attached PDF on its own, and for the
+	// document without it
+	var contents []string
+	for i := 1; i <= 20; i++ {
+		contents = append(contents, fmt.Sprintf("BT (page %d) Tj ET", i))
+	}
+	attached := pagesPDF(contents)
+	attachedPath, cleanup := writeTempFile(t, string(attached))
+	defer cleanup()
+	cfg = NewDefaultConfig()
+	cfg.ExtractAttachments = true
+	for cfg.Limits.MaxObjects = 1; ; cfg.Limits.MaxObjects++ {
+		require.Less(t, cfg.Limits.MaxObjects, int64(1000))
+		if text, _, err := NewProcessor(cfg).Extract(ctx, attachedPath); err == nil && strings.Contains(text, "page 20") {
+			break
+		}
+	}
+	path, cleanup = writeTempFile(t, string(portfolioPDF("Outer cover", attached)))
+	defer cleanup()
+	_, _, err = NewProcessor(cfg).Extract(ctx, path)
+	assertLimit(t, err, "MaxObjects")
+	cfg.ExtractAttachments = false
+	text, _, err = NewProcessor(cfg).Extract(ctx, path)
+	require.NoError(t, err)
+	assert.Contains(t, text, "Outer cover")
+}
+
+// fakeOCR records the pages it is asked to recognize.
+type fakeOCR struct {
+	mu    sync.Mutex
+	pages []OCRPage
+	err   error
+}
+
+func (f *fakeOCR) RecognizePage(ctx context.Context, page OCRPage) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pages = append(f.pages, page)
+	if f.err != nil {
+		return "", f.err
+	}
+	return fmt.Sprintf("ocr text of page %d (%d images)", page.Number, len(page.Images)), nil
+}
+
+// processor.Extract with an OCR provider for pages without enough text
+func TestProcessor_Extract_OCR(t *testing.T) {
+	scan := "q 612 0 0 792 0 0 cm BI /W 1 /H 1 /CS /G /BPC 8 ID \x80 EI Q"
+	data := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 6 0 R /Resources << /Font << /F1 9 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 7 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 8 0 R /Resources << /Font << /F1 9 0 R >> >> >>",
+		streamObj("", []byte("BT /F1 10 Tf 72 700 Td (A page of real text) Tj ET")),
+		streamObj("", []byte(scan)),
+		streamObj("", []byte(scan+" BT /F1 10 Tf 72 20 Td (p. 3) Tj ET")),
+		layoutFont,
+	}, "")
+	path, cleanup := writeTempFile(t, string(data))
+	defer cleanup()
+	ctx := context.Background()
+
+	ocr := &fakeOCR{}
+	cfg := NewDefaultConfig()
+	cfg.OCR = ocr
+	cfg.OCRMinChars = 5
+	require.NoError(t, cfg.Validate())
+	text, _, err := NewProcessor(cfg).Extract(ctx, path)
+	require.NoError(t, err)
+
+	require.Len(t, ocr.pages, 2)
+	sort.Slice(ocr.pages, func(i, j int) bool { return ocr.pages[i].Number < ocr.pages[j].Number })
+	assert.Equal(t, 2, ocr.pages[0].Number)
+	assert.Equal(t, Rect{Point{0, 0}, Point{612, 792}}, ocr.pages[0].MediaBox)
+	require.Len(t, ocr.pages[0].Images, 1)
+	assert.Equal(t, Rect{Point{0, 0}, Point{612, 792}}, ocr.pages[0].Images[0].Rect)
+	assert.Contains(t, ocr.pages[1].Text, "p. 3")
+
+	first := strings.Index(text, "A page of real text")
+	second := strings.Index(text, "ocr text of page 2 (1 images)")
+	third := strings.Index(text, "ocr text of page 3 (1 images)")
+	assert.True(t, first >= 0 && second > first && third > second, "pages out of order: %q", text)
+	assert.Contains(t, text, "p. 3")
+
+	// OCR failures keep the page text in best-effort mode and fail in strict mode
+	cfg.OCR = &fakeOCR{err: errors.New("engine down")}
+	text, _, err = NewProcessor(cfg).Extract(ctx, path)
+	require.NoError(t, err)
+	assert.Contains(t, text, "p. 3")
+	cfg.ParsingMode = Strict
+	_, _, err = NewProcessor(cfg).Extract(ctx, path)
+	assert.ErrorContains(t, err, "engine down")
+}
+
 // processor.Extract with truncation
 func TestProcessor_Extract_Truncation(t *testing.T) {
 	pdfs := getSamplePDFs(t)
@@ -294,6 +745,33 @@ func TestStreamInOrder_PartialTruncation(t *testing.T) {
 	assert.Equal(t, "ABC", out, "expected partial truncation output")
 }
 
+func TestInOrder_EmptyPages(t *testing.T) {
+	proc := newTestProcessor(BestEffort)
+	send := func() chan pageResult {
+		results := make(chan pageResult, 4)
+		results <- pageResult{index: 3, text: "C"}
+		results <- pageResult{index: 1, text: ""}
+		results <- pageResult{index: 2, text: "B"}
+		results <- pageResult{index: 4, text: ""}
+		close(results)
+		return results
+	}
+
+	out, truncated, err := proc.emitInOrder(send())
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	assert.Equal(t, "BC", out.String(), "pages without text must not hold back the pages after them")
+
+	outCh := make(chan string, 4)
+	proc.streamInOrder(send(), outCh)
+	close(outCh)
+	var streamed []string
+	for s := range outCh {
+		streamed = append(streamed, s)
+	}
+	assert.Equal(t, []string{"B", "C"}, streamed)
+}
+
 func TestAdjustWorkerCount(t *testing.T) {
 	proc := &processor{}
 
@@ -4,7 +4,10 @@
 package xtract
 
 import (
+	"context"
 	"io"
+
+	"github.com/sassoftware/pdf-xtract/logger"
 )
 
 // A Stack represents a stack of values.
@@ -41,6 +44,8 @@ func newDict() Value {
 // to implement op.
 //
 // Interpret handles the operators "dict", "currentdict", "begin", "end", "def", and "pop" itself.
+// An inline image (BI ... ID ... EI) is read as a whole and passed to do as
+// a stream value with the operator "EI".
 //
 // Interpret is not a full-blown PostScript interpreter. Its job is to handle the
 // very limited PostScript found in certain supporting file formats embedded
@@ -51,7 +56,29 @@ func newDict() Value {
 // In the case of a simple stream read only once, otherwise get the length of the stream to handle it properly
 //
 // There is no support for executable blocks, among other limitations.
-func Interpret(strm Value, do func(stk *Stack, op string)) {
+// Unbalanced dictionary operators are skipped. Interpret stops at content
+// that cannot be parsed and returns an *ObjectError, or a *LimitError if
+// the stream exceeds the Limits of its Reader. A panic in do stops
+// Interpret too and is returned as its error.
+func Interpret(strm Value, do func(stk *Stack, op string)) error {
+	return InterpretContext(context.Background(), strm, do)
+}
+
+// InterpretContext is like Interpret, but stops when ctx is done, between
+// two tokens or while the stream is read and decoded, and returns ctx.Err().
+func InterpretContext(ctx context.Context, strm Value, do func(stk *Stack, op string)) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			// a done context stops nested interpreters with a panic
+			if ctxErr := ctx.Err(); ctxErr != nil && e == ctxErr {
+				err = ctxErr
+				return
+			}
+			err = recoveredError(e)
+		}
+	}()
+
+	done := ctx.Done()
 	var stk Stack
 	var dicts []dict
 	s := strm
@@ -65,7 +92,7 @@ func Interpret(strm Value, do func(stk *Stack, op string)) {
 			s = strm.Index(i)
 		}
 
-		rd := s.Reader()
+		rd := s.ReaderContext(ctx)
 
 		b := newBuffer(rd, 0)
 		b.allowEOF = true
@@ -74,6 +101,11 @@ func Interpret(strm Value, do func(stk *Stack, op string)) {
 
 	Reading:
 		for {
+			select {
+			case <-done:
+				return ctx.Err()
+			default:
+			}
 			tok := b.readToken()
 			if tok == io.EOF {
 				break
@@ -97,26 +129,33 @@ func Interpret(strm Value, do func(stk *Stack, op string)) {
 					continue
 				case "currentdict":
 					if len(dicts) == 0 {
-						panic("no current dictionary")
+						logger.Debug("Interpret: currentdict without current dictionary")
+						stk.Push(newDict())
+						continue
 					}
 					stk.Push(Value{nil, objptr{}, dicts[len(dicts)-1]})
 					continue
 				case "begin":
 					d := stk.Pop()
 					if d.Kind() != Dict {
-						panic("cannot begin non-dict")
+						logger.Debug("Interpret: cannot begin non-dict")
+						d = newDict()
 					}
 					dicts = append(dicts, d.data.(dict))
 					continue
 				case "end":
 					if len(dicts) <= 0 {
-						panic("mismatched begin/end")
+						logger.Debug("Interpret: mismatched begin/end")
+						continue
 					}
 					dicts = dicts[:len(dicts)-1]
 					continue
 				case "def":
 					if len(dicts) <= 0 {
-						panic("def without open dict")
+						logger.Debug("Interpret: def without open dict")
+						stk.Pop()
+						stk.Pop()
+						continue
 					}
 					val := stk.Pop()
 					key, ok := stk.Pop().data.(name)
@@ -130,6 +169,11 @@ func Interpret(strm Value, do func(stk *Stack, op string)) {
 				case "pop":
 					stk.Pop()
 					continue
+				case "BI":
+					// the image is limited like the stream it is in
+					stk.Push(Value{s.r, objptr{}, b.readInlineImage()})
+					do(&stk, "EI")
+					continue
 				}
 			}
 			b.unreadToken(tok)
@@ -137,4 +181,5 @@ func Interpret(strm Value, do func(stk *Stack, op string)) {
 			stk.Push(Value{nil, objptr{}, obj})
 		}
 	}
+	return nil
 }
@@ -5,9 +5,14 @@ package xtract
 
 import (
 	"bytes"
+	"context"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStack(t *testing.T) {
@@ -35,3 +40,47 @@ func TestBuffer_seekForward(t *testing.T) {
 	assert.True(t, b.offset >= 5)
 	assert.True(t, b.pos >= 0)
 }
+
+func TestInterpretContext(t *testing.T) {
+	content := []byte(strings.Repeat("q Q ", 1000))
+	strm := Value{&Reader{f: bytes.NewReader(content), end: int64(len(content))}, objptr{}, stream{hdr: dict{name("Length"): int64(len(content))}}}
+
+	ops := 0
+	require.NoError(t, InterpretContext(context.Background(), strm, func(*Stack, string) { ops++ }))
+	assert.Equal(t, 2000, ops)
+
+	// cancelled while interpreting
+	ctx, cancel := context.WithCancel(context.Background())
+	ops = 0
+	err := InterpretContext(ctx, strm, func(_ *Stack, op string) {
+		if ops++; ops == 10 {
+			cancel()
+		}
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 10, ops)
+
+	// cancelled before the stream is read
+	_, err = io.ReadAll(strm.ReaderContext(ctx))
+	assert.ErrorIs(t, err, context.Canceled)
+	ops = 0
+	assert.ErrorIs(t, InterpretContext(ctx, strm, func(*Stack, string) { ops++ }), context.Canceled)
+	assert.Zero(t, ops)
+}
+
+func TestPage_WithContext(t *testing.T) {
+	data := errorTestPDF("BT /F1 12 Tf 72 700 Td (hello) Tj ET")
+	r := newTestReader(t, data)
+
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	page := r.Page(1).WithContext(ctx)
+	_, err := page.GetPlainText(nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = page.GetLayoutText()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	text, err := r.Page(1).WithContext(context.Background()).GetPlainText(nil)
+	require.NoError(t, err)
+	assert.Contains(t, text, "hello")
+}
@@ -53,16 +53,14 @@ package xtract
 // BUG(rsc): The library makes no attempt at efficiency. A value cache maintained in the Reader
 // would probably help significantly.
 
-// BUG(rsc): The support for reading encrypted files is weak.
-
 // BUG(rsc): The Value API does not support error reporting. The intent is to allow users to
 // set an error reporting callback in Reader, but that code has not been implemented.
 
 import (
 	"bytes"
 	"compress/zlib"
+	"context"
 	"encoding/ascii85"
-	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -71,6 +69,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/sassoftware/pdf-xtract/logger"
 )
@@ -80,13 +79,24 @@ var DebugOn = false
 
 // A Reader is a single PDF file open for reading.
 type Reader struct {
-	f          io.ReaderAt
-	end        int64
-	xref       []xref
-	trailer    dict
-	trailerptr objptr
-	key        []byte
-	useAES     bool
+	f               io.ReaderAt
+	end             int64
+	xref            []xref
+	trailer         dict
+	trailerptr      objptr
+	key             []byte
+	encryptptr      objptr            // the /Encrypt dictionary, which is never itself encrypted
+	strMethod       string            // crypt filter method for strings (/StrF)
+	stmMethod       string            // crypt filter method for streams (/StmF)
+	cryptFilters    map[string]string // crypt filter methods by name, for /Crypt stream filters
+	encryptMetadata bool
+
+	fontPrograms *sync.Map       // parsed embedded font programs by stream, shared by copies of the Reader
+	recovered    bool            // the xref table was rebuilt by scanning the file
+	sparseXref   map[uint32]xref // recovered entries of object numbers far beyond len(xref)
+	limits       Limits
+	usage        *usage // resources used so far, shared by copies of the Reader
+	raise        bool   // malformed objects panic instead of reading as null; see raising
 }
 
 type xref struct {
@@ -96,7 +106,36 @@ type xref struct {
 	offset   int64
 }
 
+// ReaderOptions controls how NewReaderWithOptions opens a document.
+type ReaderOptions struct {
+	// Password is tried as the user password and then as the owner password
+	// of an encrypted document. The empty password is always tried first.
+	Password string
+	// Recover rebuilds the cross-reference table by scanning the whole
+	// file for objects when the %%EOF marker, startxref or xref data are
+	// missing or damaged, or do not lead to the document catalog.
+	Recover bool
+	// Limits bounds the resources the document may use. The zero Limits
+	// sets no limits.
+	Limits Limits
+
+	usage *usage // counts the resources used, if shared with another document
+}
+
+// Open opens the named file for reading.
+// Encrypted documents are opened with the empty user password.
 func Open(file string) (*os.File, *Reader, error) {
+	return openWithOptions(file, ReaderOptions{})
+}
+
+// OpenWithPassword opens the named file for reading, authenticating an
+// encrypted document with password as either the user or the owner password.
+// It returns ErrBadPassword if password does not open the document.
+func OpenWithPassword(file, password string) (*os.File, *Reader, error) {
+	return openWithOptions(file, ReaderOptions{Password: password})
+}
+
+func openWithOptions(file string, opts ReaderOptions) (*os.File, *Reader, error) {
 	logger.Debug("Open file", true)
 	f, err := os.Open(file)
 	if err != nil {
@@ -109,7 +148,7 @@ func Open(file string) (*os.File, *Reader, error) {
 		return nil, nil, err
 	}
 	logger.Debug(fmt.Sprintf("document: file:%s -- opened (size=%d)", file, fi.Size()), true)
-	reader, err := NewReader(f, fi.Size())
+	reader, err := NewReaderWithOptions(f, fi.Size(), opts)
 	if err != nil {
 		f.Close()
 		return nil, nil, err
@@ -118,36 +157,103 @@ func Open(file string) (*os.File, *Reader, error) {
 }
 
 // NewReader opens a file for reading, using the data in f with the given total size.
+// Encrypted documents are opened with the empty user password; if that fails,
+// NewReader returns ErrPasswordRequired.
 func NewReader(f io.ReaderAt, size int64) (*Reader, error) {
+	return NewReaderWithOptions(f, size, ReaderOptions{})
+}
+
+// NewReaderWithOptions opens a file for reading like NewReader, using opts
+// to authenticate encrypted documents.
+func NewReaderWithOptions(f io.ReaderAt, size int64, opts ReaderOptions) (_ *Reader, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			logger.Error(fmt.Sprint(e))
+			err = recoveredError(e)
+		}
+	}()
+
 	logger.Debug("Checking Header", true)
 	if err := CheckHeader(f); err != nil {
 		return nil, err
 	}
 
-	logger.Debug("Checking End of file Marker", true)
-	if err := ValidateEOFMarker(f, size); err != nil {
+	r := &Reader{f: f, end: size, fontPrograms: new(sync.Map), limits: opts.Limits, usage: opts.usage, raise: true}
+	if r.usage == nil {
+		r.usage = new(usage)
+	}
+	err = r.readXref()
+	if err != nil && opts.Recover {
+		logger.Debug(fmt.Sprintf("xref: cannot read cross-reference data (%v), rebuilding it", err), true)
+		err = r.rebuildXref()
+	}
+	if err != nil {
 		return nil, err
 	}
+	if err := r.openIfEncrypted(opts.Password); err != nil {
+		return nil, err
+	}
+	if opts.Recover && !r.recovered && !r.hasCatalog() {
+		logger.Debug("xref: document catalog not found, rebuilding cross-reference data", true)
+		if err := r.rebuildXref(); err != nil {
+			return nil, err
+		}
+		if err := r.openIfEncrypted(opts.Password); err != nil {
+			return nil, err
+		}
+	}
+	if r.recovered {
+		r.completeRecovery()
+	}
+	if max := opts.Limits.MaxPages; max > 0 && r.NumPage() > max {
+		return nil, limitError("MaxPages", int64(max))
+	}
+	r.raise = false
+	return r, nil
+}
+
+// readXref reads the cross-reference data and trailer the file's
+// startxref points to.
+func (r *Reader) readXref() (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			logger.Error(fmt.Sprint(e))
+			err = fmt.Errorf("%w: %w", ErrBadXref, recoveredError(e))
+		}
+	}()
+
+	logger.Debug("Checking End of file Marker", true)
+	if err := ValidateEOFMarker(r.f, r.end); err != nil {
+		return err
+	}
 
 	logger.Debug("Checking Startxref", true)
-	startxref, err := FindStartXref(f, size)
+	startxref, err := FindStartXref(r.f, r.end)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	logger.Debug("Checking xref table + trailer", true)
-
-	r := &Reader{f: f, end: size}
 	b := newBuffer(io.NewSectionReader(r.f, startxref, r.end-startxref), startxref)
 	xref, trailerptr, trailer, err := readXref(r, b)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	r.xref = xref
 	r.trailer = trailer
 	r.trailerptr = trailerptr
+	return nil
+}
 
-	return r, nil
+// openIfEncrypted derives the file key of an encrypted document.
+func (r *Reader) openIfEncrypted(password string) error {
+	r.key = nil
+	r.encryptptr = objptr{}
+	if r.trailer[name("Encrypt")] == nil {
+		return nil
+	}
+	logger.Debug("Found /Encrypt, deriving file key", true)
+	return r.openEncrypted(password)
 }
 
 // CheckHeader validates the PDF header at the beginning of the file.
@@ -161,14 +267,14 @@ func CheckHeader(f io.ReaderAt) error {
 	}
 	if n == 0 {
 		logger.Error("not a PDF file: empty")
-		return errors.New("not a PDF file: empty")
+		return fmt.Errorf("%w: empty", ErrNotPDF)
 	}
 	buf = buf[:n]
 	// Find "%PDF-" possibly not at offset 0 (BOM or garbage before)
 	p := bytes.Index(buf, []byte("%PDF-"))
 	if p < 0 {
-		logger.Error("%PDF possibly not at offset 0 (BOM or garbage before)")
-		return err
+		logger.Error("not a PDF file: missing %PDF- header")
+		return fmt.Errorf("%w: missing %%PDF- header", ErrNotPDF)
 	}
 
 	// Slice from the header token forward
@@ -187,18 +293,18 @@ func CheckHeader(f io.ReaderAt) error {
 	// Parse %PDF-x.y (major.minor)
 	if !bytes.HasPrefix(line, []byte("%PDF-")) {
 		logger.Error("not a PDF file: invalid header (missing %%PDF-)")
-		return err
+		return fmt.Errorf("%w: invalid header", ErrNotPDF)
 	}
 	var major, minor int
 	if _, err := fmt.Sscanf(string(line), "%%PDF-%d.%d", &major, &minor); err != nil {
 		logger.Error("not a PDF file: malformed version")
-		return err
+		return fmt.Errorf("%w: malformed version %q", ErrNotPDF, line)
 	}
 
 	// Allow 1.0–1.7 and 2.0
 	if !((major == 1 && minor >= 0 && minor <= 7) || (major == 2 && minor == 0)) {
 		logger.Error(fmt.Sprintf("unsupported PDF version %d.%d", major, minor))
-		return err
+		return fmt.Errorf("%w: unsupported PDF version %d.%d", ErrNotPDF, major, minor)
 	}
 	// after successful parsing and version validation:
 	logger.Debug(fmt.Sprintf("header: PDF-%d.%d", major, minor), true)
@@ -209,17 +315,13 @@ func CheckHeader(f io.ReaderAt) error {
 // Ensures the PDF file is properly terminated as per the specification.
 func ValidateEOFMarker(f io.ReaderAt, size int64) error {
 	logger.Debug("checking for EOF")
-	end := size
 	const endChunk = 100
-	buf := make([]byte, endChunk)
-	f.ReadAt(buf, end-endChunk)
-	for len(buf) > 0 && buf[len(buf)-1] == '\n' || buf[len(buf)-1] == '\r' {
-		buf = buf[:len(buf)-1]
-	}
-	buf = bytes.TrimRight(buf, "\r\n\t ")
+	buf := make([]byte, min(endChunk, max(size, 0)))
+	n, _ := f.ReadAt(buf, size-int64(len(buf)))
+	buf = bytes.TrimRight(buf[:n], "\r\n\t\x00 ")
 	if !bytes.HasSuffix(buf, []byte("%%EOF")) {
 		logger.Error("not a PDF file: missing %%%%EOF")
-		return errors.New(" ")
+		return ErrMissingEOF
 	}
 	return nil
 }
@@ -228,27 +330,28 @@ func ValidateEOFMarker(f io.ReaderAt, size int64) error {
 // Returns the byte offset where the cross-reference table/stream begins.
 func FindStartXref(f io.ReaderAt, size int64) (int64, error) {
 	const endChunk = 100
-	buf := make([]byte, endChunk)
-	if _, err := f.ReadAt(buf, size-endChunk); err != nil && err != io.EOF {
+	buf := make([]byte, min(endChunk, max(size, 0)))
+	start := size - int64(len(buf))
+	if _, err := f.ReadAt(buf, start); err != nil && err != io.EOF {
 		return 0, err
 	}
 	i := findLastLine(buf, "startxref")
 	if i < 0 {
 		logger.Error("malformed PDF file: missing final startxref ")
-		return 0, errors.New(" ")
+		return 0, fmt.Errorf("%w: missing final startxref", ErrBadXref)
 	}
-	pos := size - endChunk + int64(i)
+	pos := start + int64(i)
 	b := newBuffer(io.NewSectionReader(f, pos, size-pos), pos)
 
 	tok := b.readToken()
 	if tok != keyword("startxref") {
 		logger.Error(fmt.Sprintf("malformed PDF file: missing startxref : %v", tok))
-		return 0, errors.New(" ")
+		return 0, fmt.Errorf("%w: missing startxref: %v", ErrBadXref, tok)
 	}
 	startxref, ok := b.readToken().(int64)
 	if !ok {
 		logger.Error("malformed PDF file: startxref not followed by integer, found: %d", startxref)
-		return 0, errors.New(" ")
+		return 0, fmt.Errorf("%w: startxref not followed by integer, found: %d", ErrBadXref, startxref)
 	}
 	logger.Debug(fmt.Sprintf("xref: FindStartXref -- startxref=%d", startxref), true)
 	return startxref, nil
@@ -271,7 +374,7 @@ func readXref(r *Reader, b *buffer) ([]xref, objptr, dict, error) {
 		return readXrefStream(r, b)
 	}
 	logger.Error(fmt.Sprintf("malformed PDF: cross-reference table nor stream found: %v", tok))
-	return nil, objptr{}, nil, errors.New(" ")
+	return nil, objptr{}, nil, fmt.Errorf("%w: cross-reference table nor stream found: %v", ErrBadXref, tok)
 }
 
 func readXrefStream(r *Reader, b *buffer) ([]xref, objptr, dict, error) {
@@ -289,7 +392,7 @@ func readXrefStream(r *Reader, b *buffer) ([]xref, objptr, dict, error) {
 	//Fill entries from the first stream.
 	table, err = readXrefStreamData(r, strm, table, size)
 	if err != nil {
-		return nil, objptr{}, nil, fmt.Errorf("malformed PDF: %v", err)
+		return nil, objptr{}, nil, fmt.Errorf("%w: %v", ErrBadXref, err)
 	}
 	// Follow and merge any /Prev streams.
 	table, err = mergePrevXrefStreams(r, strm, table, size)
@@ -308,16 +411,16 @@ func parseXrefStreamObject(b *buffer) (objptr, stream, error) {
 	od, ok := obj1.(objdef)
 	if !ok {
 		logger.Error(fmt.Sprintf("malformed PDF: objdef not found: %v", objfmt(obj1)))
-		return objptr{}, stream{}, errors.New(" ")
+		return objptr{}, stream{}, fmt.Errorf("%w: objdef not found: %v", ErrBadXref, objfmt(obj1))
 	}
 	strm, ok := od.obj.(stream)
 	if !ok {
 		logger.Error(fmt.Sprintf("malformed PDF: cross-reference stream not found: %v", objfmt(od)))
-		return objptr{}, stream{}, errors.New(" ")
+		return objptr{}, stream{}, fmt.Errorf("%w: cross-reference stream not found: %v", ErrBadXref, objfmt(od))
 	}
 	if strm.hdr["Type"] != name("XRef") {
 		logger.Error("malformed PDF: xref stream does not have type XRef")
-		return objptr{}, stream{}, errors.New(" ")
+		return objptr{}, stream{}, fmt.Errorf("%w: xref stream does not have type XRef", ErrBadXref)
 	}
 
 	return od.ptr, strm, nil
@@ -330,7 +433,7 @@ func xrefSize(strm stream) (int64, error) {
 		return size, nil
 	}
 	logger.Error("malformed PDF: xref stream missing Size")
-	return 0, errors.New(" ")
+	return 0, fmt.Errorf("%w: xref stream missing Size", ErrBadXref)
 }
 
 // Navigates and goes to /Prev chain, validating and merging each older stream.
@@ -340,7 +443,7 @@ func mergePrevXrefStreams(r *Reader, cur stream, table []xref, maxSize int64) ([
 		logger.Debug(fmt.Sprintf("found Prev stream wiht offset %d", off), true)
 		if !ok {
 			logger.Error(fmt.Sprintf("malformed PDF: xref Prev is not integer: %v", prevoff))
-			return nil, errors.New(" ")
+			return nil, fmt.Errorf("%w: xref Prev is not integer: %v", ErrBadXref, prevoff)
 		}
 		// Open a buffer at the previous xref stream offset and parse it.
 		b := newBuffer(io.NewSectionReader(r.f, off, r.end-off), off)
@@ -352,22 +455,22 @@ func mergePrevXrefStreams(r *Reader, cur stream, table []xref, maxSize int64) ([
 		prevVal := Value{r, objptr{}, prevStrm}
 		if prevVal.Kind() != Stream {
 			logger.Error(fmt.Sprintf("malformed PDF: xref prev stream is not stream: %v", prevVal))
-			return nil, errors.New(" ")
+			return nil, fmt.Errorf("%w: xref prev stream is not stream: %v", ErrBadXref, prevVal)
 		}
 		if prevVal.Key("Type").Name() != "XRef" {
 			logger.Error("malformed PDF: xref prev stream does not have type XRef")
-			return nil, errors.New(" ")
+			return nil, fmt.Errorf("%w: xref prev stream does not have type XRef", ErrBadXref)
 		}
 		// Size checks and merge.
 		psize := prevVal.Key("Size").Int64()
 		if psize > maxSize {
 			logger.Error("malformed PDF: xref prev stream larger than last stream")
-			return nil, errors.New(" ")
+			return nil, fmt.Errorf("%w: xref prev stream larger than last stream", ErrBadXref)
 		}
 		table, err = readXrefStreamData(r, prevVal.data.(stream), table, psize)
 		if err != nil {
 			logger.Error(fmt.Sprintf("malformed PDF: reading xref prev stream: %v", err))
-			return nil, errors.New(" ")
+			return nil, fmt.Errorf("%w: reading xref prev stream: %v", ErrBadXref, err)
 		}
 	}
 	logger.Debug("merged Prev stream data")
@@ -528,13 +631,13 @@ func parseXrefTableAndTrailer(b *buffer, table []xref) ([]xref, dict, error) {
 	table, err = readXrefTableData(b, table)
 	if err != nil {
 		logger.Error(fmt.Sprintf("malformed PDF: %v", err))
-		return nil, nil, errors.New(" ")
+		return nil, nil, err
 	}
 	logger.Debug("Parsed xref table section with %d entries so far\n", len(table))
 	trailer, ok := b.readObject().(dict)
 	if !ok {
 		logger.Error("malformed PDF: xref table not followed by trailer dictionary")
-		return nil, nil, errors.New(" ")
+		return nil, nil, fmt.Errorf("%w: xref table not followed by trailer dictionary", ErrBadXref)
 	}
 	return table, trailer, nil
 }
@@ -545,20 +648,20 @@ func resolvePrevXrefTables(r *Reader, trailer dict, table []xref) ([]xref, dict,
 		logger.Debug("found Prev xref table", true)
 		if !ok {
 			logger.Error(fmt.Sprintf("malformed PDF: xref Prev is not integer: %v", prevoff))
-			return nil, nil, errors.New(" ")
+			return nil, nil, fmt.Errorf("%w: xref Prev is not integer: %v", ErrBadXref, prevoff)
 		}
 		b := newBuffer(io.NewSectionReader(r.f, off, r.end-off), off)
 		// Prev must start with "xref"
 		tok := b.readToken()
 		if tok != keyword("xref") {
 			logger.Error("malformed PDF: xref Prev does not point to xref")
-			return nil, nil, errors.New(" ")
+			return nil, nil, fmt.Errorf("%w: xref Prev does not point to xref", ErrBadXref)
 		}
 		var err error
 		table, trailer, err = parseXrefTableAndTrailer(b, table)
 		if err != nil {
 			logger.Error(fmt.Sprintf("malformed PDF: %v", err))
-			return nil, nil, errors.New(" ")
+			return nil, nil, err
 		}
 		// call handleTrailerXRefStm for this older trailer before walking further Prev
 		table, trailer, err = r.handleTrailerXRefStm(table, trailer)
@@ -576,7 +679,7 @@ func validateTrailerSize(table *[]xref, trailer dict) error {
 	size, ok := trailer[name("Size")].(int64)
 	if !ok {
 		logger.Error("malformed PDF: trailer missing /Size entry")
-		return errors.New(" ")
+		return fmt.Errorf("%w: trailer missing /Size entry", ErrBadXref)
 	}
 
 	if size < int64(len(*table)) {
@@ -622,7 +725,7 @@ func readXrefTableData(b *buffer, table []xref) ([]xref, error) {
 		count, ok2 := b.readToken().(int64)
 		if !ok1 || !ok2 || start < 0 || count < 0 {
 			logger.Error("malformed xref table subsection header")
-			return nil, errors.New(" ")
+			return nil, fmt.Errorf("%w: malformed xref table subsection header", ErrBadXref)
 		}
 		for i := 0; i < int(count); i++ {
 			offTok := b.readToken()
@@ -634,7 +737,7 @@ func readXrefTableData(b *buffer, table []xref) ([]xref, error) {
 			alloc, okAlloc := allocTok.(keyword)
 			if !okOff || !okGen || !okAlloc {
 				logger.Error(fmt.Sprintf("malformed xref entry at subsection starting %d", start))
-				return nil, errors.New(" ")
+				return nil, fmt.Errorf("%w: malformed xref entry at subsection starting %d", ErrBadXref, start)
 			}
 
 			idx := int(start) + i
@@ -645,7 +748,7 @@ func readXrefTableData(b *buffer, table []xref) ([]xref, error) {
 				table = ensureLen(table, idx+1)
 			default:
 				logger.Error(fmt.Sprintf("malformed xref table: unexpected alloc token %v", alloc))
-				return nil, errors.New(" ")
+				return nil, fmt.Errorf("%w: malformed xref table: unexpected alloc token %v", ErrBadXref, alloc)
 			}
 		}
 	}
@@ -784,13 +887,13 @@ func (r *Reader) handleTrailerXRefStm(table []xref, trailer dict) ([]xref, dict,
 	off, ok := xrefstm.(int64)
 	if !ok {
 		logger.Error(fmt.Sprintf("malformed PDF: XRefStm not integer: %v", xrefstm))
-		return table, trailer, errors.New(" ")
+		return table, trailer, fmt.Errorf("%w: XRefStm not integer: %v", ErrBadXref, xrefstm)
 	}
 	b := newBuffer(io.NewSectionReader(r.f, off, r.end-off), off)
 	srcTable, _, hdr, err := readXrefStream(r, b)
 	if err != nil {
 		logger.Error(fmt.Sprintf("failed to parse XRefStm at %d: %v", off, err))
-		return table, trailer, errors.New(" ")
+		return table, trailer, fmt.Errorf("parsing XRefStm at %d: %w", off, err)
 	}
 	// validate & attempt repair on srcTable offsets
 	repaired, invalid := r.validateAndRepairXrefEntries(srcTable)
@@ -805,7 +908,7 @@ func (r *Reader) handleTrailerXRefStm(table []xref, trailer dict) ([]xref, dict,
 	// Accept or reject the stream table based on an invalid threshold
 	if total > 0 && float64(invalid)/float64(total) > 0.30 {
 		logger.Error(fmt.Sprintf("xref stream at %d appears invalid: %d/%d invalid entries", off, invalid, total))
-		return table, trailer, errors.New(" ")
+		return table, trailer, fmt.Errorf("%w: xref stream at %d appears invalid: %d/%d invalid entries", ErrBadXref, off, invalid, total)
 	}
 
 	// Merge the stream table into the main ASCII table.
@@ -813,7 +916,7 @@ func (r *Reader) handleTrailerXRefStm(table []xref, trailer dict) ([]xref, dict,
 
 	if _, ok := hdr["Size"]; !ok {
 		logger.Debug(fmt.Sprintf("xref stream at %d missing /Size", off))
-		return table, trailer, errors.New(" ")
+		return table, trailer, fmt.Errorf("%w: xref stream at %d missing /Size", ErrBadXref, off)
 	}
 	return table, trailer, nil
 }
@@ -1139,6 +1242,7 @@ func (v Value) Name() string {
 // Like the result of the Name method, the key should not include a leading slash.
 // If v is a stream, Key applies to the stream's header dictionary.
 // If v.Kind() != Dict and v.Kind() != Stream, Key returns a null Value.
+// An indirect object that cannot be read is also a null Value.
 func (v Value) Key(key string) Value {
 	x, ok := v.data.(dict)
 	if !ok {
@@ -1173,7 +1277,8 @@ func (v Value) Keys() []string {
 
 // Index returns the i'th element in the array v.
 // If v.Kind() != Array or if i is outside the array bounds,
-// Index returns a null Value.
+// Index returns a null Value, as it does for an indirect object that
+// cannot be read.
 func (v Value) Index(i int) Value {
 	x, ok := v.data.(array)
 	if !ok || i < 0 || i >= len(x) {
@@ -1192,34 +1297,47 @@ func (v Value) Len() int {
 	return len(x)
 }
 
-func (r *Reader) resolve(parent objptr, x interface{}) Value {
+// resolve returns the Value for x, an element of the object parent,
+// loading it if x is an indirect reference. An object that is malformed or
+// exceeds the Limits is logged and resolves to a null Value, unless r
+// raises errors.
+func (r *Reader) resolve(parent objptr, x interface{}) (v Value) {
+	defer r.catch()
+	return r.resolveObject(parent, x)
+}
+
+func (r *Reader) resolveObject(parent objptr, x interface{}) Value {
 	//logger.Debug("resolving objects")
 	if ptr, ok := x.(objptr); ok {
-		if ptr.id >= uint32(len(r.xref)) {
-			return Value{}
-		}
-		xref := r.xref[ptr.id]
+		xref := r.xrefEntry(ptr.id)
 		if xref.ptr != ptr || !xref.inStream && xref.offset == 0 {
 			return Value{}
 		}
+		r.countObject()
 		var obj object
 		if xref.inStream {
-			strm := r.resolve(parent, xref.stream)
+			// object streams may not themselves be compressed, which also
+			// keeps a crafted xref table from recursing without end
+			if s := xref.stream; r.xrefEntry(s.id).inStream {
+				panic(objectError(ptr, -1, "object stream %d %d R is in an object stream", s.id, s.gen))
+			}
+			strm := r.resolveObject(parent, xref.stream)
 		Search:
-			for {
+			for depth := 1; ; depth++ {
+				r.checkDepth(depth)
 				if strm.Kind() != Stream {
 					logger.Error("not a stream")
-					panic("not a stream")
+					panic(objectError(ptr, -1, "object stream %d %d R is not a stream", xref.stream.id, xref.stream.gen))
 				}
 				if strm.Key("Type").Name() != "ObjStm" {
 					logger.Error("not an object stream")
-					panic("not an object stream")
+					panic(objectError(ptr, -1, "stream %d %d R is not an object stream", xref.stream.id, xref.stream.gen))
 				}
 				n := int(strm.Key("N").Int64())
 				first := strm.Key("First").Int64()
 				if first == 0 {
 					logger.Error("missing First")
-					panic("missing First")
+					panic(objectError(ptr, -1, "object stream %d %d R: missing First", xref.stream.id, xref.stream.gen))
 				}
 				b := newBuffer(strm.Reader(), 0)
 				b.allowEOF = true
@@ -1236,24 +1354,25 @@ func (r *Reader) resolve(parent objptr, x interface{}) Value {
 				ext := strm.Key("Extends")
 				if ext.Kind() != Stream {
 					logger.Error("cannot find object in stream")
-					panic("cannot find object in stream")
+					panic(objectError(ptr, -1, "cannot find object in object stream %d %d R", xref.stream.id, xref.stream.gen))
 				}
 				strm = ext
 			}
 		} else {
 			b := newBuffer(io.NewSectionReader(r.f, xref.offset, r.end-xref.offset), xref.offset)
-			b.key = r.key
-			b.useAES = r.useAES
+			if ptr != r.encryptptr && r.strMethod != "None" {
+				b.key = r.key
+				b.method = r.strMethod
+			}
 			obj = b.readObject()
 			def, ok := obj.(objdef)
 			if !ok {
 				logger.Error(fmt.Sprintf("loading %v: found %T instead of objdef", ptr, obj))
-				panic(fmt.Errorf("loading %v: found %T instead of objdef", ptr, obj))
-				//return Value{}
+				panic(objectError(ptr, xref.offset, "found %T instead of object definition", obj))
 			}
 			if def.ptr != ptr {
 				logger.Error(fmt.Sprintf("loading %v: found %v", ptr, def.ptr))
-				panic(fmt.Errorf("loading %v: found %v", ptr, def.ptr))
+				panic(objectError(ptr, xref.offset, "found object %d %d", def.ptr.id, def.ptr.gen))
 			}
 			x = def.obj
 			if d, ok := x.(dict); ok {
@@ -1308,7 +1427,7 @@ func (r *Reader) resolve(parent objptr, x interface{}) Value {
 		return Value{r, parent, x}
 	default:
 		logger.Error(fmt.Sprintf("unexpected value type %T in resolve", x))
-		panic(fmt.Errorf("unexpected value type %T in resolve", x))
+		panic(objectError(parent, -1, "unexpected value type %T", x))
 	}
 }
 
@@ -1327,60 +1446,125 @@ func (e *errorReadCloser) Close() error {
 // Reader returns the data contained in the stream v.
 // If v.Kind() != Stream, Reader returns a ReadCloser that
 // responds to all reads with a “stream not present” error.
+// If the stream uses a filter that cannot be decoded, reads return an
+// error wrapping ErrUnsupportedFilter, and once the decoded data exceeds
+// the Reader's Limits they return a *LimitError.
 func (v Value) Reader() io.ReadCloser {
+	return v.ReaderContext(context.Background())
+}
+
+// ReaderContext is like Reader, but once ctx is done reads of the stream,
+// and of the data its filters decode, return ctx.Err().
+func (v Value) ReaderContext(ctx context.Context) io.ReadCloser {
 	logger.Debug("Reader: reading the data contained in the stream")
 
+	rd := newContextReader(ctx, v.rawReader())
+	names, params, err := v.filters()
+	if err != nil {
+		return &errorReadCloser{err}
+	}
+	for i, name := range names {
+		if rd, err = applyFilter(rd, name, params[i]); err != nil {
+			return &errorReadCloser{err}
+		}
+	}
+	return ioutil.NopCloser(newContextReader(ctx, v.r.limitStream(rd)))
+}
+
+// rawReader returns the stream's data before its filters are applied,
+// decrypted if the document is encrypted.
+func (v Value) rawReader() io.Reader {
 	x, ok := v.data.(stream)
 	if !ok {
 		logger.Error("stream not present")
 		return &errorReadCloser{fmt.Errorf("stream not present")}
 	}
+	if x.data != nil {
+		return bytes.NewReader(x.data)
+	}
 	var rd io.Reader
 	rd = io.NewSectionReader(v.r.f, x.offset, v.Key("Length").Int64())
+	if v.r.key != nil {
+		rd = decryptStream(v.r.key, v.cryptMethod(), x.ptr, rd)
+	}
+	return rd
+}
+
+// filters returns the names of the stream's filters, in the order they
+// are applied, and their decode parameters.
+func (v Value) filters() ([]string, []Value, error) {
 	filter := v.Key("Filter")
 	param := v.Key("DecodeParms")
 	switch filter.Kind() {
 	default:
 		logger.Error(fmt.Sprintf("unsupported filter %v", filter))
-		panic(fmt.Errorf("unsupported filter %v", filter))
+		return nil, nil, fmt.Errorf("%w %v", ErrUnsupportedFilter, filter)
 	case Null:
-		// ok
+		return nil, nil, nil
 	case Name:
-		rd = applyFilter(rd, filter.Name(), param)
+		return []string{filter.Name()}, []Value{param}, nil
 	case Array:
-		for i := 0; i < filter.Len(); i++ {
-			rd = applyFilter(rd, filter.Index(i).Name(), param.Index(i))
+		names := make([]string, filter.Len())
+		params := make([]Value, filter.Len())
+		for i := range names {
+			names[i] = filter.Index(i).Name()
+			params[i] = param.Index(i)
 		}
+		return names, params, nil
 	}
+}
 
-	return ioutil.NopCloser(rd)
+// cryptMethod returns the crypt filter method that applies to the data of
+// stream v: the method of a leading /Crypt filter if there is one, /StmF
+// otherwise. Cross-reference streams are never encrypted, and metadata
+// streams are left in the clear when /EncryptMetadata is false.
+func (v Value) cryptMethod() string {
+	switch v.Key("Type").Name() {
+	case "XRef":
+		return "None"
+	case "Metadata":
+		if !v.r.encryptMetadata {
+			return "None"
+		}
+	}
+	filter, param := v.Key("Filter"), v.Key("DecodeParms")
+	if filter.Kind() == Array {
+		filter, param = filter.Index(0), param.Index(0)
+	}
+	if filter.Name() != "Crypt" {
+		return v.r.stmMethod
+	}
+	n := param.Key("Name").Name()
+	if n == "" || n == "Identity" {
+		return "None"
+	}
+	if m, ok := v.r.cryptFilters[n]; ok {
+		return m
+	}
+	logger.Debug(fmt.Sprintf("decrypt: undefined crypt filter %q, using /StmF", n), true)
+	return v.r.stmMethod
 }
 
-func applyFilter(rd io.Reader, name string, param Value) io.Reader {
+// applyFilter wraps rd with the decoder for the named filter. Filters and
+// parameters that cannot be decoded are reported as ErrUnsupportedFilter.
+func applyFilter(rd io.Reader, name string, param Value) (io.Reader, error) {
 	logger.Debug("applyFilter")
 	switch name {
 	default:
 		logger.Error("unknown filter " + name)
-		panic("unknown filter " + name)
+		return nil, fmt.Errorf("%w %s", ErrUnsupportedFilter, name)
 	case "FlateDecode":
 		zr, err := zlib.NewReader(rd)
 		if err != nil {
 			logger.Error(err.Error())
-			panic(err)
+			return nil, fmt.Errorf("FlateDecode: %w", err)
 		}
 		logger.Debug("filter: FlateDecode (decoder initialized)", true)
-		pred := param.Key("Predictor")
-		if pred.Kind() == Null {
-			return zr
-		}
-		columns := param.Key("Columns").Int64()
-		switch pred.Int64() {
-		default:
-			logger.Error(fmt.Sprintf("unknown predictor %d", pred.data))
-			panic("pred")
-		case 12:
-			return &pngUpReader{r: zr, hist: make([]byte, 1+columns), tmp: make([]byte, 1+columns)}
-		}
+		return applyPredictor(zr, param)
+	case "LZWDecode":
+		early := param.Key("EarlyChange")
+		logger.Debug(fmt.Sprintf("filter: LZWDecode (EarlyChange=%v)", early), true)
+		return applyPredictor(newLZWReader(rd, early.Kind() != Integer || early.Int64() != 0), param)
 	case "ASCII85Decode":
 		cleanASCII85 := newAlphaReader(rd)
 		decoder := ascii85.NewDecoder(cleanASCII85)
@@ -1388,43 +1572,49 @@ func applyFilter(rd io.Reader, name string, param Value) io.Reader {
 		switch param.Keys() {
 		default:
 			logger.Error("not expected DecodeParms for ascii85")
-			panic("not expected DecodeParms for ascii85")
+			return nil, fmt.Errorf("%w: DecodeParms for ASCII85Decode", ErrUnsupportedFilter)
 		case nil:
-			return decoder
+			return decoder, nil
 		}
+	case "ASCIIHexDecode":
+		return newASCIIHexReader(rd), nil
+	case "RunLengthDecode":
+		return newRunLengthReader(rd), nil
+	case "Crypt":
+		// Crypt filters are applied by the document-level decryption in
+		// rawReader, which selects the method from the filter's /Name.
+		return rd, nil
 	}
 }
 
-type pngUpReader struct {
-	r    io.Reader
-	hist []byte
-	tmp  []byte
-	pend []byte
-}
-
-func (r *pngUpReader) Read(b []byte) (int, error) {
-	n := 0
-	for len(b) > 0 {
-		if len(r.pend) > 0 {
-			m := copy(b, r.pend)
-			n += m
-			b = b[m:]
-			r.pend = r.pend[m:]
-			continue
-		}
-		_, err := io.ReadFull(r.r, r.tmp)
-		if err != nil {
-			return n, err
-		}
-		if r.tmp[0] != 2 {
-			logger.Error("malformed PNG-Up encoding")
-			return n, errors.New(" ")
-		}
-		for i, b := range r.tmp {
-			r.hist[i] += b
-		}
-		r.pend = r.hist[1:]
+// applyPredictor wraps rd with the predictor selected by the
+// /Predictor entry of a FlateDecode or LZWDecode parameter dictionary.
+func applyPredictor(rd io.Reader, param Value) (io.Reader, error) {
+	pred := param.Key("Predictor")
+	if pred.Kind() == Null {
+		return rd, nil
+	}
+	colors, bpc, columns := 1, 8, 1
+	if v := param.Key("Colors"); v.Kind() == Integer && v.Int64() > 0 {
+		colors = int(v.Int64())
+	}
+	if v := param.Key("BitsPerComponent"); v.Kind() == Integer && v.Int64() > 0 {
+		bpc = int(v.Int64())
+	}
+	if v := param.Key("Columns"); v.Kind() == Integer && v.Int64() > 0 {
+		columns = int(v.Int64())
+	}
+	switch p := pred.Int64(); {
+	default:
+		logger.Error(fmt.Sprintf("unknown predictor %d", pred.data))
+		return nil, fmt.Errorf("%w: predictor %v", ErrUnsupportedFilter, pred.data)
+	case p == 1:
+		return rd, nil
+	case p == 2:
+		logger.Debug(fmt.Sprintf("predictor: TIFF (Colors=%d BitsPerComponent=%d Columns=%d)", colors, bpc, columns))
+		return newTIFFPredictorReader(rd, colors, bpc, columns), nil
+	case p >= 10 && p <= 15:
+		logger.Debug(fmt.Sprintf("predictor: PNG %d (Colors=%d BitsPerComponent=%d Columns=%d)", p, colors, bpc, columns))
+		return newPNGPredictorReader(rd, colors, bpc, columns), nil
 	}
-	return n, nil
 }
-
@@ -8,6 +8,7 @@ import (
 	"compress/zlib"
 	"encoding/ascii85"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -887,8 +888,9 @@ func TestResolve_InStream_NotAStream(t *testing.T) {
 		},
 	}
 
+	assert.True(t, r.resolve(objptr{}, objptr{1, 0}).IsNull())
 	assert.Panics(t, func() {
-		_ = r.resolve(objptr{}, objptr{1, 0})
+		_ = r.raising().resolve(objptr{}, objptr{1, 0})
 	})
 }
 
@@ -924,8 +926,9 @@ func TestResolve_InStream_NotObjStm(t *testing.T) {
 			{ptr: objptr{2, 0}, inStream: true, stream: objptr{1, 0}},
 		},
 	}
+	assert.True(t, r.resolve(objptr{}, objptr{2, 0}).IsNull())
 	assert.Panics(t, func() {
-		_ = r.resolve(objptr{}, objptr{2, 0})
+		_ = r.raising().resolve(objptr{}, objptr{2, 0})
 	}, "expected panic because stream Type is not ObjStm")
 }
 
@@ -957,7 +960,8 @@ func TestApplyFilter(t *testing.T) {
 		zw.Write([]byte("hello"))
 		zw.Close()
 
-		rd := applyFilter(bytes.NewReader(buf.Bytes()), "FlateDecode", Value{})
+		rd, err := applyFilter(bytes.NewReader(buf.Bytes()), "FlateDecode", Value{})
+		assert.NoError(t, err)
 		out, err := io.ReadAll(rd)
 		assert.NoError(t, err)
 		assert.Equal(t, []byte("hello"), out)
@@ -970,49 +974,16 @@ func TestApplyFilter(t *testing.T) {
 		enc.Write([]byte("hi!"))
 		enc.Close()
 
-		rd := applyFilter(bytes.NewReader(buf.Bytes()), "ASCII85Decode", Value{})
+		rd, err := applyFilter(bytes.NewReader(buf.Bytes()), "ASCII85Decode", Value{})
+		assert.NoError(t, err)
 		out, err := io.ReadAll(rd)
 		assert.NoError(t, err)
 		assert.Equal(t, []byte("hi!"), out)
 	}
 
-	//Unknown filter should panic
-	assert.Panics(t, func() {
-		_ = applyFilter(bytes.NewReader([]byte("abc")), "UnknownFilter", Value{})
-	})
-}
-
-func TestPngUpReader(t *testing.T) {
-	r1 := &pngUpReader{
-		r:    bytes.NewReader([]byte{2, 1, 1}),
-		hist: []byte{10, 20, 30},
-		tmp:  make([]byte, 3),
-	}
-	buf := make([]byte, 2)
-	n, err := r1.Read(buf)
-	assert.NoError(t, err)
-	assert.Equal(t, 2, n)
-	assert.Equal(t, []byte{21, 31}, buf) // hist updated => [12,21,31] => pend = hist[1:]
-
-	//malformed case
-	r2 := &pngUpReader{
-		r:    bytes.NewReader([]byte{9, 1, 1}),
-		hist: []byte{0, 0, 0},
-		tmp:  make([]byte, 3),
-	}
-	n, err = r2.Read(make([]byte, 1))
-	assert.Error(t, err)
-	assert.Equal(t, 0, n)
-
-	// EOF
-	r3 := &pngUpReader{
-		r:    bytes.NewReader([]byte{}),
-		hist: []byte{0, 0, 0},
-		tmp:  make([]byte, 3),
-	}
-	n, err = r3.Read(make([]byte, 1))
-	assert.Error(t, err)
-	assert.Equal(t, 0, n)
+	//Unknown filter is reported as unsupported
+	_, err := applyFilter(bytes.NewReader([]byte("abc")), "UnknownFilter", Value{})
+	assert.ErrorIs(t, err, ErrUnsupportedFilter)
 }
 
 func TestDictEncoder_Decode_MappedAndUnmapped(t *testing.T) {
@@ -1052,3 +1023,48 @@ func TestDictEncoder_Decode_NoMappingsAndEmpty(t *testing.T) {
 	got2 := e.Decode("")
 	assert.Equal(t, "", got2)
 }
+
+func TestReadXrefStream_PNGPredictors(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.5\n")
+	offsets := []int{0}
+	for _, obj := range []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [] /Count 0 >>",
+	} {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", len(offsets)-1, obj)
+	}
+	xrefOff := b.Len()
+	offsets = append(offsets, xrefOff)
+
+	// W [1 2 1]: one row per object, each row using a different PNG filter.
+	rows := [][]byte{
+		{0, 0, 0, 255},
+		{1, byte(offsets[1] >> 8), byte(offsets[1]), 0},
+		{1, byte(offsets[2] >> 8), byte(offsets[2]), 0},
+		{1, byte(offsets[3] >> 8), byte(offsets[3]), 0},
+	}
+	var raw []byte
+	prior := make([]byte, 4)
+	for i, row := range rows {
+		raw = append(raw, pngFilterRow([]byte{1, 4, 3, 2}[i], row, prior, 1)...)
+		prior = row
+	}
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write(raw)
+	zw.Close()
+
+	fmt.Fprintf(&b, "3 0 obj\n<< /Type /XRef /Size 4 /W [1 2 1] /Root 1 0 R /Filter /FlateDecode "+
+		"/DecodeParms << /Predictor 12 /Columns 4 >> /Length %d >>\nstream\n", z.Len())
+	b.Write(z.Bytes())
+	fmt.Fprintf(&b, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", xrefOff)
+
+	data := b.Bytes()
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	assert.Equal(t, "Catalog", r.Trailer().Key("Root").Key("Type").Name())
+	assert.Equal(t, "Pages", r.Trailer().Key("Root").Key("Pages").Key("Type").Name())
+	assert.Equal(t, 0, r.NumPage())
+}
@@ -0,0 +1,352 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+// Recovery scans damaged files (a missing %%EOF or startxref, a truncated
+// download, an xref table pointing at the wrong offsets) from start to end
+// for "N G obj" headers and trailers, instead of trusting the cross-reference
+// data. An object defined more than once keeps its last definition, as an
+// incremental update would. The trailer is merged from every trailer
+// dictionary and XRef stream found; if it names no usable catalog, the last
+// /Type /Catalog object is used.
+
+const (
+	// recoverChunk is the size of the blocks the file is scanned in, and
+	// recoverOverlap how far consecutive blocks overlap so that headers
+	// straddling a block boundary are found.
+	recoverChunk   = 1 << 20
+	recoverOverlap = 64
+	// maxObjectID is the largest object number a PDF file may use.
+	maxObjectID = 8388607
+)
+
+var (
+	objHeaderRE = regexp.MustCompile(`(\d+)[\x00\t\n\f\r ]+(\d+)[\x00\t\n\f\r ]+obj`)
+	trailerRE   = regexp.MustCompile(`trailer[\x00\t\n\f\r ]*<<`)
+)
+
+// scannedObject is an object header found by scanning the file.
+type scannedObject struct {
+	ptr    objptr
+	offset int64
+}
+
+// scanFile returns the object headers and the offsets of the trailer
+// dictionaries in the file, in file order.
+func (r *Reader) scanFile() (objs []scannedObject, trailers []int64) {
+	buf := make([]byte, recoverChunk+recoverOverlap)
+	for base := int64(0); base < r.end; base += recoverChunk {
+		n, err := r.f.ReadAt(buf, base)
+		if err != nil && err != io.EOF {
+			logger.Debug(fmt.Sprintf("xref recovery: reading at offset %d: %v", base, err), true)
+			break
+		}
+		data := buf[:n]
+		// matches starting in the overlap belong to the next block
+		limit := len(data)
+		if base+recoverChunk < r.end {
+			limit = recoverChunk
+		}
+		for _, m := range objHeaderRE.FindAllSubmatchIndex(data, -1) {
+			if m[0] >= limit || m[0] > 0 && isDigit(data[m[0]-1]) {
+				continue
+			}
+			if end := m[1]; end < len(data) && !isDelimOrSpace(data[end]) {
+				continue
+			}
+			id, gen := scanDecimal(data[m[2]:m[3]]), scanDecimal(data[m[4]:m[5]])
+			if id < 0 || id > maxObjectID || gen < 0 || gen > 65535 {
+				continue
+			}
+			objs = append(objs, scannedObject{objptr{uint32(id), uint16(gen)}, base + int64(m[0])})
+		}
+		for _, m := range trailerRE.FindAllIndex(data, -1) {
+			if m[0] < limit {
+				trailers = append(trailers, base+int64(m[0]))
+			}
+		}
+	}
+	return objs, trailers
+}
+
+// rebuildXref replaces the cross-reference table and trailer with those
+// found by scanning the file. Objects in object streams are added later, by
+// completeRecovery, once encrypted streams can be read.
+func (r *Reader) rebuildXref() error {
+	objs, trailers := r.scanFile()
+	if len(objs) == 0 {
+		logger.Error("xref recovery: no objects found")
+		return errors.New("malformed PDF file: no objects found")
+	}
+
+	size := 0
+	for _, o := range objs {
+		size = max(size, int(o.ptr.id)+1)
+	}
+	// the table is sized by the objects found, not by the largest object
+	// number, which a few bytes of a damaged file can make huge
+	r.xref = make([]xref, min(size, sparseXrefSlack(len(objs))))
+	r.sparseXref = nil
+	for _, o := range objs {
+		// the last definition of an object wins, as in an incremental update
+		r.setXref(o.ptr.id, xref{ptr: o.ptr, offset: o.offset})
+	}
+	r.trailerptr = objptr{}
+	r.trailer = dict{}
+	r.recovered = true
+
+	for _, off := range trailers {
+		b := newBuffer(io.NewSectionReader(r.f, off+int64(len("trailer")), r.end-off-int64(len("trailer"))), off+int64(len("trailer")))
+		b.allowEOF = true
+		if d, ok := safeReadObject(b).(dict); ok {
+			r.mergeTrailer(d)
+		}
+	}
+	// XRef streams carry the trailer entries of files that use them
+	for _, x := range r.xrefEntries() {
+		v := r.safeResolve(x.ptr)
+		if v.Kind() == Stream && v.Key("Type").Name() == "XRef" {
+			if strm, ok := v.data.(stream); ok {
+				r.mergeTrailer(strm.hdr)
+			}
+		}
+	}
+	r.trailer[name("Size")] = int64(size)
+	logger.Debug(fmt.Sprintf("xref recovery: found %d object headers, %d objects and %d trailers by scanning the file",
+		len(objs), r.objectCount(), len(trailers)), true)
+	return nil
+}
+
+// mergeTrailer copies the document-level entries of a trailer or XRef
+// stream dictionary d into the trailer, later ones replacing earlier ones.
+func (r *Reader) mergeTrailer(d dict) {
+	for _, key := range []name{"Root", "Info", "ID", "Encrypt"} {
+		if v, ok := d[key]; ok && v != nil {
+			r.trailer[key] = v
+		}
+	}
+}
+
+// completeRecovery completes a rebuilt cross-reference table with the
+// objects stored in object streams, and finds the catalog if the trailer
+// does not name a usable one. If the catalog or its page tree is lost, as
+// in a truncated download that had them at the end, the pages found are
+// gathered in file order into a page tree of their own.
+func (r *Reader) completeRecovery() {
+	var streams []objptr
+	for _, x := range r.xrefEntries() {
+		if x.inStream {
+			continue
+		}
+		if v := r.safeResolve(x.ptr); v.Kind() == Stream && v.Key("Type").Name() == "ObjStm" {
+			streams = append(streams, x.ptr)
+		}
+	}
+	// later object streams replace earlier ones and direct objects before them
+	sort.Slice(streams, func(i, j int) bool { return r.xrefEntry(streams[i].id).offset < r.xrefEntry(streams[j].id).offset })
+	added := 0
+	for _, s := range streams {
+		off := r.xrefEntry(s.id).offset
+		for _, id := range r.objectStreamIDs(s) {
+			if id <= 0 || id > maxObjectID {
+				continue
+			}
+			if x := r.xrefEntry(uint32(id)); x.ptr != (objptr{}) && !x.inStream && x.offset > off {
+				continue
+			}
+			r.setXref(uint32(id), xref{ptr: objptr{uint32(id), 0}, inStream: true, stream: s})
+			added++
+		}
+	}
+	size := int64(len(r.xref))
+	for id := range r.sparseXref {
+		size = max(size, int64(id)+1)
+	}
+	r.trailer[name("Size")] = size
+	logger.Debug(fmt.Sprintf("xref recovery: %d objects from %d object streams", added, len(streams)), true)
+
+	if r.hasCatalog() {
+		return
+	}
+	var catalog dict
+	var pages []objptr
+	for _, x := range r.xrefEntries() {
+		v := r.safeResolve(x.ptr)
+		switch v.Key("Type").Name() {
+		case "Catalog":
+			r.trailer[name("Root")] = x.ptr
+			catalog, _ = v.data.(dict)
+		case "Page":
+			pages = append(pages, x.ptr)
+		}
+	}
+	if r.hasCatalog() {
+		logger.Debug(fmt.Sprintf("xref recovery: using catalog %v", r.trailer[name("Root")]), true)
+		return
+	}
+	if len(pages) == 0 {
+		logger.Debug("xref recovery: no catalog or pages found", true)
+		return
+	}
+	// page objects in object streams are ordered by the stream's position
+	offset := func(ptr objptr) int64 {
+		if x := r.xrefEntry(ptr.id); x.inStream {
+			return r.xrefEntry(x.stream.id).offset
+		}
+		return r.xrefEntry(ptr.id).offset
+	}
+	sort.SliceStable(pages, func(i, j int) bool { return offset(pages[i]) < offset(pages[j]) })
+	kids := make(array, len(pages))
+	for i, p := range pages {
+		kids[i] = p
+	}
+	root := dict{}
+	for k, v := range catalog {
+		root[k] = v
+	}
+	root[name("Type")] = name("Catalog")
+	root[name("Pages")] = dict{name("Type"): name("Pages"), name("Kids"): kids, name("Count"): int64(len(kids))}
+	r.trailer[name("Root")] = root
+	logger.Debug(fmt.Sprintf("xref recovery: page tree lost, rebuilt from %d pages", len(pages)), true)
+}
+
+// objectStreamIDs returns the object numbers stored in an object stream.
+func (r *Reader) objectStreamIDs(ptr objptr) (ids []int) {
+	defer func() {
+		if e := recover(); e != nil {
+			logger.Debug(fmt.Sprintf("xref recovery: object stream %d %d R: %v", ptr.id, ptr.gen, e), true)
+		}
+	}()
+	strm := r.resolve(objptr{}, ptr)
+	b := newBuffer(strm.Reader(), 0)
+	b.allowEOF = true
+	for i := int64(0); i < strm.Key("N").Int64(); i++ {
+		id, ok1 := b.readToken().(int64)
+		_, ok2 := b.readToken().(int64)
+		if !ok1 || !ok2 {
+			break
+		}
+		ids = append(ids, int(id))
+	}
+	return ids
+}
+
+// hasCatalog reports whether the trailer's /Root is a catalog with a page
+// tree.
+func (r *Reader) hasCatalog() (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return r.Trailer().Key("Root").Key("Pages").Kind() == Dict
+}
+
+// objectCount returns the number of objects in the cross-reference table.
+func (r *Reader) objectCount() int {
+	return len(r.xrefEntries())
+}
+
+// sparseXrefSlack returns how far beyond its n entries the cross-reference
+// table may grow to hold an object number; larger object numbers are kept
+// in sparseXref instead.
+func sparseXrefSlack(n int) int {
+	return 2*n + 1024
+}
+
+// xrefEntry returns the cross-reference entry of object number id, or the
+// zero xref if there is none.
+func (r *Reader) xrefEntry(id uint32) xref {
+	if int64(id) < int64(len(r.xref)) {
+		if x := r.xref[id]; x.ptr != (objptr{}) || r.sparseXref == nil {
+			return x
+		}
+	}
+	return r.sparseXref[id]
+}
+
+// setXref sets the cross-reference entry of object number id, growing the
+// table unless id lies far beyond its end, as a stray object number in a
+// damaged file can.
+func (r *Reader) setXref(id uint32, x xref) {
+	if int64(id) < int64(sparseXrefSlack(len(r.xref))) {
+		r.xref = ensureLen(r.xref, int(id)+1)
+		r.xref[id] = x
+		delete(r.sparseXref, id)
+		return
+	}
+	if r.sparseXref == nil {
+		r.sparseXref = make(map[uint32]xref)
+	}
+	r.sparseXref[id] = x
+}
+
+// xrefEntries returns the entries of the cross-reference table, in object
+// number order.
+func (r *Reader) xrefEntries() []xref {
+	var entries []xref
+	for _, x := range r.xref {
+		if x.ptr != (objptr{}) {
+			entries = append(entries, x)
+		}
+	}
+	sparse := make([]xref, 0, len(r.sparseXref))
+	for _, x := range r.sparseXref {
+		sparse = append(sparse, x)
+	}
+	sort.Slice(sparse, func(i, j int) bool { return sparse[i].ptr.id < sparse[j].ptr.id })
+	return append(entries, sparse...)
+}
+
+// safeResolve resolves ptr, returning the null Value for objects that
+// cannot be read.
+func (r *Reader) safeResolve(ptr objptr) (v Value) {
+	defer func() {
+		if recover() != nil {
+			v = Value{}
+		}
+	}()
+	return r.resolve(objptr{}, ptr)
+}
+
+// safeReadObject reads the next object from b, or returns nil if it is
+// malformed.
+func safeReadObject(b *buffer) (obj object) {
+	defer func() {
+		if recover() != nil {
+			obj = nil
+		}
+	}()
+	return b.readObject()
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isDelimOrSpace(c byte) bool {
+	return isDelim(c) || isSpace(c)
+}
+
+// scanDecimal parses a run of decimal digits, returning -1 if it overflows.
+func scanDecimal(b []byte) int {
+	n := 0
+	for _, c := range b {
+		n = n*10 + int(c-'0')
+		if n > maxObjectID {
+			return -1
+		}
+	}
+	return n
+}
@@ -0,0 +1,147 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recoverTestObjs returns the objects of a two-page document whose pages
+// show "first" and "second".
+func recoverTestObjs() []string {
+	return []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 7 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 5 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 6 0 R >>",
+		streamObj("", []byte("BT /F1 12 Tf 72 700 Td (first) Tj ET")),
+		streamObj("", []byte("BT /F1 12 Tf 72 700 Td (second) Tj ET")),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+}
+
+// recoveredText opens data with Recover set and returns the text of its
+// pages, one line each.
+func recoveredText(t *testing.T, data []byte) []string {
+	t.Helper()
+	r, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{Recover: true})
+	require.NoError(t, err)
+	var pages []string
+	for i := 1; i <= r.NumPage(); i++ {
+		s, err := r.Page(i).GetPlainText(nil)
+		require.NoError(t, err)
+		pages = append(pages, strings.TrimSpace(s))
+	}
+	return pages
+}
+
+func TestRecover_Truncated(t *testing.T) {
+	data := buildPDF(recoverTestObjs(), "")
+	cut := data[:bytes.Index(data, []byte("xref"))]
+
+	_, err := NewReader(bytes.NewReader(cut), int64(len(cut)))
+	require.Error(t, err, "without recovery a file without %%EOF is rejected")
+	assert.Equal(t, []string{"first", "second"}, recoveredText(t, cut))
+}
+
+func TestRecover_BadXref(t *testing.T) {
+	data := buildPDF(recoverTestObjs(), "")
+
+	// startxref pointing into the middle of an object
+	bad := bytes.Replace(data, []byte("startxref\n"), []byte("startxref\n1"), 1)
+	_, err := NewReader(bytes.NewReader(bad), int64(len(bad)))
+	require.Error(t, err)
+	assert.Equal(t, []string{"first", "second"}, recoveredText(t, bad))
+
+	// xref offsets shifted by bytes inserted before the objects, so the
+	// catalog cannot be read
+	shifted := bytes.Replace(data, []byte("%PDF-1.7\n"), []byte("%PDF-1.7\n"+strings.Repeat("%", 2048)+"\n"), 1)
+	assert.Equal(t, []string{"first", "second"}, recoveredText(t, shifted))
+}
+
+func TestRecover_IncrementalUpdate(t *testing.T) {
+	data := buildPDF(recoverTestObjs(), "")
+	data = data[:bytes.Index(data, []byte("xref"))]
+	data = append(data, "6 0 obj\n"+streamObj("", []byte("BT /F1 12 Tf 72 700 Td (updated) Tj ET"))+"\nendobj\n"...)
+	assert.Equal(t, []string{"first", "updated"}, recoveredText(t, data))
+}
+
+func TestRecover_ObjectStream(t *testing.T) {
+	// the catalog, page tree and pages are compressed into object 8
+	objs := recoverTestObjs()
+	var header, body strings.Builder
+	for i, obj := range objs[:4] {
+		fmt.Fprintf(&header, "%d %d ", i+1, body.Len())
+		body.WriteString(obj + "\n")
+	}
+	strm := streamObj(fmt.Sprintf("/Type /ObjStm /N 4 /First %d", header.Len()), []byte(header.String()+body.String()))
+	objs = append([]string{"null", "null", "null", "null"}, objs[4:]...)
+	data := buildPDF(append(objs, strm), "")
+	data = data[:bytes.Index(data, []byte("xref"))]
+	assert.Equal(t, []string{"first", "second"}, recoveredText(t, data))
+}
+
+func TestRecover_LostPageTree(t *testing.T) {
+	// the catalog and page tree were at the end of the file and are cut off
+	data := buildPDF([]string{
+		"<< /Type /Page /Parent 6 0 R /MediaBox [0 0 612 792] /Contents 3 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		"<< /Type /Page /Parent 6 0 R /MediaBox [0 0 612 792] /Contents 4 0 R >>",
+		streamObj("", []byte("BT /F1 12 Tf 72 700 Td (first) Tj ET")),
+		streamObj("", []byte("BT /F1 12 Tf 72 700 Td (second) Tj ET")),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Pages /Kids [1 0 R 2 0 R] /Count 2 >>",
+		"<< /Type /Catalog /Pages 6 0 R >>",
+	}, "")
+	data = data[:bytes.Index(data, []byte("6 0 obj"))]
+	assert.Equal(t, []string{"first", "second"}, recoveredText(t, data))
+}
+
+func TestRecover_LargeObjectNumber(t *testing.T) {
+	// the content stream of the second page has the largest object number
+	// a file may use
+	objs := recoverTestObjs()
+	objs[3] = strings.Replace(objs[3], "6 0 R", "8388607 0 R", 1)
+	data := buildPDF(objs, "")
+	data = data[:bytes.Index(data, []byte("xref"))]
+	data = append(data, "8388607 0 obj\n"+streamObj("", []byte("BT /F1 12 Tf 72 700 Td (last) Tj ET"))+"\nendobj\n"...)
+
+	r, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{Recover: true})
+	require.NoError(t, err)
+	assert.Less(t, len(r.xref), 2048, "the table is not sized by the object number")
+	assert.Equal(t, int64(8388608), r.Trailer().Key("Size").Int64())
+	assert.Equal(t, []string{"first", "last"}, recoveredText(t, data))
+}
+
+func TestRecover_NoObjects(t *testing.T) {
+	data := []byte("%PDF-1.7\nnothing to see here\n")
+	_, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{Recover: true})
+	assert.Error(t, err)
+}
+
+func TestProcessor_Extract_Recover(t *testing.T) {
+	data := buildPDF(recoverTestObjs(), "")
+	data = data[:bytes.Index(data, []byte("xref"))]
+	path := filepath.Join(t.TempDir(), "truncated.pdf")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	cfg := NewDefaultConfig()
+	text, _, err := NewProcessor(cfg).Extract(context.Background(), path)
+	require.NoError(t, err)
+	assert.Contains(t, text, "first")
+	assert.Contains(t, text, "second")
+
+	cfg = NewDefaultConfig()
+	cfg.ParsingMode = Strict
+	_, _, err = NewProcessor(cfg).Extract(context.Background(), path)
+	assert.Error(t, err, "strict mode does not rebuild the xref table")
+}
@@ -0,0 +1,173 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+// PageResult is the outcome of extracting one page.
+type PageResult struct {
+	Number    int           // page number, starting at 1
+	Text      string        // the page's text, cut short if Truncated
+	Err       error         // why the page could not be extracted, or nil
+	Duration  time.Duration // time spent extracting the page, retries included
+	Retries   int           // number of times the page was retried
+	CharCount int           // number of characters in Text
+	FontsUsed []string      // BaseFont names of the page's fonts, sorted
+	Truncated bool          // whether Text was cut by Config.MaxTotalChars
+}
+
+// DocumentResult is the outcome of extracting a document page by page.
+type DocumentResult struct {
+	Pages       []PageResult  // one per page, in page order
+	FailedPages int           // number of pages with an Err
+	CharCount   int           // number of characters of all pages
+	Retries     int           // number of retries of all pages
+	Duration    time.Duration // time spent on the whole document
+	Truncated   bool          // whether Config.MaxTotalChars cut the text
+}
+
+// Partial reports whether some pages of the document could not be
+// extracted.
+func (d *DocumentResult) Partial() bool {
+	return d.FailedPages > 0
+}
+
+// ExtractPages extracts the text of the document at path page by page.
+// Unlike Extract, it does not skip or stop at pages that fail, in either
+// parsing mode: their errors are reported in PageResult.Err. ExtractPages
+// fails only if the document cannot be opened, exceeds its Limits, or ctx
+// is done. Config.MaxTotalChars applies to the pages in order; XFA data
+// and attachments are not included.
+func (p *processor) ExtractPages(ctx context.Context, path string) (*DocumentResult, error) {
+	return p.extractPages(ctx, fileSource(path))
+}
+
+// extractPages extracts the pages of the document src.
+func (p *processor) extractPages(ctx context.Context, src source) (*DocumentResult, error) {
+	start := time.Now()
+	path := src.name
+	logger.Debug(fmt.Sprintf("Starting page extraction: path=%s", path), true)
+
+	if err := p.acquireSlot(ctx); err != nil {
+		logger.Debug(fmt.Sprintf("Failed to acquire slot: err=%v", err), true)
+		return nil, err
+	}
+	defer p.sem.Release(1)
+
+	r, release, err := src.open(ctx, p.readerOptions())
+	if err != nil {
+		logger.Debug(fmt.Sprintf("Failed to open PDF: path=%s err=%v", path, err), true)
+		return nil, err
+	}
+	defer release()
+
+	total := r.NumPage()
+	logger.Debug(fmt.Sprintf("Total pages detected: path=%s pages=%d", path, total), true)
+
+	// Page errors are reported, not skipped, so pages are extracted as in
+	// Strict mode whatever the parsing mode.
+	strict := *p
+	strict.extractor = &StrictExtractor{Mode: p.cfg.TextMode}
+	strict.fonts = true
+
+	// as in extract, the workers are stopped and done with r before it
+	// is released
+	jobs, results := make(chan int, total), make(chan pageResult, total)
+	workCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	strict.startWorkers(workCtx, *r, jobs, results, p.adjustWorkerCount(p.cfg.MaxWorkersPerPDF), &wg)
+	p.feedJobs(workCtx, total, jobs)
+	close(jobs)
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	collected := make([]pageResult, 0, total)
+	for res := range results {
+		if errors.Is(res.err, ErrLimitExceeded) {
+			logger.Debug(fmt.Sprintf("Limit exceeded — stopping extraction: page=%d err=%v", res.index, res.err), true)
+			return nil, fmt.Errorf("page %d: %w", res.index, res.err)
+		}
+		collected = append(collected, res)
+	}
+	if err := ctx.Err(); err != nil {
+		logger.Debug(fmt.Sprintf("Extraction cancelled: path=%s err=%v", path, err), true)
+		return nil, err
+	}
+	sort.Slice(collected, func(i, j int) bool { return collected[i].index < collected[j].index })
+
+	doc := &DocumentResult{Pages: make([]PageResult, len(collected))}
+	length := 0
+	for i, res := range collected {
+		page := PageResult{
+			Number:    res.index,
+			Text:      res.text,
+			Err:       res.err,
+			Duration:  res.duration,
+			Retries:   res.retries,
+			FontsUsed: res.fonts,
+		}
+		// as in emitInOrder, the limit is counted in bytes, but the text
+		// is cut at a rune boundary so that it remains valid UTF-8
+		if p.cfg.MaxTotalChars > 0 {
+			if remaining := max(p.cfg.MaxTotalChars-length, 0); len(page.Text) > remaining {
+				for remaining > 0 && !utf8.RuneStart(page.Text[remaining]) {
+					remaining--
+				}
+				page.Text = page.Text[:remaining]
+				page.Truncated = true
+				doc.Truncated = true
+				length = p.cfg.MaxTotalChars - len(page.Text)
+			}
+		}
+		length += len(page.Text)
+		page.CharCount = utf8.RuneCountInString(page.Text)
+
+		if page.Err != nil {
+			doc.FailedPages++
+		}
+		doc.CharCount += page.CharCount
+		doc.Retries += page.Retries
+		doc.Pages[i] = page
+	}
+	doc.Duration = time.Since(start)
+
+	logger.Debug(fmt.Sprintf("Page extraction completed: path=%s pages=%d failed=%d truncated=%v", path, len(doc.Pages), doc.FailedPages, doc.Truncated), true)
+	return doc, nil
+}
+
+// pageFonts returns the sorted BaseFont names of the fonts in the
+// resources of page, or nil if they cannot be read.
+func pageFonts(page Page) (fonts []string) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Debug(fmt.Sprintf("pageFonts: %v", r), true)
+			fonts = nil
+		}
+	}()
+	seen := make(map[string]bool)
+	for _, name := range page.Fonts() {
+		if base := page.Font(name).BaseFont(); base != "" && !seen[base] {
+			seen[base] = true
+			fonts = append(fonts, base)
+		}
+	}
+	sort.Strings(fonts)
+	return fonts
+}
@@ -0,0 +1,113 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+// A source is a document for the processor to extract: a file, data that
+// can be read at any offset, or a stream that must be spooled first. The
+// processor opens it only once it has acquired a slot, so that spooling
+// and parsing are bounded by Config.MaxConcurrentPDFs.
+type source struct {
+	name string // describes the document in the trace
+	open func(ctx context.Context, opts ReaderOptions) (*Reader, func(), error)
+}
+
+// fileSource returns the source for the named file.
+func fileSource(path string) source {
+	return source{
+		name: path,
+		open: func(_ context.Context, opts ReaderOptions) (*Reader, func(), error) {
+			f, r, err := openWithOptions(path, opts)
+			if err != nil {
+				return nil, nil, err
+			}
+			return r, func() { f.Close() }, nil
+		},
+	}
+}
+
+// readerAtSource returns the source for the size bytes of ra.
+func readerAtSource(name string, ra io.ReaderAt, size int64) source {
+	return source{
+		name: name,
+		open: func(_ context.Context, opts ReaderOptions) (*Reader, func(), error) {
+			logger.Debug(fmt.Sprintf("document: %s -- opened (size=%d)", name, size), true)
+			r, err := NewReaderWithOptions(ra, size, opts)
+			if err != nil {
+				return nil, nil, err
+			}
+			return r, func() {}, nil
+		},
+	}
+}
+
+// readerSource returns the source for the data read from rd. Readers that
+// can also read at an offset and seek, such as files and bytes.Readers, are
+// read in place; others are spooled by spool.
+func (p *processor) readerSource(rd io.Reader) source {
+	if ra, ok := rd.(interface {
+		io.ReaderAt
+		io.Seeker
+	}); ok {
+		if size, err := ra.Seek(0, io.SeekEnd); err == nil {
+			return readerAtSource("reader", ra, size)
+		}
+	}
+	return source{
+		name: "reader",
+		open: func(ctx context.Context, opts ReaderOptions) (*Reader, func(), error) {
+			ra, size, release, err := p.spool(ctx, rd)
+			if err != nil {
+				return nil, nil, err
+			}
+			r, err := NewReaderWithOptions(ra, size, opts)
+			if err != nil {
+				release()
+				return nil, nil, err
+			}
+			return r, release, nil
+		},
+	}
+}
+
+// spool reads rd to the end, keeping up to Config.SpoolThreshold bytes in
+// memory and writing larger documents to a temporary file in
+// Config.SpoolDir, which release removes.
+func (p *processor) spool(ctx context.Context, rd io.Reader) (ra io.ReaderAt, size int64, release func(), err error) {
+	rd = newContextReader(ctx, rd)
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, rd, p.cfg.SpoolThreshold+1)
+	if errors.Is(err, io.EOF) {
+		logger.Debug(fmt.Sprintf("document: reader -- spooled to memory (size=%d)", n), true)
+		return bytes.NewReader(buf.Bytes()), n, func() {}, nil
+	}
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("spool: %w", err)
+	}
+
+	f, err := os.CreateTemp(p.cfg.SpoolDir, "pdf-xtract-*.pdf")
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("spool: %w", err)
+	}
+	release = func() {
+		f.Close()
+		os.Remove(f.Name())
+	}
+	if size, err = io.Copy(f, io.MultiReader(&buf, rd)); err != nil {
+		release()
+		return nil, 0, nil, fmt.Errorf("spool: %w", err)
+	}
+	logger.Debug(fmt.Sprintf("document: reader -- spooled to %s (size=%d)", f.Name(), size), true)
+	return f, size, release, nil
+}
@@ -0,0 +1,400 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+// Table detection heuristics.
+const (
+	// rulingTolerance is the distance, in points, within which ruling
+	// lines are considered aligned, touching or intersecting. It also
+	// absorbs the thickness of rules drawn as thin filled rectangles.
+	rulingTolerance = 2.0
+	// minAlignedRows is the number of consecutive aligned lines needed to
+	// recognise a table without ruling lines.
+	minAlignedRows = 3
+	// maxCellWords is the largest median number of words per cell for
+	// aligned lines to be a table rather than columns of running text.
+	maxCellWords = 3
+	// rowGap is the largest baseline distance, as a fraction of the font
+	// size, between two rows of a table without ruling lines.
+	rowGap = 2.5
+)
+
+// A Table is a grid of cells found on a page.
+type Table struct {
+	Rect  Rect          `json:"rect"`
+	Ruled bool          `json:"ruled"` // found from ruling lines rather than text alignment
+	Rows  [][]TableCell `json:"rows"`  // top to bottom, each left to right
+}
+
+// A TableCell is one cell of a Table.
+type TableCell struct {
+	Rect Rect   `json:"rect"`
+	Text string `json:"text"`
+}
+
+// WriteCSV writes the table's cell text as CSV, one record per row.
+func (t Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	for _, row := range t.Rows {
+		rec := make([]string, len(row))
+		for i, c := range row {
+			rec[i] = c.Text
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the table, including cell bounding boxes, as pretty JSON.
+func (t Table) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}
+
+// ruling is a horizontal or vertical line: pos is its y (horizontal) or x
+// (vertical) coordinate and it extends from lo to hi along the other axis.
+type ruling struct {
+	pos, lo, hi float64
+}
+
+// Tables returns the tables on the page, top to bottom. Tables are found
+// from grids of horizontal and vertical ruling lines (stroked paths and
+// rectangles) and, where there are none, from runs of lines whose text
+// falls into the same columns.
+func (p Page) Tables() (tables []Table, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			tables = nil
+			logger.Error(fmt.Sprint(r))
+			err = recoveredError(r)
+		}
+	}()
+	p = p.raising()
+
+	if p.V.IsNull() || p.V.Key("Contents").Kind() == Null {
+		return nil, nil
+	}
+	var glyphs []layoutGlyph
+	var hs, vs []ruling
+	p.content(func(t Text, adv float64) {
+		glyphs = append(glyphs, layoutGlyph{t, adv})
+	}, func(a, b Point) {
+		switch {
+		case math.Abs(a.Y-b.Y) <= rulingTolerance && math.Abs(a.X-b.X) > rulingTolerance:
+			hs = append(hs, ruling{(a.Y + b.Y) / 2, math.Min(a.X, b.X), math.Max(a.X, b.X)})
+		case math.Abs(a.X-b.X) <= rulingTolerance && math.Abs(a.Y-b.Y) > rulingTolerance:
+			vs = append(vs, ruling{(a.X + b.X) / 2, math.Min(a.Y, b.Y), math.Max(a.Y, b.Y)})
+		}
+	})
+
+	tables, rest := ruledTables(mergeRulings(hs), mergeRulings(vs), glyphs)
+	ruled := len(tables)
+	tables = append(tables, alignedTables(rest)...)
+	sort.SliceStable(tables, func(i, j int) bool { return tables[i].Rect.Max.Y > tables[j].Rect.Max.Y })
+	logger.Debug(fmt.Sprintf("Tables: %d ruled, %d aligned for Page %d %d R",
+		ruled, len(tables)-ruled, p.V.ptr.id, p.V.ptr.gen), true)
+	return tables, nil
+}
+
+// mergeRulings joins rulings that lie on the same line and overlap or touch.
+func mergeRulings(rs []ruling) []ruling {
+	sort.Slice(rs, func(i, j int) bool {
+		if math.Abs(rs[i].pos-rs[j].pos) > rulingTolerance {
+			return rs[i].pos < rs[j].pos
+		}
+		return rs[i].lo < rs[j].lo
+	})
+	var out []ruling
+	for _, r := range rs {
+		merged := false
+		for i := len(out) - 1; i >= 0 && math.Abs(out[i].pos-r.pos) <= rulingTolerance; i-- {
+			if r.lo <= out[i].hi+rulingTolerance && r.hi >= out[i].lo-rulingTolerance {
+				out[i].lo = math.Min(out[i].lo, r.lo)
+				out[i].hi = math.Max(out[i].hi, r.hi)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// ruledTables builds a table for every connected grid of horizontal and
+// vertical rulings with at least two rows and two columns holding text, and
+// returns the glyphs not placed in any of them. Grids whose cell borders
+// cut through words are page decoration rather than tables.
+func ruledTables(hs, vs []ruling, glyphs []layoutGlyph) ([]Table, []layoutGlyph) {
+	// union-find over hs followed by vs
+	parent := make([]int, len(hs)+len(vs))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i, h := range hs {
+		for j, v := range vs {
+			if v.pos >= h.lo-rulingTolerance && v.pos <= h.hi+rulingTolerance &&
+				h.pos >= v.lo-rulingTolerance && h.pos <= v.hi+rulingTolerance {
+				parent[find(i)] = find(len(hs) + j)
+			}
+		}
+	}
+	groups := make(map[int][2][]float64)
+	for i, h := range hs {
+		g := groups[find(i)]
+		g[0] = append(g[0], h.pos)
+		groups[find(i)] = g
+	}
+	for j, v := range vs {
+		g := groups[find(len(hs)+j)]
+		g[1] = append(g[1], v.pos)
+		groups[find(len(hs)+j)] = g
+	}
+
+	used := make([]bool, len(glyphs))
+	var tables []Table
+	for _, g := range groups {
+		ys, xs := distinct(g[0]), distinct(g[1])
+		if len(ys) < 2 || len(xs) < 2 {
+			continue
+		}
+		// rows top to bottom
+		sort.Sort(sort.Reverse(sort.Float64Slice(ys)))
+		cells := make([][][]layoutGlyph, len(ys)-1)
+		for r := range cells {
+			cells[r] = make([][]layoutGlyph, len(xs)-1)
+		}
+		pending := make(map[int]bool)
+		for i, gl := range glyphs {
+			cx, cy := gl.center()
+			r := sort.Search(len(ys), func(k int) bool { return ys[k] < cy }) - 1
+			c := sort.Search(len(xs), func(k int) bool { return xs[k] > cx }) - 1
+			if r < 0 || r >= len(ys)-1 || c < 0 || c >= len(xs)-1 {
+				continue
+			}
+			cells[r][c] = append(cells[r][c], gl)
+			pending[i] = true
+		}
+		t := Table{Rect: Rect{Point{xs[0], ys[len(ys)-1]}, Point{xs[len(xs)-1], ys[0]}}, Ruled: true}
+		for r := range cells {
+			row := make([]TableCell, len(xs)-1)
+			for c := range row {
+				row[c] = TableCell{
+					Rect: Rect{Point{xs[c], ys[r+1]}, Point{xs[c+1], ys[r]}},
+					Text: cellText(cells[r][c]),
+				}
+			}
+			t.Rows = append(t.Rows, row)
+		}
+		if cutsWords(cells) {
+			logger.Debug(fmt.Sprintf("Tables: %dx%d grid cuts through text, ignored", len(ys)-1, len(xs)-1), true)
+			continue
+		}
+		if t = dropEmpty(t); len(t.Rows) < 2 || len(t.Rows[0]) < 2 {
+			continue
+		}
+		tables = append(tables, t)
+		for i := range pending {
+			used[i] = true
+		}
+	}
+
+	var rest []layoutGlyph
+	for i, gl := range glyphs {
+		if !used[i] {
+			rest = append(rest, gl)
+		}
+	}
+	return tables, rest
+}
+
+// cutsWords reports whether a line of text continues from one cell into
+// the next without a word gap.
+func cutsWords(cells [][][]layoutGlyph) bool {
+	for _, row := range cells {
+		var glyphs []layoutGlyph
+		col := make(map[layoutGlyph]int)
+		for c, cell := range row {
+			for _, g := range cell {
+				glyphs = append(glyphs, g)
+				col[g] = c
+			}
+		}
+		for _, l := range buildLines(glyphs) {
+			end := math.Inf(-1)
+			for i, g := range l.glyphs {
+				if i > 0 && g.S != " " && l.glyphs[i-1].S != " " &&
+					col[g] != col[l.glyphs[i-1]] && g.X-end < wordGap*g.size() {
+					return true
+				}
+				end = g.advanceFrom(end)
+			}
+		}
+	}
+	return false
+}
+
+// distinct returns the sorted positions in ps with those closer than
+// rulingTolerance merged.
+func distinct(ps []float64) []float64 {
+	sort.Float64s(ps)
+	var out []float64
+	for _, p := range ps {
+		if len(out) == 0 || p-out[len(out)-1] > rulingTolerance {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// cellText returns the text of the glyphs in a cell, with wrapped lines
+// joined by spaces.
+func cellText(glyphs []layoutGlyph) string {
+	var parts []string
+	for _, l := range buildLines(glyphs) {
+		parts = append(parts, l.text())
+	}
+	return strings.Join(parts, " ")
+}
+
+// dropEmpty removes the rows and columns of t that hold no text, such as
+// the gaps between doubled rules.
+func dropEmpty(t Table) Table {
+	var rows [][]TableCell
+	for _, row := range t.Rows {
+		for _, c := range row {
+			if c.Text != "" {
+				rows = append(rows, row)
+				break
+			}
+		}
+	}
+	if len(rows) == 0 {
+		t.Rows = nil
+		return t
+	}
+	var keep []int
+	for c := range rows[0] {
+		for _, row := range rows {
+			if row[c].Text != "" {
+				keep = append(keep, c)
+				break
+			}
+		}
+	}
+	for i, row := range rows {
+		out := make([]TableCell, len(keep))
+		for j, c := range keep {
+			out[j] = row[c]
+		}
+		rows[i] = out
+	}
+	t.Rows = rows
+	return t
+}
+
+// alignedTables finds tables without ruling lines: runs of at least
+// minAlignedRows consecutive lines that each split into two or more
+// segments at column gaps, whose segments line up in shared columns and
+// hold only a few words each.
+func alignedTables(glyphs []layoutGlyph) []Table {
+	var tables []Table
+	var run [][]*textSegment
+	flush := func() {
+		if t, ok := alignedTable(run); ok {
+			tables = append(tables, t)
+		}
+		run = nil
+	}
+	for _, l := range buildLines(glyphs) {
+		segs := splitSegments([]*layoutLine{l})
+		if len(segs) < 2 {
+			flush()
+			continue
+		}
+		if len(run) > 0 {
+			prev := run[len(run)-1][0]
+			if prev.y-l.y > rowGap*math.Max(prev.size, l.size) {
+				flush()
+			}
+		}
+		run = append(run, segs)
+	}
+	flush()
+	return tables
+}
+
+// alignedTable builds a table from a run of multi-segment lines, reporting
+// whether the run qualifies as one.
+func alignedTable(run [][]*textSegment) (Table, bool) {
+	if len(run) < minAlignedRows {
+		return Table{}, false
+	}
+	var all []*textSegment
+	var words []int
+	for _, segs := range run {
+		for _, s := range segs {
+			all = append(all, s)
+			words = append(words, len(strings.Fields(s.text())))
+		}
+	}
+	sort.Ints(words)
+	if words[len(words)/2] > maxCellWords {
+		return Table{}, false
+	}
+	cols, _ := xCut(all)
+	if len(cols) < 2 {
+		return Table{}, false
+	}
+	colOf := make(map[*textSegment]int)
+	xs := make([]Rect, len(cols))
+	for i, c := range cols {
+		xs[i] = segmentBounds(c)
+		for _, s := range c {
+			colOf[s] = i
+		}
+	}
+
+	t := Table{Rect: segmentBounds(all)}
+	for _, segs := range run {
+		row := make([]TableCell, len(cols))
+		b := segmentBounds(segs)
+		for i := range row {
+			row[i].Rect = Rect{Point{xs[i].Min.X, b.Min.Y}, Point{xs[i].Max.X, b.Max.Y}}
+		}
+		for _, s := range segs {
+			c := &row[colOf[s]]
+			if c.Text != "" {
+				c.Text += " "
+			}
+			c.Text += s.text()
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, true
+}
@@ -0,0 +1,171 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pageTables(t *testing.T, content []string) []Table {
+	t.Helper()
+	data := layoutTestPDF(layoutFont, strings.Join(content, "\n"))
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	tables, err := r.Page(1).Tables()
+	require.NoError(t, err)
+	return tables
+}
+
+func cellTexts(tbl Table) [][]string {
+	var out [][]string
+	for _, row := range tbl.Rows {
+		var r []string
+		for _, c := range row {
+			r = append(r, c.Text)
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// gridLines draws a ruled grid with the given column and row boundaries
+// using moveto/lineto and a single stroke.
+func gridLines(xs, ys []float64) string {
+	var sb strings.Builder
+	for _, y := range ys {
+		fmt.Fprintf(&sb, "%g %g m %g %g l ", xs[0], y, xs[len(xs)-1], y)
+	}
+	for _, x := range xs {
+		fmt.Fprintf(&sb, "%g %g m %g %g l ", x, ys[0], x, ys[len(ys)-1])
+	}
+	sb.WriteString("S")
+	return sb.String()
+}
+
+func TestTables_RuledLines(t *testing.T) {
+	content := []string{
+		showAt(72, 760, "Statement"),
+		gridLines([]float64{70, 150, 300, 380}, []float64{720, 700, 680, 660}),
+		showAt(75, 705, "Date"), showAt(155, 705, "Description"), showAt(385-80, 705, "Amount"),
+		showAt(75, 685, "01/02"), showAt(155, 685, "Coffee shop"), showAt(305, 685, "4.50"),
+		showAt(75, 665, "01/03"), showAt(155, 665, "Book store"), showAt(305, 665, "12.00"),
+	}
+	tables := pageTables(t, content)
+	require.Len(t, tables, 1)
+	tbl := tables[0]
+	assert.True(t, tbl.Ruled)
+	assert.Equal(t, [][]string{
+		{"Date", "Description", "Amount"},
+		{"01/02", "Coffee shop", "4.50"},
+		{"01/03", "Book store", "12.00"},
+	}, cellTexts(tbl))
+	assert.Equal(t, Rect{Point{70, 660}, Point{380, 720}}, tbl.Rect)
+	assert.Equal(t, Rect{Point{150, 680}, Point{300, 700}}, tbl.Rows[1][1].Rect)
+}
+
+func TestTables_BoxesAreNotTables(t *testing.T) {
+	content := []string{
+		// a text box
+		"100 600 200 30 re S",
+		showAt(110, 610, "This is content in the text box"),
+		// background shapes whose edges run through a heading
+		"50 400 150 100 re f 200 400 150 100 re f",
+		showAt(150, 450, "Innovative"),
+		showAt(150, 430, "Solutions"),
+	}
+	assert.Empty(t, pageTables(t, content))
+}
+
+func TestTables_RectangleCells(t *testing.T) {
+	// Each cell is its own stroked rectangle, scaled by the CTM, and the
+	// header rule is doubled.
+	var content []string
+	content = append(content, "q 2 0 0 2 0 0 cm")
+	for r := 0; r < 2; r++ {
+		for c := 0; c < 2; c++ {
+			content = append(content, fmt.Sprintf("%d %d 50 10 re S", 50+50*c, 300+10*r))
+		}
+	}
+	content = append(content, "50 319 100 0.5 re f", "Q")
+	content = append(content,
+		showAt(105, 625, "a1"), showAt(205, 625, "b1"),
+		showAt(105, 605, "a2"), showAt(205, 605, "b2"),
+	)
+	tables := pageTables(t, content)
+	require.Len(t, tables, 1)
+	assert.Equal(t, [][]string{{"a1", "b1"}, {"a2", "b2"}}, cellTexts(tables[0]))
+	assert.Equal(t, Rect{Point{100, 600}, Point{300, 640}}, tables[0].Rect)
+}
+
+func TestTables_Aligned(t *testing.T) {
+	content := []string{
+		showAt(72, 760, "Account summary for the period"),
+		showAt(72, 700, "Date"), showAt(150, 700, "Description"), showAt(350, 700, "Amount"),
+		showAt(72, 686, "01/02"), showAt(150, 686, "Coffee shop"), showAt(355, 686, "-4.50"),
+		showAt(72, 672, "01/03"), showAt(150, 672, "Salary"), showAt(340, 672, "2,500.00"),
+		showAt(72, 658, "01/05"), showAt(150, 658, "Book store"), showAt(350, 658, "-12.00"),
+		showAt(72, 600, "Thank you for banking with us."),
+	}
+	tables := pageTables(t, content)
+	require.Len(t, tables, 1)
+	assert.False(t, tables[0].Ruled)
+	assert.Equal(t, [][]string{
+		{"Date", "Description", "Amount"},
+		{"01/02", "Coffee shop", "-4.50"},
+		{"01/03", "Salary", "2,500.00"},
+		{"01/05", "Book store", "-12.00"},
+	}, cellTexts(tables[0]))
+}
+
+func TestTables_ColumnsOfTextAreNotTables(t *testing.T) {
+	var content []string
+	for j := 0; j < 4; j++ {
+		y := 700 - 12*float64(j)
+		content = append(content,
+			showAt(72, y, "running text in the left column"),
+			showAt(260, y, "and more running text on the right"))
+	}
+	assert.Empty(t, pageTables(t, content))
+}
+
+func TestTable_WriteCSV(t *testing.T) {
+	tbl := Table{Rows: [][]TableCell{
+		{{Text: "Date"}, {Text: "Description"}},
+		{{Text: "01/02"}, {Text: `Coffee, "large"`}},
+	}}
+	var buf bytes.Buffer
+	require.NoError(t, tbl.WriteCSV(&buf))
+	assert.Equal(t, "Date,Description\n01/02,\"Coffee, \"\"large\"\"\"\n", buf.String())
+}
+
+func TestTable_WriteJSON(t *testing.T) {
+	tbl := Table{
+		Rect:  Rect{Point{0, 0}, Point{10, 20}},
+		Ruled: true,
+		Rows:  [][]TableCell{{{Rect: Rect{Point{0, 0}, Point{10, 20}}, Text: "x"}}},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, tbl.WriteJSON(&buf))
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, true, got["ruled"])
+	cell := got["rows"].([]any)[0].([]any)[0].(map[string]any)
+	assert.Equal(t, "x", cell["text"])
+	assert.Equal(t, map[string]any{"x": 10.0, "y": 20.0}, cell["rect"].(map[string]any)["max"])
+}
+
+func TestMergeRulings(t *testing.T) {
+	got := mergeRulings([]ruling{
+		{100, 0, 50}, {100.5, 49, 80}, {101, 200, 300}, {150, 0, 10},
+	})
+	assert.Equal(t, []ruling{{100, 0, 80}, {101, 200, 300}, {150, 0, 10}}, got)
+}
@@ -0,0 +1,230 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+// An XFAField is one value of the XFA form data, keyed by the path of its
+// element below xfa:data.
+type XFAField struct {
+	Key   string
+	Value string
+}
+
+// XFA returns the document's XFA form (/Root/AcroForm/XFA) as one XML
+// document. The form is stored either as a single stream or as an array
+// of packet names and streams, which are concatenated in order. It returns
+// an empty string if the document has no XFA form.
+func (r *Reader) XFA() (s string, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			logger.Error(fmt.Sprint(e))
+			s, err = "", recoveredError(e)
+		}
+	}()
+	r = r.raising()
+
+	xfa := r.Trailer().Key("Root").Key("AcroForm").Key("XFA")
+	switch xfa.Kind() {
+	case Stream:
+		b, err := readStream(xfa)
+		return string(b), err
+	case Array:
+		var sb strings.Builder
+		for i := 1; i < xfa.Len(); i += 2 {
+			b, err := readStream(xfa.Index(i))
+			if err != nil {
+				return "", fmt.Errorf("XFA packet %q: %w", xfa.Index(i-1).Text(), err)
+			}
+			sb.Write(b)
+		}
+		return sb.String(), nil
+	}
+	return "", nil
+}
+
+// XFADatasets returns the xfa:datasets packet of the document's XFA form,
+// which holds the data filled into the form, or an empty string if there
+// is none.
+func (r *Reader) XFADatasets() (s string, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			logger.Error(fmt.Sprint(e))
+			s, err = "", recoveredError(e)
+		}
+	}()
+	r = r.raising()
+
+	xfa := r.Trailer().Key("Root").Key("AcroForm").Key("XFA")
+	if xfa.Kind() == Array {
+		for i := 0; i+1 < xfa.Len(); i += 2 {
+			if xfa.Index(i).Text() == "datasets" {
+				b, err := readStream(xfa.Index(i + 1))
+				return string(b), err
+			}
+		}
+	}
+	doc, err := r.XFA()
+	if err != nil || doc == "" {
+		return "", err
+	}
+	return findXFAPacket(doc, "datasets")
+}
+
+// XFAData returns the XFA form data as a flat map from element path to
+// value. See XFAFields for how the keys are formed.
+func (r *Reader) XFAData() (map[string]string, error) {
+	fields, err := r.XFAFields()
+	if err != nil || fields == nil {
+		return nil, err
+	}
+	data := make(map[string]string, len(fields))
+	for _, f := range fields {
+		data[f.Key] = f.Value
+	}
+	return data, nil
+}
+
+// XFAFields returns the values of the XFA form data in document order.
+// Each element below xfa:data without child elements is a value; its key
+// is the path of element names from the data root joined by ".", with
+// repeated elements numbered from the second occurrence on, as in
+// "form1.item", "form1.item[1]".
+func (r *Reader) XFAFields() ([]XFAField, error) {
+	datasets, err := r.XFADatasets()
+	if err != nil || datasets == "" {
+		return nil, err
+	}
+	fields, err := flattenXFAData(datasets)
+	logger.Debug(fmt.Sprintf("XFAFields: %d values", len(fields)), true)
+	return fields, err
+}
+
+// readStream returns the decoded data of stream v.
+func readStream(v Value) (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			data = nil
+			logger.Error(fmt.Sprint(r))
+			err = recoveredError(r)
+		}
+	}()
+
+	if v.Kind() != Stream {
+		return nil, fmt.Errorf("not a stream: %v", v)
+	}
+	rc := v.Reader()
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// findXFAPacket returns the XML of the first element named packet (in any
+// namespace) in doc.
+func findXFAPacket(doc, packet string) (string, error) {
+	d := xml.NewDecoder(strings.NewReader(doc))
+	d.Strict = false
+	for {
+		start := d.InputOffset()
+		tok, err := d.Token()
+		if err == io.EOF {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("XFA: %w", err)
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == packet {
+			if err := d.Skip(); err != nil {
+				return "", fmt.Errorf("XFA %s: %w", packet, err)
+			}
+			return doc[start:d.InputOffset()], nil
+		}
+	}
+}
+
+// xfaNode is an element of the XFA data.
+type xfaNode struct {
+	name     string
+	children []*xfaNode
+	text     strings.Builder
+}
+
+// flattenXFAData lists the leaf elements below the xfa:data element of a
+// datasets packet, or below the packet itself if it has no xfa:data.
+func flattenXFAData(datasets string) ([]XFAField, error) {
+	d := xml.NewDecoder(strings.NewReader(datasets))
+	d.Strict = false
+	root := &xfaNode{}
+	stack := []*xfaNode{root}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("XFA datasets: %w", err)
+		}
+		top := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xfaNode{name: t.Name.Local}
+			top.children = append(top.children, n)
+			stack = append(stack, n)
+		case xml.CharData:
+			top.text.Write(t)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if len(root.children) == 1 && root.children[0].name == "datasets" {
+		root = root.children[0]
+	}
+	var nodes []*xfaNode
+	for _, n := range root.children {
+		if n.name == "data" {
+			nodes = n.children
+			break
+		}
+	}
+	if nodes == nil {
+		for _, n := range root.children {
+			if n.name != "dataDescription" {
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	var fields []XFAField
+	flattenXFANodes(nodes, "", &fields)
+	return fields, nil
+}
+
+// flattenXFANodes appends the leaves below nodes to fields, prefixing
+// their keys with prefix.
+func flattenXFANodes(nodes []*xfaNode, prefix string, fields *[]XFAField) {
+	counts := make(map[string]int)
+	for _, n := range nodes {
+		key := n.name
+		if c := counts[n.name]; c > 0 {
+			key = fmt.Sprintf("%s[%d]", key, c)
+		}
+		counts[n.name]++
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if len(n.children) == 0 {
+			*fields = append(*fields, XFAField{Key: key, Value: strings.TrimSpace(n.text.String())})
+			continue
+		}
+		flattenXFANodes(n.children, key, fields)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const xfaTestDatasets = `<xfa:datasets xmlns:xfa="http://www.xfa.org/schema/xfa-data/1.0/">
+<xfa:data>
+<form1>
+  <applicant><name>Jane Doe</name><ssn>123-45-6789</ssn></applicant>
+  <dependent><name>Sam</name></dependent>
+  <dependent><name>Alex</name></dependent>
+  <signed/>
+</form1>
+</xfa:data>
+<dd:dataDescription xmlns:dd="http://ns.adobe.com/data-description/" dd:name="form1"><form1/></dd:dataDescription>
+</xfa:datasets>`
+
+var xfaTestFields = []XFAField{
+	{"form1.applicant.name", "Jane Doe"},
+	{"form1.applicant.ssn", "123-45-6789"},
+	{"form1.dependent.name", "Sam"},
+	{"form1.dependent[1].name", "Alex"},
+	{"form1.signed", ""},
+}
+
+// xfaTestPDF builds a one-page document whose XFA form is xfa, an array or
+// a stream reference, with packet streams following the page objects.
+func xfaTestPDF(xfa string, packets ...string) []byte {
+	objs := []string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [] /XFA " + xfa + " >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		streamObj("", []byte("BT /F1 10 Tf 72 700 Td (Please wait...) Tj ET")),
+		layoutFont,
+	}
+	for _, p := range packets {
+		objs = append(objs, streamObj("", []byte(p)))
+	}
+	return buildPDF(objs, "")
+}
+
+func TestXFA_Packets(t *testing.T) {
+	data := xfaTestPDF("[(preamble) 6 0 R (template) 7 0 R (datasets) 8 0 R (postamble) 9 0 R]",
+		`<xdp:xdp xmlns:xdp="http://ns.adobe.com/xdp/">`,
+		`<template xmlns="http://www.xfa.org/schema/xfa-template/3.3/"/>`,
+		xfaTestDatasets,
+		`</xdp:xdp>`)
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	doc, err := r.XFA()
+	require.NoError(t, err)
+	assert.Contains(t, doc, `<xdp:xdp`)
+	assert.Contains(t, doc, `<template`)
+	assert.Contains(t, doc, `</xdp:xdp>`)
+
+	ds, err := r.XFADatasets()
+	require.NoError(t, err)
+	assert.Equal(t, xfaTestDatasets, ds)
+
+	fields, err := r.XFAFields()
+	require.NoError(t, err)
+	assert.Equal(t, xfaTestFields, fields)
+
+	m, err := r.XFAData()
+	require.NoError(t, err)
+	assert.Equal(t, "Alex", m["form1.dependent[1].name"])
+	assert.Len(t, m, 5)
+}
+
+func TestXFA_SingleStream(t *testing.T) {
+	data := xfaTestPDF("6 0 R", `<?xml version="1.0"?><xdp:xdp xmlns:xdp="http://ns.adobe.com/xdp/">`+
+		`<template xmlns="http://www.xfa.org/schema/xfa-template/3.3/"><subform name="form1"/></template>`+
+		xfaTestDatasets+`</xdp:xdp>`)
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	ds, err := r.XFADatasets()
+	require.NoError(t, err)
+	assert.Equal(t, xfaTestDatasets, ds)
+
+	fields, err := r.XFAFields()
+	require.NoError(t, err)
+	assert.Equal(t, xfaTestFields, fields)
+}
+
+func TestXFA_None(t *testing.T) {
+	r := annotationsTestReader(t)
+	doc, err := r.XFA()
+	require.NoError(t, err)
+	assert.Empty(t, doc)
+	m, err := r.XFAData()
+	require.NoError(t, err)
+	assert.Nil(t, m)
+}
+
+func TestFlattenXFAData_NoDataElement(t *testing.T) {
+	fields, err := flattenXFAData(`<datasets><order><id>7</id></order></datasets>`)
+	require.NoError(t, err)
+	assert.Equal(t, []XFAField{{"order.id", "7"}}, fields)
+}
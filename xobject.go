@@ -0,0 +1,122 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sassoftware/pdf-xtract/logger"
+)
+
+// formStack tracks the Form XObjects entered through the Do operator while
+// a page's content is interpreted. The innermost resource dictionary is
+// used to resolve fonts and XObject names, and forms already being
+// interpreted are not entered again so that cyclic references terminate.
+type formStack struct {
+	res      []Value
+	active   map[objptr]bool
+	ops      int64 // operators interpreted so far
+	maxOps   int64 // Limits.MaxOperators, or 0
+	maxDepth int   // Limits.MaxDepth in effect
+	ctx      context.Context
+}
+
+func newFormStack(pageResources Value) *formStack {
+	return &formStack{res: []Value{pageResources}, active: make(map[objptr]bool), maxDepth: defaultMaxDepth, ctx: context.Background()}
+}
+
+// formStack returns the formStack for interpreting the content of p, which
+// counts the page's operators and nested forms against the Reader's Limits
+// and stops when the page's context is done.
+func (p Page) formStack() *formStack {
+	fs := newFormStack(p.Resources())
+	fs.ctx = p.context()
+	if p.V.r != nil {
+		fs.maxOps = p.V.r.limits.MaxOperators
+		fs.maxDepth = p.V.r.limits.maxDepth()
+	}
+	return fs
+}
+
+// interpret interprets strm, the content stream of the page or of a form
+// it paints, with do. It aborts with a LimitError once the page has more
+// than Limits.MaxOperators operators, and with the context's error once
+// the context is done.
+func (fs *formStack) interpret(strm Value, do func(stk *Stack, op string)) {
+	err := InterpretContext(fs.ctx, strm, func(stk *Stack, op string) {
+		fs.ops++
+		if fs.maxOps > 0 && fs.ops > fs.maxOps {
+			panic(limitError("MaxOperators", fs.maxOps))
+		}
+		do(stk, op)
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// depth returns the number of forms currently being interpreted.
+func (fs *formStack) depth() int {
+	return len(fs.res) - 1
+}
+
+// resources returns the resource dictionary in effect.
+func (fs *formStack) resources() Value {
+	return fs.res[len(fs.res)-1]
+}
+
+// font returns the font called name in the resources in effect.
+func (fs *formStack) font(name string) Font {
+	return Font{fs.resources().Key("Font").Key(name), nil}
+}
+
+// doForm calls fn with the Form XObject called name while the form's own
+// resources are in effect. A form without /Resources inherits the
+// resources of its caller. Image XObjects, unknown names and cycles are
+// skipped; doForm reports whether fn was called. Forms nested deeper than
+// Limits.MaxDepth abort with a LimitError.
+func (fs *formStack) doForm(name string, fn func(form Value)) bool {
+	form := fs.resources().Key("XObject").Key(name)
+	if form.Kind() != Stream || form.Key("Subtype").Name() != "Form" {
+		return false
+	}
+	if fs.active[form.ptr] {
+		logger.Debug(fmt.Sprintf("operator: Do /%s skipped (cycle at obj %d %d)", name, form.ptr.id, form.ptr.gen), true)
+		return false
+	}
+	if fs.depth() >= fs.maxDepth {
+		panic(limitError("MaxDepth", int64(fs.maxDepth)))
+	}
+	logger.Debug(fmt.Sprintf("operator: Do /%s (form obj %d %d, depth %d)", name, form.ptr.id, form.ptr.gen, fs.depth()+1), true)
+
+	res := form.Key("Resources")
+	if res.Kind() != Dict {
+		res = fs.resources()
+	}
+	fs.res = append(fs.res, res)
+	fs.active[form.ptr] = true
+	defer func() {
+		fs.res = fs.res[:len(fs.res)-1]
+		delete(fs.active, form.ptr)
+	}()
+
+	fn(form)
+	return true
+}
+
+// formMatrix returns the /Matrix of a Form XObject, which maps form space
+// to the user space of the caller. It defaults to the identity matrix.
+func formMatrix(form Value) matrix {
+	mv := form.Key("Matrix")
+	if mv.Kind() != Array || mv.Len() != 6 {
+		return ident
+	}
+	var m matrix
+	for i := 0; i < 6; i++ {
+		m[i/2][i%2] = mv.Index(i).Float64()
+	}
+	m[2][2] = 1
+	return m
+}
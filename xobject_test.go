@@ -0,0 +1,144 @@
+// Copyright © 2026, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package xtract
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// formTestPDF returns a one-page document whose body text is on the page
+// and whose header is drawn by a Form XObject with its own font resources
+// and a translating /Matrix. The header form also draws a nested form, which
+// in turn invokes the header again to create a cycle, and an image XObject.
+func formTestPDF() []byte {
+	return buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R " +
+			"/Resources << /Font << /F1 5 0 R >> /XObject << /Hdr 6 0 R /Img 9 0 R >> >> >>",
+		streamObj("", []byte("BT /F1 12 Tf 1 0 0 1 72 600 Tm (Body) Tj ET q /Hdr Do Q /Img Do")),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		streamObj("/Type /XObject /Subtype /Form /BBox [0 0 612 100] /Matrix [1 0 0 1 0 700] "+
+			"/Resources << /Font << /H1 7 0 R >> /XObject << /Inner 8 0 R >> >>",
+			[]byte("BT /H1 10 Tf 1 0 0 1 72 20 Tm (Header) Tj ET /Inner Do")),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>",
+		streamObj("/Type /XObject /Subtype /Form /BBox [0 0 10 10] "+
+			"/Resources << /Font << /H1 7 0 R >> /XObject << /Back 6 0 R >> >>",
+			[]byte("BT /H1 8 Tf 1 0 0 1 72 5 Tm (Inner) Tj ET /Back Do")),
+		streamObj("/Type /XObject /Subtype /Image /Width 1 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 8", []byte{0}),
+	}, "")
+}
+
+func TestGetPlainText_FormXObject(t *testing.T) {
+	data := formTestPDF()
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	text, err := r.Page(1).GetPlainText(nil)
+	require.NoError(t, err)
+	assert.Contains(t, text, "Body")
+	assert.Contains(t, text, "Header")
+	assert.Equal(t, 1, strings.Count(text, "Inner"), "cyclic forms must be entered once")
+	assert.Less(t, strings.Index(text, "Body"), strings.Index(text, "Header"))
+}
+
+func TestContent_FormXObject(t *testing.T) {
+	data := formTestPDF()
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	var header []Text
+	for _, txt := range r.Page(1).Content().Text {
+		if txt.Font == "Courier" {
+			header = append(header, txt)
+		}
+	}
+	require.Len(t, header, len("Header")+len("Inner"))
+	assert.Equal(t, "H", header[0].S)
+	assert.InDelta(t, 72, header[0].X, 0.001)
+	assert.InDelta(t, 720, header[0].Y, 0.001, "form /Matrix must be applied")
+	assert.Equal(t, 10.0, header[0].FontSize)
+
+	inner := header[len("Header")]
+	assert.Equal(t, "I", inner.S)
+	assert.InDelta(t, 705, inner.Y, 0.001, "nested form inherits the caller's matrix")
+}
+
+func TestContent_FormFontRestored(t *testing.T) {
+	// the form shows a 2-byte CID font inside q/Q, then the page's font
+	// again; the page goes on in its simple font after the form
+	data := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R " +
+			"/Resources << /Font << /F1 5 0 R >> /XObject << /Fm 6 0 R >> >> >>",
+		streamObj("", []byte("BT /F1 10 Tf 1 0 0 1 72 600 Tm (AB) Tj ET /Fm Do BT 1 0 0 1 72 500 Tm (AB) Tj ET")),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		streamObj("/Type /XObject /Subtype /Form /BBox [0 0 612 792] /Resources << /Font << /C1 7 0 R >> >>",
+			[]byte("q BT /C1 10 Tf 1 0 0 1 72 700 Tm <0022> Tj ET Q BT 1 0 0 1 72 650 Tm (AB) Tj ET BT /C1 10 Tf ET")),
+		"<< /Type /Font /Subtype /Type0 /BaseFont /CJK /Encoding /Identity-H /DescendantFonts [<< /Subtype /CIDFontType0" +
+			" /CIDSystemInfo << /Registry (Adobe) /Ordering (Japan1) /Supplement 6 >> /DW 1000 >>] >>",
+	}, "")
+	r := newTestReader(t, data)
+
+	lines := map[float64][]Text{}
+	for _, txt := range r.Page(1).Content().Text {
+		lines[txt.Y] = append(lines[txt.Y], txt)
+	}
+	require.Len(t, lines[700], 1)
+	for _, y := range []float64{650, 500} {
+		line := lines[y]
+		require.Len(t, line, 2, "y=%v", y)
+		assert.Equal(t, "A", line[0].S)
+		assert.Equal(t, "B", line[1].S)
+		assert.Equal(t, "Helvetica", line[1].Font)
+		assert.InDelta(t, 6.67, line[1].X-line[0].X, 0.001, "y=%v: measured with the Helvetica widths", y)
+	}
+}
+
+func TestWalkTextBlocks_FormXObject(t *testing.T) {
+	data := formTestPDF()
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	pos := map[string][2]float64{}
+	r.Page(1).walkTextBlocks(func(enc TextEncoding, x, y float64, s string) {
+		if s != "" {
+			pos[enc.Decode(s)] = [2]float64{x, y}
+		}
+	})
+	assert.Equal(t, [2]float64{72, 600}, pos["Body"])
+	assert.Equal(t, [2]float64{72, 720}, pos["Header"])
+	assert.Equal(t, [2]float64{72, 705}, pos["Inner"])
+}
+
+func TestFormStack_DepthLimit(t *testing.T) {
+	form := Value{nil, objptr{1, 0}, stream{hdr: dict{"Subtype": name("Form")}}}
+	fs := newFormStack(Value{data: dict{"XObject": dict{"Fm": form.data}}})
+	fs.maxDepth = 3
+	for i := 0; i < fs.maxDepth; i++ {
+		fs.res = append(fs.res, fs.resources())
+	}
+	assert.PanicsWithError(t, limitError("MaxDepth", 3).Error(), func() {
+		fs.doForm("Fm", func(Value) { t.Fatal("form entered past the depth limit") })
+	})
+
+	fs = newFormStack(Value{data: dict{"XObject": dict{"Fm": form.data}}})
+	assert.True(t, fs.doForm("Fm", func(Value) { assert.Equal(t, 1, fs.depth()) }))
+	assert.Equal(t, 0, fs.depth())
+	assert.False(t, fs.doForm("Missing", func(Value) { t.Fatal("unknown XObject entered") }))
+}
+
+func TestFormMatrix(t *testing.T) {
+	assert.Equal(t, ident, formMatrix(Value{}))
+	m := formMatrix(Value{data: dict{"Matrix": array{int64(2), int64(0), int64(0), int64(2), 10.5, int64(20)}}})
+	x, y := m.apply(1, 1)
+	assert.Equal(t, 12.5, x)
+	assert.Equal(t, 22.0, y)
+}